@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config key of variables available in every test case
+const configVarsKey = "vars"
+
+// config files looked up in the current directory in order if '--config' is not provided
+var defaultConfigFiles = []string{"bozr.json", "bozr.yaml", "bozr.yml"}
+
+// configVars are variables from config file, test case args take precedence over them
+var configVars map[string]interface{}
+
+// configApplied are names of options set from config file
+var configApplied = make(map[string]bool)
+
+// flagAliases maps short flag names to long ones, so both are treated as the same option
+var flagAliases = map[string]string{
+	"H": "base-url",
+	"d": "debug",
+	"i": "info",
+	"h": "help",
+	"v": "version",
+}
+
+// options that make no sense in config file
+var nonConfigurable = map[string]bool{
+	"config":       true,
+	"help":         true,
+	"version":      true,
+	"print-config": true,
+}
+
+func canonicalFlagName(name string) string {
+	if long, ok := flagAliases[name]; ok {
+		return long
+	}
+
+	return name
+}
+
+// applyConfig sets options from JSON or YAML config file. Keys are names of command line options, e.g. 'base-url', 'w', 'reporter'.
+// Options provided in command line take precedence over config values. Repeatable options are set with arrays.
+// Default config file is used only if it exists.
+func applyConfig(fs *flag.FlagSet, path string) error {
+	if path == "" {
+		path = defaultConfigFile()
+		if path == "" {
+			return nil
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Can't read config file: %s", err)
+	}
+
+	options, err := parseConfig(path, data)
+	if err != nil {
+		return fmt.Errorf("Invalid config file %s: %s", path, err)
+	}
+
+	provided := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		provided[canonicalFlagName(f.Name)] = true
+	})
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key == configVarsKey {
+			if err := json.Unmarshal(options[key], &configVars); err != nil {
+				return fmt.Errorf("Invalid config option '%s': %s", key, err)
+			}
+			continue
+		}
+
+		name := canonicalFlagName(key)
+		if fs.Lookup(key) == nil || nonConfigurable[name] {
+			return fmt.Errorf("Unknown config option '%s'", key)
+		}
+
+		if provided[name] {
+			continue
+		}
+
+		values, err := configValues(options[key])
+		if err != nil {
+			return fmt.Errorf("Invalid config option '%s': %s", key, err)
+		}
+
+		for _, value := range values {
+			if err := fs.Set(key, value); err != nil {
+				return fmt.Errorf("Invalid config option '%s': %s", key, err)
+			}
+		}
+		configApplied[name] = true
+	}
+
+	return nil
+}
+
+// defaultConfigFile returns the first existing default config file, empty if there is none
+func defaultConfigFile() string {
+	for _, name := range defaultConfigFiles {
+		if _, err := os.Stat(name); err == nil {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// parseConfig parses options of config file, files with '.yaml' or '.yml' extension are parsed as YAML
+func parseConfig(path string, data []byte) (map[string]json.RawMessage, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		var doc map[string]interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var options map[string]json.RawMessage
+	if err := json.Unmarshal(data, &options); err != nil {
+		return nil, err
+	}
+
+	return options, nil
+}
+
+// configValues converts JSON value of option into command line values, array is treated as repeated option
+func configValues(raw json.RawMessage) ([]string, error) {
+	var items []json.RawMessage
+	if strings.HasPrefix(strings.TrimSpace(string(raw)), "[") {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+	} else {
+		items = []json.RawMessage{raw}
+	}
+
+	values := make([]string, 0, len(items))
+	for _, item := range items {
+		var value interface{}
+		if err := json.Unmarshal(item, &value); err != nil {
+			return nil, err
+		}
+
+		switch typed := value.(type) {
+		case string:
+			values = append(values, typed)
+		case bool, float64:
+			values = append(values, string(item))
+		default:
+			return nil, fmt.Errorf("Expected string, number, boolean or array of them, got %s", string(item))
+		}
+	}
+
+	return values, nil
+}
+
+// printConfig writes effective values of options with their source (command line, config file or default),
+// selected reporters and names of config variables. Values of variables and request headers are masked.
+func printConfig(w io.Writer, fs *flag.FlagSet) {
+	provided := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		provided[canonicalFlagName(f.Name)] = true
+	})
+
+	fmt.Fprintln(w, "Options:")
+	tw := tabwriter.NewWriter(w, 4, 2, 2, ' ', 0)
+	fs.VisitAll(func(f *flag.Flag) {
+		if _, alias := flagAliases[f.Name]; alias || (nonConfigurable[f.Name] && f.Name != "config") {
+			return
+		}
+
+		source := "default"
+		if configApplied[f.Name] {
+			source = "config"
+		}
+		if provided[f.Name] && !configApplied[f.Name] {
+			source = "flag"
+		}
+
+		value := f.Value.String()
+		if f.Name == "request-header" {
+			value = maskHeaderValues(requestHeaderFlags)
+		}
+
+		fmt.Fprintf(tw, "  %s\t%s\t(%s)\n", f.Name, value, source)
+	})
+	tw.Flush()
+
+	fmt.Fprintln(w, "Reporters:")
+	for _, spec := range reporterSpecs() {
+		fmt.Fprintln(w, "  "+spec)
+	}
+	if junitFlag {
+		path, _ := filepath.Abs(junitOutputFlag)
+		fmt.Fprintln(w, "  junit:"+path)
+	}
+	if allureFlag {
+		path, _ := filepath.Abs(allureOutFlag)
+		fmt.Fprintln(w, "  allure:"+path)
+	}
+
+	names := make([]string, 0, len(configVars))
+	for name := range configVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Variables:")
+	for _, name := range names {
+		fmt.Fprintln(w, "  "+name+"=***")
+	}
+}
+
+// maskHeaderValues hides values of headers given as 'Name: value', e.g. tokens
+func maskHeaderValues(headers []string) string {
+	masked := make([]string, 0, len(headers))
+	for _, header := range headers {
+		masked = append(masked, strings.TrimSpace(strings.SplitN(header, ":", 2)[0])+": ***")
+	}
+
+	return strings.Join(masked, ",")
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kajf/bozr/runner"
+)
+
+func newConfigTestFlags() (*flag.FlagSet, *string, *int, *bool, *reporterList) {
+	var host string
+	var workers int
+	var junit bool
+	var reporters reporterList
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&host, "H", "", "")
+	fs.StringVar(&host, "base-url", "", "")
+	fs.IntVar(&workers, "w", 1, "")
+	fs.BoolVar(&junit, "junit", false, "")
+	fs.Var(&reporters, "reporter", "")
+	fs.String("config", "", "")
+
+	return fs, &host, &workers, &junit, &reporters
+}
+
+func writeConfig(t *testing.T, content string) (string, func()) {
+	return writeConfigFile(t, "bozr.json", content)
+}
+
+func writeConfigFile(t *testing.T, name string, content string) (string, func()) {
+	dir, _ := ioutil.TempDir("", "bozr-config")
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestApplyConfig(t *testing.T) {
+	// given
+	defer func(vars map[string]interface{}) { configVars = vars }(configVars)
+
+	path, cleanup := writeConfig(t, `{
+		"base-url": "http://config.example.com",
+		"w": 4,
+		"junit": true,
+		"reporter": ["console", "junit:./out"],
+		"vars": {"tenant": "acme"}
+	}`)
+	defer cleanup()
+
+	fs, host, workers, junit, reporters := newConfigTestFlags()
+	fs.Parse([]string{"-H", "http://cli.example.com", "-w", "2"})
+
+	// when
+	err := applyConfig(fs, path)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *host != "http://cli.example.com" || *workers != 2 {
+		t.Errorf("Expected command line values to override config, got host %s, workers %d", *host, *workers)
+	}
+
+	if !*junit || strings.Join(*reporters, ",") != "console,junit:./out" {
+		t.Errorf("Expected config values to be applied, got junit %v, reporters %v", *junit, *reporters)
+	}
+
+	if configVars["tenant"] != "acme" {
+		t.Errorf("Expected config vars to be loaded, got %v", configVars)
+	}
+}
+
+func TestApplyYAMLConfig(t *testing.T) {
+	// given
+	defer func(vars map[string]interface{}) { configVars = vars }(configVars)
+
+	path, cleanup := writeConfigFile(t, "bozr.yml", `
+base-url: http://config.example.com
+w: 4
+junit: true
+reporter: [console, "junit:./out"]
+vars:
+  tenant: acme
+  limits: {orders: 10}
+`)
+	defer cleanup()
+
+	fs, host, workers, junit, reporters := newConfigTestFlags()
+
+	// when
+	err := applyConfig(fs, path)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *host != "http://config.example.com" || *workers != 4 || !*junit || strings.Join(*reporters, ",") != "console,junit:./out" {
+		t.Errorf("Expected YAML config values to be applied, got host %s, workers %d, junit %v, reporters %v", *host, *workers, *junit, *reporters)
+	}
+
+	if limits, ok := configVars["limits"].(map[string]interface{}); configVars["tenant"] != "acme" || !ok || limits["orders"] != 10.0 {
+		t.Errorf("Expected YAML config vars to be loaded, got %v", configVars)
+	}
+}
+
+func TestApplyDefaultYAMLConfig(t *testing.T) {
+	// given
+	defer func(vars map[string]interface{}) { configVars = vars }(configVars)
+
+	path, cleanup := writeConfigFile(t, "bozr.yaml", "w: 3\n")
+	defer cleanup()
+
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(filepath.Dir(path))
+
+	fs, _, workers, _, _ := newConfigTestFlags()
+
+	// when
+	err := applyConfig(fs, "")
+
+	// then
+	if err != nil || *workers != 3 {
+		t.Errorf("Expected default bozr.yaml to be applied, got workers %d, error %v", *workers, err)
+	}
+}
+
+func TestApplyInvalidYAMLConfig(t *testing.T) {
+	path, cleanup := writeConfigFile(t, "bozr.yaml", "w: [4")
+	defer cleanup()
+
+	fs, _, _, _, _ := newConfigTestFlags()
+
+	err := applyConfig(fs, path)
+	if err == nil || !strings.HasPrefix(err.Error(), "Invalid config file "+path+": ") {
+		t.Error("Expected invalid config error, got:", err)
+	}
+}
+
+func TestApplyConfigUnknownOption(t *testing.T) {
+	path, cleanup := writeConfig(t, `{"timeout": "5s"}`)
+	defer cleanup()
+
+	fs, _, _, _, _ := newConfigTestFlags()
+
+	err := applyConfig(fs, path)
+	if err == nil || err.Error() != "Unknown config option 'timeout'" {
+		t.Error("Expected unknown option error, got:", err)
+	}
+}
+
+func TestConfigVarsOverriddenByArgs(t *testing.T) {
+	vars := runner.NewVars("")
+
+	if err := vars.AddDefaults(map[string]interface{}{"tenant": "acme", "region": "eu"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := vars.AddAll(map[string]interface{}{"tenant": "globex"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := vars.ApplyTo("{tenant}"); res != "globex" {
+		t.Errorf("Expected args to override config vars, got %s", res)
+	}
+
+	if unused := vars.Unused(); len(unused) != 0 {
+		t.Errorf("Config vars are not expected to be reported as unused, got %v", unused)
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	// given
+	defer func(vars map[string]interface{}, applied map[string]bool) {
+		configVars, configApplied = vars, applied
+	}(configVars, configApplied)
+	configApplied = make(map[string]bool)
+
+	defer func(host string, timeout time.Duration, env string, headers requestHeaderList) {
+		hostFlag, stopTimeoutFlag, envFlag, requestHeaderFlags = host, timeout, env, headers
+	}(hostFlag, stopTimeoutFlag, envFlag, requestHeaderFlags)
+	defer func() { reporterFlags, outputJSONFlag = nil, "" }()
+
+	path, cleanup := writeConfig(t, `{
+		"base-url": "http://config.example.com",
+		"stop-timeout": "30s",
+		"reporter": ["console"],
+		"request-header": ["Authorization: Bearer secret-token"],
+		"vars": {"token": "secret-token", "tenant": "acme"}
+	}`)
+	defer cleanup()
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.StringVar(&hostFlag, "H", "", "")
+	fs.StringVar(&hostFlag, "base-url", "", "")
+	fs.DurationVar(&stopTimeoutFlag, "stop-timeout", runner.DefaultStopTimeout, "")
+	fs.StringVar(&envFlag, "env", "", "")
+	fs.Var(&reporterFlags, "reporter", "")
+	fs.Var(&requestHeaderFlags, "request-header", "")
+	fs.StringVar(&outputJSONFlag, "output-json", "", "")
+	fs.String("config", "", "")
+	fs.Parse([]string{"-H", "http://cli.example.com", "--output-json", "./out/report.json"})
+
+	if err := applyConfig(fs, path); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	var out bytes.Buffer
+	printConfig(&out, fs)
+
+	// then
+	dump := out.String()
+	expected := []*regexp.Regexp{
+		regexp.MustCompile(`base-url\s+http://cli\.example\.com\s+\(flag\)`),
+		regexp.MustCompile(`stop-timeout\s+30s\s+\(config\)`),
+		regexp.MustCompile(`env\s+\(default\)`),
+		regexp.MustCompile(`request-header\s+Authorization: \*\*\*\s+\(config\)`),
+		regexp.MustCompile(`Reporters:\n  console\n  json:./out/report.json\n`),
+		regexp.MustCompile(`Variables:\n  tenant=\*\*\*\n  token=\*\*\*\n`),
+	}
+	for _, re := range expected {
+		if !re.MatchString(dump) {
+			t.Errorf("Expected config dump to match %s, got:\n%s", re, dump)
+		}
+	}
+
+	if strings.Contains(dump, "secret-token") || strings.Contains(dump, "config.example.com") {
+		t.Errorf("Expected overridden and secret values to be absent, got:\n%s", dump)
+	}
+}
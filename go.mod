@@ -3,12 +3,21 @@ module github.com/kajf/bozr
 go 1.15
 
 require (
+	github.com/Azure/go-ntlmssp v0.0.0-20221128193559-754e69321358
 	github.com/clbanning/mxj v1.8.4
 	github.com/fatih/color v1.9.0
-	github.com/google/go-cmp v0.2.0
+	github.com/fxamacker/cbor/v2 v2.4.0
+	github.com/google/cel-go v0.7.3
+	github.com/google/go-cmp v0.5.5
 	github.com/mattn/go-colorable v0.1.6 // indirect
+	github.com/mattn/go-sqlite3 v1.14.6
 	github.com/pkg/errors v0.9.1
+	github.com/vmihailenco/msgpack/v5 v5.3.6
 	github.com/xeipuuv/gojsonschema v1.2.0
+	golang.org/x/crypto v0.0.0-20200622213623-75b288015ac9
 	golang.org/x/sys v0.0.0-20200515095857-1151b9dac4a9 // indirect
+	golang.org/x/text v0.3.7
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 	moul.io/http2curl v1.0.0
 )
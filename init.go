@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kajf/bozr/runner"
+)
+
+const initCommand = "init"
+
+// scaffoldFiles are written by 'bozr init'. JSON has no comments, so 'description' fields explain the samples, config is commented YAML.
+var scaffoldFiles = map[string]string{
+	"example.suite.json": `[
+  {
+    "name": "Health check",
+    "description": "Sample test case. Run with: bozr . Options are read from bozr.yaml, switch environment by changing base-url there or with --base-url.",
+    "calls": [
+      {
+        "description": "Relative url is prefixed with base-url. Vars of bozr.yaml and args are referenced as {name}, OS environment variables as {env:NAME}.",
+        "on": {
+          "method": "GET",
+          "url": "/health",
+          "headers": {
+            "Accept": "application/json",
+            "X-Api-Key": "{env:API_KEY}"
+          }
+        },
+        "expect": {
+          "use": ["json"],
+          "statusCode": 200,
+          "bodyPath": {
+            "status": "{expectedStatus}"
+          }
+        }
+      }
+    ]
+  }
+]
+`,
+	"bozr.yaml": `# Default options of bozr run in this directory.
+# Keys are names of command line options, options provided in command line take precedence.
+
+# Relative urls of calls are prefixed with it
+base-url: http://localhost:8080
+
+# Environment name to apply expectations declared for it in 'expectEnv' of calls
+# env: staging
+
+# Variables available in every test case as {name}, test case args with the same name override them
+vars:
+  expectedStatus: ok
+`,
+	runner.MatchersFile: `{
+  "json": {
+    "contentType": "application/json"
+  }
+}
+`,
+}
+
+// runInit writes starter test files into directory provided as argument (current directory by default)
+func runInit(args []string) error {
+	fs := flag.NewFlagSet(initCommand, flag.ExitOnError)
+	force := fs.Bool("force", false, "Overwrite existing files")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: bozr init [--force] [DIR]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	dir := fs.Arg(0)
+	if dir == "" {
+		dir = "."
+	}
+
+	written, err := scaffold(dir, *force)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range written {
+		fmt.Println("Created", path)
+	}
+
+	return nil
+}
+
+// scaffold writes starter files into dir. Existing files are not overwritten unless force is set.
+func scaffold(dir string, force bool) ([]string, error) {
+	names := make([]string, 0, len(scaffoldFiles))
+	for name := range scaffoldFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if !force {
+		for _, name := range names {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("File %s already exists. Use --force to overwrite", path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+
+	written := make([]string, 0, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(scaffoldFiles[name]), 0644); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kajf/bozr/runner"
+)
+
+func TestScaffoldRunsAgainstServer(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "bozr-init")
+	defer os.RemoveAll(dir)
+
+	// when
+	written, err := scaffold(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// then
+	if len(written) != len(scaffoldFiles) {
+		t.Errorf("Expected %d files, got %v", len(scaffoldFiles), written)
+	}
+
+	defer func(vars map[string]interface{}, applied map[string]bool) {
+		configVars, configApplied = vars, applied
+	}(configVars, configApplied)
+	configVars, configApplied = nil, make(map[string]bool)
+
+	fs, host, _, _, _ := newConfigTestFlags()
+	if err := applyConfig(fs, filepath.Join(dir, "bozr.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	if *host != "http://localhost:8080" || configVars["expectedStatus"] != "ok" {
+		t.Errorf("Expected generated config to set base-url and vars, got host %s, vars %v", *host, configVars)
+	}
+
+	results, err := runner.Run(context.Background(), runner.Options{Path: dir, BaseURL: server.URL, Vars: configVars})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("Generated suite is not executed")
+	}
+
+	for _, result := range results {
+		if result.HasError() {
+			t.Errorf("Case '%s' failed: %s", result.Case.Name, result.Error())
+		}
+	}
+}
+
+func TestScaffoldDoesNotOverwrite(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "bozr-init")
+	defer os.RemoveAll(dir)
+
+	existing := filepath.Join(dir, runner.MatchersFile)
+	ioutil.WriteFile(existing, []byte(`{}`), 0644)
+
+	_, err := scaffold(dir, false)
+	if err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Error("Expected error for existing file, got:", err)
+	}
+
+	if data, _ := ioutil.ReadFile(existing); string(data) != `{}` {
+		t.Error("Existing file is overwritten:", string(data))
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "example.suite.json")); !os.IsNotExist(err) {
+		t.Error("No files expected to be written when one of them exists")
+	}
+
+	if _, err := scaffold(dir, true); err != nil {
+		t.Error("Expected files to be overwritten with force, got:", err)
+	}
+}
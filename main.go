@@ -1,41 +1,86 @@
 package main
 
 import (
-	"bytes"
-	"errors"
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
-	"net/http"
 	"net/url"
 	"os"
-	"path"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
-	"moul.io/http2curl"
-)
 
-const (
-	version = "0.9.2"
+	"github.com/fatih/color"
+	"github.com/kajf/bozr/runner"
 )
 
+const version = "0.9.2"
+
 func init() {
 	flag.Usage = func() {
 		h := "Usage:\n"
-		h += "  bozr [OPTIONS] (DIR|FILE)\n\n"
+		h += "  bozr [OPTIONS] (DIR|FILE)\n"
+		h += "  bozr init [--force] [DIR]	Create starter test files\n\n"
 
 		h += "Options:\n"
+		h += "      --config		Path to JSON or YAML config file with default values of options (default ./bozr.json, ./bozr.yaml or ./bozr.yml if exists)\n"
+		h += "      --print-config	Print effective options (command line over config file), reporters and variable names, then quit\n"
+		h += "      --compact	Print one line per test case, e.g. 'PASS users/create 120ms', details only for failed ones\n"
+		h += "      --color	Color console output: auto (only if it is a terminal), always or never (default auto)\n"
 		h += "  -d, --debug		Enable debug mode\n"
-		h += "  -H, --host		Base URI prefix for test calls\n"
+		h += "  -H, --base-url	Base URL prefix for test calls\n"
 		h += "  -w, --worker		Execute in parallel with specified number of workers\n"
 		h += "      --throttle	Execute no more than specified number of requests per second (in suite)\n"
+		h += "      --env		Apply expectations declared for the environment in 'expectEnv' of calls\n"
+		h += "      --changed-files	Run only suites affected by files listed in the file ('-' for stdin), e.g. git diff --name-only\n"
+		h += "      --repeat-run	Run test suites N times and report flaky test cases\n"
+		h += "      --latency-stability	Fail repeated run if variation of request durations across runs exceeds the limit, e.g. cv=0.3 or ratio=2\n"
+		h += "      --bail-on-error	Abort the run on the first transport error, e.g. refused connection. Failed expectations do not abort it\n"
+		h += "      --strict-skips	Fail the run if any test case is skipped, skipped test cases are listed\n"
+		h += "      --coverage-spec	OpenAPI document (JSON) which operations called during the run are reported after it\n"
+		h += "      --require-coverage	Fail the run if any operation of --coverage-spec is not called, untested operations are listed\n"
+		h += "      --export-vars	Comma separated variables (args and remembered values) written after the run, e.g. createdId,token\n"
+		h += "      --export-vars-output	JSON file of exported variables or 'github' for GitHub Actions step outputs (default ./vars.json)\n"
+		h += "      --heartbeat	Print progress line to stderr at the interval during the run, e.g. 30s for CI watchdogs (default 0, disabled)\n"
+		h += "      --stop-timeout	Grace period for in-flight requests once the run is stopped (default 10s)\n"
+		h += "      --max-failures	Abort the run once specified number of test cases failed (default 0, no limit)\n"
+		h += "      --allow-duplicate-names	Do not fail on duplicate test case names within a suite and duplicate suite names\n"
+		h += "      --perf-threshold	Fail the run if percentile of request durations exceeds the limit, e.g. p99=800ms. Could be repeated\n"
+		h += "      --before-all	Suite file executed once before all test suites, e.g. to seed test data. Its failure aborts the run\n"
+		h += "      --after-all	Suite file executed once after all test suites, even if they failed\n"
 		h += "  -h, --help		Print usage\n"
 		h += "  -i, --info		Enable info mode. Print request and response details\n"
 		h += "      --info-curl Enable info mode. Print request and response details. Request is printed as curl command\n"
+		h += "      --show-offsets	Prefix request lines in console output with offset of request start from test case start, e.g. +120ms\n"
+		h += "      --tree-summary	Print results grouped by package into a tree with per-package counts in the console summary\n"
+		h += "      --reporter	Reporter to use as name[:output], could be repeated. Output is stdout, stderr or file, e.g. json:stdout (default console)\n"
+		h += "      --output-json	Write JSON report into the file in addition to console output\n"
+		h += "      --output-junit	Write junit xml reports into the directory in addition to console output\n"
+		h += "      --max-body-log	Max size in bytes of logged request and response body (default 8192, -1 for no limit)\n"
+		h += "      --max-response-size	Max size in bytes of response body read into memory (default 10485760, -1 for no limit)\n"
 		h += "      --junit		Enable junit xml reporter\n"
 		h += "      --junit-output	Destination for junit report files\n"
+		h += "      --junit-pretty	Indent junit xml report\n"
+		h += "      --junit-suite-name-template	Template of suite name in junit report, e.g. '{{.Env}}-{{.Name}}'\n"
+		h += "      --junit-package-template	Template of suite package in junit report, e.g. 'staging.{{.Package}}'\n"
+		h += "      --fixed-timestamp	Timestamp written into junit reports instead of current time (RFC 3339), e.g. for reproducible reports\n"
+		h += "      --timezone	Time zone of timestamps in console summary and junit reports, e.g. Europe/Berlin or Local (default UTC)\n"
+		h += "      --rfc3339	Print start and end times of console summary in RFC 3339\n"
+		h += "      --include-passed-details	Record requests and responses of passed test cases in junit report (system-out)\n"
+		h += "      --allure		Enable allure results reporter\n"
+		h += "      --allure-output	Destination for allure result files\n"
+		h += "      --results-db	Append results into SQLite database file, requires build with '-tags sqlite'\n"
+		h += "      --request-header	Add header to every request, e.g. 'X-Test-Run-Id: {ctx:run_id}'\n"
+		h += "      --user-agent	User-Agent header of requests (default bozr/<version>), e.g. 'bozr ({ctx:run_id})'\n"
+		h += "      --request-timeout	Limit of every request including reading of the response body, e.g. 30s\n"
+		h += "      --sigv4-region	Sign requests with AWS Signature V4 for the region. Credentials are taken from AWS_* env variables\n"
+		h += "      --sigv4-service	AWS service name used for Signature V4, e.g. execute-api\n"
+		h += "      --insecure	Do not verify server certificates, e.g. self-signed ones of test environments\n"
 		h += "  -v, --version		Print version information and quit\n\n"
 
 		h += "Examples:\n"
@@ -48,47 +93,109 @@ func init() {
 }
 
 var (
-	suitesDir       string
-	hostFlag        string
-	workersFlag     int
-	throttleFlag    int
-	infoFlag        bool
-	infoCurlFlag    bool
-	debugFlag       bool
-	helpFlag        bool
-	versionFlag     bool
-	junitFlag       bool
-	junitOutputFlag string
-
-	debug *log.Logger
+	suitesDir               string
+	configFlag              string
+	hostFlag                string
+	workersFlag             int
+	throttleFlag            int
+	infoFlag                bool
+	infoCurlFlag            bool
+	maxBodyLogFlag          int
+	maxResponseSizeFlag     int64
+	treeSummaryFlag         bool
+	compactFlag             bool
+	colorFlag               string
+	showOffsetsFlag         bool
+	debugFlag               bool
+	helpFlag                bool
+	versionFlag             bool
+	junitFlag               bool
+	junitPrettyFlag         bool
+	junitOutputFlag         string
+	suiteNameTemplateFlag   string
+	packageTemplateFlag     string
+	allureFlag              bool
+	allureOutFlag           string
+	outputJSONFlag          string
+	outputJUnitFlag         string
+	resultsDBFlag           string
+	maxFailuresFlag         int
+	repeatRunFlag           int
+	changedFilesFlag        string
+	envFlag                 string
+	beforeAllFlag           string
+	afterAllFlag            string
+	stopTimeoutFlag         = runner.DefaultStopTimeout
+	reporterFlags           reporterList
+	allowDuplicateNamesFlag bool
+	perfThresholdFlags      perfThresholds
+	latencyStabilityFlag    latencyStability
+	requestHeaderFlags      requestHeaderList
+	sigV4Region             string
+	sigV4Service            string
+	insecureFlag            bool
+	requestTimeoutFlag      time.Duration
+	userAgentFlag           string
+	printConfigFlag         bool
+	bailOnErrorFlag         bool
+	strictSkipsFlag         bool
+	coverageSpecFlag        string
+	requireCoverageFlag     bool
+	exportVarsFlag          string
+	exportVarsOutputFlag    string
+	heartbeatFlag           time.Duration
+	passedDetailsFlag       bool
+	fixedTimestampFlag      string
+	timezoneFlag            string
+	rfc3339Flag             bool
 )
 
 const (
-	suiteExt        = ".suite.json"
-	ignoredSuiteExt = ".xsuite.json"
+	// some of test cases failed
+	exitCodeFailed = 1
+	// conventional exit code of process terminated by SIGINT
+	exitCodeInterrupted = 130
 )
 
-func initLogger() {
-	debugHandler := ioutil.Discard
-
-	if debugFlag {
-		debugHandler = os.Stdout
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == initCommand {
+		if err := runInit(os.Args[2:]); err != nil {
+			terminate(err.Error())
+		}
+		return
 	}
 
-	debug = log.New(debugHandler, "DEBUG: ", log.Ltime|log.Lshortfile)
-}
-
-func main() {
 	flag.BoolVar(&debugFlag, "d", false, "Enable debug mode.")
 	flag.BoolVar(&debugFlag, "debug", false, "Enable debug mode")
 
 	flag.BoolVar(&infoFlag, "i", false, "Enable info mode. Print request and response details.")
 	flag.BoolVar(&infoFlag, "info", false, "Enable info mode. Print request and response details.")
 	flag.BoolVar(&infoCurlFlag, "info-curl", false, "Enable info mode. Print request and response details. Request is printed as curl command")
+	flag.BoolVar(&treeSummaryFlag, "tree-summary", false, "Print results grouped by package into a tree with per-package counts in the console summary")
+	flag.BoolVar(&compactFlag, "compact", false, "Print one line per test case in console output, details are printed only for failed ones")
+	flag.StringVar(&colorFlag, "color", runner.ColorAuto, "Color console output: auto (only if it is a terminal), always (even if piped) or never")
+	flag.BoolVar(&showOffsetsFlag, "show-offsets", false, "Prefix request lines in console output with offset of request start from test case start, e.g. +120ms")
+	flag.IntVar(&maxBodyLogFlag, "max-body-log", runner.DefaultMaxBodyLog, "Max size in bytes of request and response body in logs and reports, -1 for no limit. Binary bodies are replaced with their size")
+	flag.Int64Var(&maxResponseSizeFlag, "max-response-size", runner.DefaultMaxResponseSize, "Max size in bytes of response body read into memory, -1 for no limit. Body assertions of larger responses fail")
 
 	flag.StringVar(&hostFlag, "H", "", "Test server address. Example: http://example.com/api.")
+	flag.StringVar(&hostFlag, "base-url", "", "Base URL prefix for test calls. Example: http://example.com/api.")
 	flag.IntVar(&workersFlag, "w", 1, "Execute test sutes in parallel with provided numer of workers. Default is 1.")
 	flag.IntVar(&throttleFlag, "throttle", 0, "Execute no more than specified number of requests per second (in suite)")
+	flag.DurationVar(&stopTimeoutFlag, "stop-timeout", runner.DefaultStopTimeout, "Grace period for in-flight requests once the run is stopped, e.g. interrupted. Requests are cancelled after it")
+	flag.StringVar(&envFlag, "env", "", "Environment name to apply expectations declared for it in 'expectEnv' of calls, e.g. prod")
+	flag.StringVar(&changedFilesFlag, "changed-files", "", "Run only suites affected by changed files listed one per line in the file, '-' to read the list from stdin")
+	flag.StringVar(&beforeAllFlag, "before-all", "", "Suite file executed once before all test suites, e.g. to seed test data. Its failure aborts the run")
+	flag.StringVar(&afterAllFlag, "after-all", "", "Suite file executed once after all test suites, even if they failed")
+	flag.IntVar(&repeatRunFlag, "repeat-run", 1, "Run test suites specified number of times and report flaky test cases, which passed in some runs and failed in others")
+	flag.BoolVar(&bailOnErrorFlag, "bail-on-error", false, "Abort the run on the first transport error (unresolved host, refused connection, TLS error, timeout), failed expectations do not abort it")
+	flag.BoolVar(&strictSkipsFlag, "strict-skips", false, "Fail the run if any test case is skipped, e.g. to catch accidentally disabled tests in CI")
+	flag.StringVar(&coverageSpecFlag, "coverage-spec", "", "OpenAPI document (JSON) which operations called during the run are reported after it")
+	flag.BoolVar(&requireCoverageFlag, "require-coverage", false, "Fail the run if any operation of --coverage-spec is not called by test suites")
+	flag.StringVar(&exportVarsFlag, "export-vars", "", "Comma separated variables (args and remembered values) of test cases written after the run, e.g. createdId,token. Value of the last test case wins")
+	flag.StringVar(&exportVarsOutputFlag, "export-vars-output", "./vars.json", "JSON file of exported variables, or 'github' to write GitHub Actions step outputs ($GITHUB_OUTPUT)")
+	flag.DurationVar(&heartbeatFlag, "heartbeat", 0, "Print progress line to stderr at the interval during the run, e.g. 30s, so CI does not treat long run as hung. Default is 0 (disabled)")
+	flag.IntVar(&maxFailuresFlag, "max-failures", 0, "Abort the run once specified number of test cases failed. Default is 0 (no limit)")
 
 	flag.BoolVar(&helpFlag, "h", false, "Print usage")
 	flag.BoolVar(&helpFlag, "help", false, "Print usage")
@@ -97,11 +204,45 @@ func main() {
 	flag.BoolVar(&versionFlag, "version", false, "Print version information and quit")
 
 	flag.BoolVar(&junitFlag, "junit", false, "Enable junit xml reporter")
+	flag.BoolVar(&junitPrettyFlag, "junit-pretty", false, "Indent junit xml report")
+	flag.StringVar(&suiteNameTemplateFlag, "junit-suite-name-template", "", "Template of suite name attribute in junit report, fields are .Name, .Package, .FullName and .Env, function env reads OS environment variable")
+	flag.StringVar(&packageTemplateFlag, "junit-package-template", "", "Template of suite package attribute in junit report, fields are .Name, .Package, .FullName and .Env, e.g. '{{.Env}}.{{.Package}}'")
+	flag.BoolVar(&passedDetailsFlag, "include-passed-details", false, "Record requests and responses of passed test cases in junit report (system-out of test case), e.g. for audit. Reports could be large")
+	flag.StringVar(&fixedTimestampFlag, "fixed-timestamp", "", "Timestamp written into junit reports instead of current time, RFC 3339, e.g. 2021-03-01T12:00:00Z. Makes reports reproducible")
+	flag.StringVar(&timezoneFlag, "timezone", "UTC", "Time zone of timestamps in console summary and junit reports, IANA name (e.g. Europe/Berlin) or Local for the zone of the host")
+	flag.BoolVar(&rfc3339Flag, "rfc3339", false, "Print start and end times of console summary in RFC 3339, e.g. 2021-03-01T12:00:00.000Z")
 	flag.StringVar(&junitOutputFlag, "junit-output", "./report", "Destination for junit report files. Default ")
 
+	flag.Var(&reporterFlags, "reporter", "Reporter to use as name[:output], could be repeated. Output is stdout, stderr or file, e.g. json:stdout. Available: "+strings.Join(runner.ReporterNames(), ", ")+". Default is console")
+
+	flag.BoolVar(&allowDuplicateNamesFlag, "allow-duplicate-names", false, "Do not fail on duplicate test case names within a suite and duplicate suite names")
+	flag.Var(&requestHeaderFlags, "request-header", "Add header to every request as 'Name: value', e.g. 'X-Test-Run-Id: {ctx:run_id}'. Could be repeated")
+	flag.StringVar(&userAgentFlag, "user-agent", "bozr/"+version, "User-Agent header of requests unless suite or request sets it, could contain variables, e.g. 'bozr/"+version+" ({ctx:run_id})'")
+	flag.Var(&perfThresholdFlags, "perf-threshold", "Fail the run if percentile of request durations exceeds the limit, e.g. p99=800ms. Could be repeated")
+	flag.Var(&latencyStabilityFlag, "latency-stability", "Fail repeated run if variation of request durations across runs exceeds the limit: coefficient of variation (cv=0.3) or max/min ratio (ratio=2)")
+
+	flag.StringVar(&outputJSONFlag, "output-json", "", "Write JSON report into the file in addition to console output")
+	flag.StringVar(&outputJUnitFlag, "output-junit", "", "Write junit xml reports into the directory in addition to console output")
+	flag.StringVar(&resultsDBFlag, "results-db", "", "Append results of the run into SQLite database file (bozr built with '-tags sqlite'), e.g. to query trends")
+
+	flag.BoolVar(&allureFlag, "allure", false, "Enable allure results reporter")
+	flag.StringVar(&allureOutFlag, "allure-output", "./allure-results", "Destination for allure result files")
+
+	flag.BoolVar(&insecureFlag, "insecure", false, "Do not verify server certificates, e.g. self-signed ones of test environments")
+	flag.DurationVar(&requestTimeoutFlag, "request-timeout", 0, "Limit of every request including reading of the response body, e.g. 30s. Default is 0 (no limit), suite 'client' settings override it")
+
+	flag.StringVar(&sigV4Region, "sigv4-region", "", "Sign requests with AWS Signature V4 for the region")
+	flag.StringVar(&sigV4Service, "sigv4-service", "execute-api", "AWS service name used for Signature V4")
+
+	flag.StringVar(&configFlag, "config", "", "Path to JSON or YAML config file with default values of options. Default is the first existing of "+strings.Join(defaultConfigFiles, ", "))
+	flag.BoolVar(&printConfigFlag, "print-config", false, "Print effective options resolved from command line and config file, selected reporters and variable names, then quit")
+
 	flag.Parse()
 
-	initLogger()
+	if err := applyConfig(flag.CommandLine, configFlag); err != nil {
+		terminate(err.Error())
+		return
+	}
 
 	if versionFlag {
 		fmt.Println("bozr version " + version)
@@ -113,6 +254,11 @@ func main() {
 		return
 	}
 
+	if printConfigFlag {
+		printConfig(os.Stdout, flag.CommandLine)
+		return
+	}
+
 	if len(hostFlag) > 0 {
 		_, err := url.ParseRequestURI(hostFlag)
 		if err != nil {
@@ -121,6 +267,53 @@ func main() {
 		}
 	}
 
+	if repeatRunFlag < 1 {
+		terminate("Invalid number of runs: " + strconv.Itoa(repeatRunFlag))
+	}
+
+	if err := runner.ValidateColorMode(colorFlag); err != nil {
+		terminate(err.Error())
+		return
+	}
+	color.NoColor = !runner.ColorEnabled(colorFlag, os.Stdout)
+
+	for _, tmpl := range []string{suiteNameTemplateFlag, packageTemplateFlag} {
+		if err := runner.ValidateJUnitTemplate(tmpl); err != nil {
+			terminate(err.Error())
+			return
+		}
+	}
+
+	if fixedTimestampFlag != "" {
+		if _, err := time.Parse(time.RFC3339, fixedTimestampFlag); err != nil {
+			terminate("Invalid fixed timestamp, expected RFC 3339 time, e.g. 2021-03-01T12:00:00Z")
+			return
+		}
+	}
+
+	if _, err := time.LoadLocation(timezoneFlag); err != nil {
+		terminate("Invalid time zone '" + timezoneFlag + "', expected IANA name, e.g. Europe/Berlin, UTC or Local")
+		return
+	}
+
+	for _, spec := range reporterSpecs() {
+		name, _ := parseReporterSpec(spec)
+		if err := runner.ValidateReporter(name); err != nil {
+			terminate(err.Error())
+			return
+		}
+	}
+
+	if latencyStabilityFlag.value != nil && repeatRunFlag < 2 {
+		terminate("Latency stability requires at least 2 runs, see --repeat-run")
+		return
+	}
+
+	if requireCoverageFlag && coverageSpecFlag == "" {
+		terminate("Coverage requirement needs OpenAPI document, see --coverage-spec")
+		return
+	}
+
 	if workersFlag < 1 || workersFlag > 9 {
 		fmt.Println("Invalid number of workers:  [", workersFlag, "]. Setting to default [1]")
 		workersFlag = 1
@@ -142,364 +335,385 @@ func main() {
 		return
 	}
 
-	err = ValidateSuites(suitesDir, suiteExt, ignoredSuiteExt)
-	if err != nil {
-		terminate("One or more test suites are invalid.", err.Error())
-		return
+	var changedFiles []string
+	if changedFilesFlag != "" {
+		changedFiles, err = readChangedFiles(changedFilesFlag, os.Stdin)
+		if err != nil {
+			terminate(err.Error())
+			return
+		}
 	}
 
-	loader := NewSuiteLoader(suitesDir, suiteExt, ignoredSuiteExt)
-	reporter := createReporter()
-
-	RunParallel(loader, reporter, runSuite, workersFlag)
-}
-
-func runSuite(suite TestSuite) []TestResult {
-	results := []TestResult{}
-
-	throttle := NewThrottle(throttleFlag, time.Second)
-
-	for _, testCase := range suite.Cases {
-
-		result := TestResult{
-			Suite:     suite,
-			Case:      testCase,
-			ExecFrame: TimeFrame{Start: time.Now(), End: time.Now()},
+	var middlewares []runner.RequestMiddleware
+	if sigV4Region != "" {
+		signer := runner.SigV4Signer{
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			Region:       sigV4Region,
+			Service:      sigV4Service,
 		}
+		middlewares = append(middlewares, signer.Sign)
+	}
 
-		if testCase.Ignore != nil {
-			result.Skipped = true
-			result.SkippedMsg = *testCase.Ignore
-
-			results = append(results, result)
-			continue
+	var debugOutput io.Writer
+	if debugFlag {
+		debugOutput = os.Stdout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	handleInterrupt(cancel)
+
+	opts := runner.Options{
+		Path:                suitesDir,
+		BaseURL:             hostFlag,
+		Workers:             workersFlag,
+		Throttle:            throttleFlag,
+		MaxFailures:         maxFailuresFlag,
+		BailOnError:         bailOnErrorFlag,
+		StopTimeout:         stopTimeoutFlag,
+		RequestHeaders:      requestHeaderFlags,
+		UserAgent:           userAgentFlag,
+		Vars:                configVars,
+		AllowDuplicateNames: allowDuplicateNamesFlag,
+		CurlDump:            infoCurlFlag,
+		Insecure:            insecureFlag,
+		RequestTimeout:      requestTimeoutFlag,
+		MaxBodyLog:          maxBodyLogFlag,
+		MaxResponseSize:     maxResponseSizeFlag,
+		ChangedFiles:        changedFiles,
+		Env:                 envFlag,
+		BeforeAll:           beforeAllFlag,
+		AfterAll:            afterAllFlag,
+		Middlewares:         middlewares,
+		DebugOutput:         debugOutput,
+		Heartbeat:           heartbeatFlag,
+	}
+
+	var exportVars []string
+	for _, name := range strings.Split(exportVarsFlag, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			exportVars = append(exportVars, name)
 		}
+	}
+	opts.ExportVars = exportVars
 
-		vars := NewVars(hostFlag)
-		callArgsErr := vars.AddAll(testCase.Args)
-		for i, c := range testCase.Calls {
-
-			throttle.RunOrPause()
+	timings := &runner.TimingsReporter{}
 
-			if callArgsErr != nil {
-				result.Traces = append(result.Traces, &CallTrace{ErrorCause: callArgsErr, Num: i})
-				break
-			}
+	exported := &runner.VarsExportReporter{Names: exportVars, Output: exportVarsOutputFlag}
 
-			err := vars.AddAll(c.Args)
-			if err != nil {
-				result.Traces = append(result.Traces, &CallTrace{ErrorCause: err, Num: i})
-				break
-			}
+	var coverage *runner.CoverageReporter
+	if coverageSpecFlag != "" {
+		coverage, err = runner.NewCoverageReporter(coverageSpecFlag, requireCoverageFlag)
+		if err != nil {
+			terminate(err.Error())
+			return
+		}
+	}
 
-			trace := call(suite.Dir, c, vars)
-			trace.Num = i
+	var runs [][]runner.TestResult
+	for i := 0; i < repeatRunFlag; i++ {
+		// every run is reported separately
+		reporter, err := createReporter(i > 0)
+		if err != nil {
+			terminate(err.Error())
+			return
+		}
 
-			result.Traces = append(result.Traces, trace)
+		var collectors []runner.Reporter
+		if len(perfThresholdFlags) > 0 {
+			collectors = append(collectors, timings)
+		}
+		if coverage != nil {
+			collectors = append(collectors, coverage)
+		}
+		if len(exportVars) > 0 {
+			collectors = append(collectors, exported)
+		}
+		if len(collectors) > 0 {
+			reporter = runner.NewPrimaryMultiReporter(reporter, collectors...)
+		}
+		opts.Reporter = reporter
 
-			if trace.hasError() {
-				break
-			}
+		results, err := runner.Run(ctx, opts)
+		if err != nil {
+			terminate("One or more test suites are invalid.", err.Error())
+			return
 		}
 
-		unused := vars.Unused()
-		if len(unused) != 0 {
-			traces := result.Traces
-			lastTrace := traces[len(traces)-1]
-			if lastTrace.ErrorCause == nil {
-				lastTrace.ErrorCause = fmt.Errorf("Declared/remembered arguments are not used: %s", unused)
-			}
+		if ctx.Err() != nil {
+			os.Exit(exitCodeInterrupted)
 		}
 
-		result.ExecFrame.End = time.Now()
+		if err := runner.FlushError(reporter); err != nil {
+			os.Exit(exitCodeFailed)
+		}
 
-		results = append(results, result)
+		runs = append(runs, results)
 	}
 
-	return results
-}
-
-func createReporter() Reporter {
-	reporters := []Reporter{NewConsoleReporter(infoFlag||infoCurlFlag)}
-	if junitFlag {
-		path, _ := filepath.Abs(junitOutputFlag)
-		reporters = append(reporters, NewJUnitReporter(path))
+	if repeatRunFlag > 1 {
+		// stdout could be taken by report stream, e.g. json:stdout
+		runner.WriteRunsSummary(os.Stderr, runs)
 	}
-	reporter := NewMultiReporter(reporters...)
-	reporter.Init()
-
-	return reporter
-}
 
-func call(suitePath string, call Call, vars *Vars) *CallTrace {
+	if code := resultsExitCode(os.Stderr, runs, strictSkipsFlag); code != 0 {
+		os.Exit(code)
+	}
 
-	trace := &CallTrace{}
-	execStart := time.Now()
+	if err := timings.Check(perfThresholdFlags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCodeFailed)
+	}
 
-	on := call.On
+	if coverage != nil {
+		if err := coverage.Check(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCodeFailed)
+		}
+	}
 
-	bodyTmpl, err := on.BodyContent(suitePath)
-	if err != nil {
-		trace.ErrorCause = err
-		return trace
+	if err := runner.CheckIncreasing(runs); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCodeFailed)
 	}
 
-	tmplCtx := NewTemplateContext(vars)
+	if latencyStabilityFlag.value != nil {
+		if err := latencyStabilityFlag.value.Check(runs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCodeFailed)
+		}
+	}
+}
 
-	bodyToSend := tmplCtx.ApplyTo(bodyTmpl)
-	if tmplCtx.HasErrors() {
-		trace.ErrorCause = tmplCtx.Error()
-		return trace
+// resultsExitCode is exitCodeFailed if any test case failed, or was skipped in strict mode. Skipped test cases are listed in strict mode.
+func resultsExitCode(w io.Writer, runs [][]runner.TestResult, strictSkips bool) int {
+	code := 0
+	var skipped []string
+	for _, results := range runs {
+		for _, result := range results {
+			if result.HasError() {
+				code = exitCodeFailed
+			}
+			if result.Skipped {
+				skipped = append(skipped, fmt.Sprintf("  %s / %s: %s", result.Suite.FullName(), result.Case.Name, result.SkippedMsg))
+			}
+		}
 	}
 
-	req, err := populateRequest(on, bodyToSend, tmplCtx)
-	if err != nil {
-		trace.ErrorCause = err
-		return trace
+	if strictSkips && len(skipped) > 0 {
+		fmt.Fprintf(w, "%d skipped test case(s) fail the run (--strict-skips):\n%s\n", len(skipped), strings.Join(skipped, "\n"))
+		code = exitCodeFailed
 	}
 
-	trace.RequestDump = dumpRequest(req, bodyToSend, infoCurlFlag)
-	trace.RequestMethod = req.Method
-	trace.RequestURL = req.URL.String()
+	return code
+}
 
-	client := &http.Client{}
+// handleInterrupt stops the run on first SIGINT/SIGTERM so partial results are still flushed.
+// Second signal terminates the process immediately.
+func handleInterrupt(cancel context.CancelFunc) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
 
-	resp, err := client.Do(req)
+	go func() {
+		<-signals
+		signal.Stop(signals)
 
-	if err != nil {
-		debug.Print("Error when sending request", err)
-		trace.ErrorCause = err
-		return trace
-	}
+		fmt.Fprintln(os.Stderr, "Interrupted. Waiting for running test cases to finish...")
+		cancel()
+	}()
+}
 
-	defer resp.Body.Close()
+// requestHeaderList collects values of repeated '--request-header' option, e.g. 'X-Test-Run-Id: {ctx:run_id}'
+type requestHeaderList []string
 
-	trace.ExecFrame = TimeFrame{Start: execStart, End: time.Now()}
+func (l *requestHeaderList) String() string {
+	return strings.Join(*l, ",")
+}
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		debug.Print("Error reading response")
-		trace.ErrorCause = err
-		return trace
+func (l *requestHeaderList) Set(value string) error {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return fmt.Errorf("Invalid request header '%s'. Expected format: 'Name: value'", value)
 	}
 
-	testResp := Response{http: resp, body: body}
-	trace.ResponseDump = testResp.ToString()
+	*l = append(*l, value)
+	return nil
+}
 
-	if err = call.Expect.populateWith(vars); err != nil {
-		trace.ErrorCause = err
-		return trace
+// readChangedFiles reads list of changed files, one per line, from the file or stdin if path is '-'
+func readChangedFiles(path string, stdin io.Reader) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
 	}
-
-	exps, err := expectations(call.Expect, suitePath)
 	if err != nil {
-		trace.ErrorCause = err
-		return trace
+		return nil, fmt.Errorf("Can't read changed files: %s", err)
 	}
 
-	for _, exp := range exps {
-		checkErr := exp.check(&testResp)
-
-		if checkErr != nil {
-			trace.addFail(checkErr)
-			return trace
+	files := []string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
 		}
-
-		trace.addExp(exp.desc())
 	}
 
-	err = rememberBody(&testResp, call.Remember.BPath, vars)
-	debug.Print(vars)
-	if err != nil {
-		debug.Print("Error remember")
-		trace.ErrorCause = err
-		return trace
-	}
+	return files, nil
+}
 
-	rememberHeaders(testResp.http.Header, call.Remember.Headers, vars)
+// reporterList collects values of repeated '--reporter' option
+type reporterList []string
 
-	return trace
+func (l *reporterList) String() string {
+	return strings.Join(*l, ",")
 }
 
-func populateRequest(on On, body string, tmplCtx *TemplateContext) (*http.Request, error) {
+func (l *reporterList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
 
-	urlStr, err := urlPrefix(tmplCtx.ApplyTo(on.URL))
-	if err != nil {
-		return nil, errors.New("Cannot create request. Invalid url: " + on.URL)
+// parseReporterSpec splits reporter option into name and output, e.g. 'junit:./out'
+func parseReporterSpec(spec string) (string, string) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
 	}
 
-	dat := []byte(body)
-
-	req, err := http.NewRequest(on.Method, urlStr, bytes.NewBuffer(dat))
-	if err != nil {
-		return nil, err
-	}
+	return parts[0], parts[1]
+}
 
-	for key, valueTmpl := range on.Headers {
-		req.Header.Add(key, tmplCtx.ApplyTo(valueTmpl))
+// reporterSpecs lists selected reporters as name[:output]
+func reporterSpecs() []string {
+	specs := append(reporterList{}, reporterFlags...)
+	if len(specs) == 0 {
+		specs = reporterList{"console"}
 	}
 
-	q := req.URL.Query()
-	for key, valueTmpl := range on.Params {
-		q.Add(key, tmplCtx.ApplyTo(valueTmpl))
+	// structured outputs are added to the console (or explicitly selected) reporters
+	if outputJSONFlag != "" {
+		specs = append(specs, "json:"+outputJSONFlag)
 	}
-
-	req.URL.RawQuery = q.Encode()
-
-	if tmplCtx.HasErrors() {
-		return nil, tmplCtx.Error()
+	if outputJUnitFlag != "" {
+		specs = append(specs, "junit:"+outputJUnitFlag)
 	}
-
-	return req, nil
-}
-
-func urlPrefix(p string) (string, error) {
-	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
-		return p, nil
+	if resultsDBFlag != "" {
+		specs = append(specs, "sqlite:"+resultsDBFlag)
 	}
 
-	return concatURL(hostFlag, p)
+	return specs
 }
 
-func concatURL(base string, p string) (string, error) {
-	baseURL, err := url.ParseRequestURI(base)
-	if err != nil {
-		return "", err
-	}
-	return baseURL.Scheme + "://" + baseURL.Host + path.Join(baseURL.Path, p), nil
-}
+// createReporter creates reporters selected for the run, console file output is appended by repeated runs
+func createReporter(repeated bool) (runner.Reporter, error) {
+	logHTTP := infoFlag || infoCurlFlag
 
-func expectations(expect Expect, suitePath string) ([]ResponseExpectation, error) {
-	var exps []ResponseExpectation
-	if expect.StatusCode != 0 {
-		exps = append(exps, StatusCodeExpectation{statusCode: expect.StatusCode})
-	}
+	var reporters []runner.Reporter
+	for _, spec := range reporterSpecs() {
+		name, output := parseReporterSpec(spec)
 
-	if expect.BodySchemaURI != "" {
-		schema, err := expect.loadSchemaFromURI()
+		selected, err := runner.NewReporter(name, runner.ReporterOptions{Output: output, LogHTTP: logHTTP, Pretty: junitPrettyFlag, TreeSummary: treeSummaryFlag, ShowOffsets: showOffsetsFlag, Compact: compactFlag, Color: colorFlag, PassedDetails: passedDetailsFlag, Now: reportClock(), SuiteNameTemplate: suiteNameTemplateFlag, PackageTemplate: packageTemplateFlag, Location: reportTimezone(), RFC3339: rfc3339Flag, Append: repeated})
 		if err != nil {
 			return nil, err
 		}
 
-		exps = append(exps, BodySchemaExpectation{
-			schema:      schema,
-			displayName: expect.BodySchemaURI,
-		})
+		reporters = append(reporters, selected)
 	}
 
-	if expect.BodySchemaFile != "" {
-		schema, err := expect.loadSchemaFromFile(suitePath)
-		if err != nil {
-			return nil, err
-		}
-		exps = append(exps, BodySchemaExpectation{
-			schema:      schema,
-			displayName: expect.BodySchemaFile,
-		})
+	if junitFlag {
+		path, _ := filepath.Abs(junitOutputFlag)
+		reporters = append(reporters, &runner.JUnitXMLReporter{OutPath: path, Pretty: junitPrettyFlag, PassedDetails: passedDetailsFlag, Now: reportClock(), NameTemplate: suiteNameTemplateFlag, PackageTemplate: packageTemplateFlag, Location: reportTimezone()})
 	}
-
-	if expect.BodySchemaRaw != nil {
-		exps = append(exps, BodySchemaExpectation{
-			schema:      expect.BodySchemaRaw,
-			displayName: "",
-		})
+	if allureFlag {
+		path, _ := filepath.Abs(allureOutFlag)
+		reporters = append(reporters, runner.NewAllureReporter(path))
 	}
 
-	if len(expect.BodyPath()) > 0 {
-		exps = append(exps, BodyPathExpectation{pathExpectations: expect.BodyPath()})
-	}
-
-	if expect.Body != nil {
-		exps = append(exps, BodyExpectation{ExpectedBody: expect.Body, Strict: false})
+	// console summary is printed once all report files are written
+	multi := &runner.MultiReporter{Reporters: reporters}
+	for _, reporter := range reporters {
+		if console, ok := reporter.(*runner.ConsoleReporter); ok {
+			multi.Primary = console
+			break
+		}
 	}
 
-	if expect.ExactBody != nil {
-		exps = append(exps, BodyExpectation{ExpectedBody: expect.ExactBody, Strict: true})
-	}
+	return multi, nil
+}
 
-	if len(expect.Absent) > 0 {
-		exps = append(exps, AbsentExpectation{paths: expect.Absent})
+// reportClock returns time fixed with '--fixed-timestamp' for reports, nil if reports use current time
+func reportClock() func() time.Time {
+	if fixedTimestampFlag == "" {
+		return nil
 	}
 
-	if len(expect.Headers) > 0 {
-		for k, v := range expect.Headers {
-			exps = append(exps, HeaderExpectation{Name: k, Value: v})
-		}
-	}
+	fixed, _ := time.Parse(time.RFC3339, fixedTimestampFlag)
+	return func() time.Time { return fixed }
+}
 
-	if expect.ContentType != "" {
-		exps = append(exps, ContentTypeExpectation{expect.ContentType})
+// reportTimezone returns time zone of '--timezone' for reports, it is validated at startup
+func reportTimezone() *time.Location {
+	loc, err := time.LoadLocation(timezoneFlag)
+	if err != nil {
+		return time.UTC
 	}
 
-	// and so on
-	return exps, nil
+	return loc
 }
 
-func rememberBody(resp *Response, remember map[string]string, vars *Vars) (err error) {
-
-	for varName, pathLine := range remember {
-		body, err := resp.Body()
-		if err != nil {
-			debug.Print("Can't parse response body to Map for [remember]")
-			return err
-		}
-
-		if rememberVar, err := GetByPath(body, pathLine); err == nil {
-			vars.Add(varName, rememberVar)
-		} else {
-			debug.Print(err)
-			return fmt.Errorf("Remembered value not found, path: %v", pathLine)
-		}
+func terminate(msgLines ...string) {
+	for _, line := range msgLines {
+		fmt.Fprintln(os.Stderr, line)
 	}
 
-	return err
+	os.Exit(1)
 }
 
-func rememberHeaders(header http.Header, remember map[string]string, vars *Vars) {
-	for valueName, headerName := range remember {
-		value := header.Get(headerName)
-		if value == "" {
-			continue
-		}
-
-		vars.Add(valueName, value)
-	}
-}
+// perfThresholds collects values of repeated '--perf-threshold' option
+type perfThresholds []runner.PerfThreshold
 
-func dumpRequest(req *http.Request, body string, dumpAsCurl bool) string {
-	if dumpAsCurl {
-		command, _ := http2curl.GetCurlCommand(req)
-		return command.String()
+func (l *perfThresholds) String() string {
+	values := make([]string, 0, len(*l))
+	for _, t := range *l {
+		values = append(values, t.String())
 	}
-	buf := bytes.NewBufferString("")
 
-	buf.WriteString(fmt.Sprintf("%s %s %s\n", req.Method, req.URL.String(), req.Proto))
+	return strings.Join(values, ",")
+}
 
-	for k, v := range req.Header {
-		buf.WriteString(fmt.Sprintf("%s: %s\n", k, strings.Join(v, " ")))
+func (l *perfThresholds) Set(value string) error {
+	threshold, err := runner.ParsePerfThreshold(value)
+	if err != nil {
+		return err
 	}
 
-	if len(body) > 0 {
-		buf.WriteString("\n")
-		buf.WriteString(body)
-	}
+	*l = append(*l, threshold)
+	return nil
+}
 
-	return buf.String()
+// latencyStability is a value of '--latency-stability' option, nil if not set
+type latencyStability struct {
+	value *runner.LatencyStability
 }
 
-func terminate(msgLines ...string) {
-	for _, line := range msgLines {
-		fmt.Fprintln(os.Stderr, line)
+func (l *latencyStability) String() string {
+	if l.value == nil {
+		return ""
 	}
 
-	os.Exit(1)
+	return l.value.String()
 }
 
-func debugf(format string, v ...interface{}) {
-	if debug == nil {
-		// fmt.Printf(format, v...)
-		return
+func (l *latencyStability) Set(value string) error {
+	stability, err := runner.ParseLatencyStability(value)
+	if err != nil {
+		return err
 	}
 
-	debug.Printf(format, v...)
+	l.value = &stability
+	return nil
 }
@@ -1,86 +1,204 @@
 package main
 
 import (
-	"net/http"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kajf/bozr/runner"
 )
 
-func TestRememberBodyLazy(t *testing.T) {
-	resp := Response{
-		http: &http.Response{
-			Header: map[string][]string{"Content-Type": {"application/json"}},
-		},
-		body: []byte(`# invalid body so it fails if parsed`),
-	}
+type fakeReporter struct {
+	opts runner.ReporterOptions
+}
+
+func (r *fakeReporter) Init() {}
+
+func (r *fakeReporter) Report(results []runner.TestResult) {}
+
+func (r *fakeReporter) Flush() {}
+
+func TestRegisterReporterSelectedByName(t *testing.T) {
+	// given
+	var created *fakeReporter
+	runner.RegisterReporter("fake", func(opts runner.ReporterOptions) runner.Reporter {
+		created = &fakeReporter{opts: opts}
+		return created
+	})
+
+	reporterFlags = reporterList{"fake:./out"}
+	defer func() { reporterFlags = nil }()
 
-	err := rememberBody(&resp, map[string]string{}, NewVars(""))
+	// when
+	reporter, err := createReporter(false)
 
+	// then
 	if err != nil {
-		t.Error(err)
+		t.Fatal(err)
+	}
+
+	multi, ok := reporter.(*runner.MultiReporter)
+	if !ok || len(multi.Reporters) != 1 || multi.Reporters[0] != created {
+		t.Fatalf("Expected registered reporter to be selected, got: %#v", reporter)
+	}
+
+	if created.opts.Output != "./out" {
+		t.Errorf("Expected reporter output to be passed, got: %s", created.opts.Output)
 	}
 }
 
-func TestRunSuite_InvalidUrlAndUnused_OnlyInvalidUrl(t *testing.T) {
-	suite := TestSuite{
-		Cases: []TestCase{
-			{
-				Calls: []Call{
-					{
-						Args: map[string]interface{}{"a": 1},
-						On: On{
-							URL: "my-invalid-host",
-						},
-					},
-				},
-			},
-		},
+func TestCreateReporterFromRepeatedFlags(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	flags := flag.NewFlagSet("bozr", flag.ContinueOnError)
+	flags.Var(&reporterFlags, "reporter", "")
+	defer func() { reporterFlags = nil }()
+
+	err := flags.Parse([]string{"--reporter", "console", "--reporter", "junit:" + dir})
+	if err != nil {
+		t.Fatal(err)
 	}
 
-	results := runSuite(suite)
+	// when
+	reporter, err := createReporter(false)
 
-	err := results[0].Traces[0].ErrorCause
-	if err == nil || !strings.Contains(err.Error(), "Invalid url") {
-		t.Error("Expected error not thrown", err)
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	multi := reporter.(*runner.MultiReporter)
+	if len(multi.Reporters) != 2 {
+		t.Fatalf("Expected 2 reporters, got %d", len(multi.Reporters))
+	}
+
+	if _, ok := multi.Reporters[0].(*runner.ConsoleReporter); !ok {
+		t.Errorf("Expected console reporter, got %T", multi.Reporters[0])
+	}
+
+	if multi.Primary != multi.Reporters[0] {
+		t.Errorf("Expected console reporter to be flushed last, got primary %T", multi.Primary)
+	}
+
+	junit, ok := multi.Reporters[1].(*runner.JUnitXMLReporter)
+	if !ok {
+		t.Fatalf("Expected junit reporter, got %T", multi.Reporters[1])
+	}
+
+	if junit.OutPath != dir {
+		t.Errorf("Unexpected junit output: %s", junit.OutPath)
 	}
 }
 
-func TestConcatURL(t *testing.T) {
+func TestCreateReporterWithOutputFlags(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-output")
+	defer os.RemoveAll(dir)
 
-	t.Run("open base and closed path", func(t *testing.T) {
-		base := "http://example.com"
-		path := "/api/v1/example"
-		url, _ := concatURL(base, path)
-		if url != "http://example.com/api/v1/example" {
-			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
-		}
-	})
+	outputJSONFlag = filepath.Join(dir, "report.json")
+	outputJUnitFlag = filepath.Join(dir, "junit")
+	defer func() { outputJSONFlag, outputJUnitFlag = "", "" }()
 
-	t.Run("closed base and closed path", func(t *testing.T) {
-		base := "http://example.com/api/"
-		path := "/v1/example"
-		url, _ := concatURL(base, path)
-		if url != "http://example.com/api/v1/example" {
-			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
-		}
-	})
+	defer func(output io.Writer) { color.Output = output }(color.Output)
+	color.Output = ioutil.Discard
 
-	t.Run("closed base and open path", func(t *testing.T) {
-		base := "http://example.com/api/"
-		path := "v1/example"
-		url, _ := concatURL(base, path)
-		if url != "http://example.com/api/v1/example" {
-			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
-		}
-	})
+	// when
+	reporter, err := createReporter(false)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	multi := reporter.(*runner.MultiReporter)
+	if len(multi.Reporters) != 3 {
+		t.Fatalf("Expected console, json and junit reporters, got %d", len(multi.Reporters))
+	}
+
+	console, ok := multi.Reporters[0].(*runner.ConsoleReporter)
+	if !ok {
+		t.Fatalf("Expected console reporter, got %T", multi.Reporters[0])
+	}
+	console.Writer = ioutil.Discard
 
-	t.Run("open base and open path", func(t *testing.T) {
-		base := "http://example.com/api"
-		path := "v1/example"
-		url, _ := concatURL(base, path)
-		if url != "http://example.com/api/v1/example" {
-			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
+	reporter.Init()
+	reporter.Report([]runner.TestResult{{Suite: runner.TestSuite{Name: "users"}, Case: runner.TestCase{Name: "get user"}}})
+	reporter.Flush()
+
+	for _, path := range []string{outputJSONFlag, filepath.Join(outputJUnitFlag, "users.xml")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected output to be produced: %s", err)
 		}
-	})
+	}
+}
+
+func TestCreateReporterFixedTimestamp(t *testing.T) {
+	// given
+	reporterFlags = reporterList{"junit:" + os.TempDir()}
+	fixedTimestampFlag = "2021-03-01T12:00:00Z"
+	defer func() { reporterFlags, fixedTimestampFlag = nil, "" }()
+
+	// when
+	reporter, err := createReporter(false)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	junit := reporter.(*runner.MultiReporter).Reporters[0].(*runner.JUnitXMLReporter)
+	if junit.Now == nil || !junit.Now().Equal(time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Error("Expected fixed timestamp of junit reporter")
+	}
+}
+
+func TestCreateReporterUnknownName(t *testing.T) {
+	reporterFlags = reporterList{"console", "dashboard:http://example.com"}
+	defer func() { reporterFlags = nil }()
 
+	_, err := createReporter(false)
+
+	if err == nil || !strings.Contains(err.Error(), "Unknown reporter 'dashboard'") {
+		t.Error("Expected unknown reporter error, got:", err)
+	}
+}
+
+func TestReadChangedFilesFromStdin(t *testing.T) {
+	files, err := readChangedFiles("-", strings.NewReader("users/get.suite.json\n\n  orders/order.json \n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(files, ",") != "users/get.suite.json,orders/order.json" {
+		t.Errorf("Unexpected changed files: %v", files)
+	}
+}
+
+func TestResultsExitCodeStrictSkips(t *testing.T) {
+	runs := [][]runner.TestResult{{
+		{Suite: runner.TestSuite{Name: "users"}, Case: runner.TestCase{Name: "get"}},
+		{Suite: runner.TestSuite{Name: "users"}, Case: runner.TestCase{Name: "delete"}, Skipped: true, SkippedMsg: "JIRA-1"},
+	}}
+
+	var out strings.Builder
+	if code := resultsExitCode(&out, runs, false); code != 0 || out.Len() > 0 {
+		t.Errorf("Expected zero exit code without --strict-skips, got %d: %s", code, out.String())
+	}
+
+	if code := resultsExitCode(&out, runs, true); code != exitCodeFailed {
+		t.Errorf("Expected non-zero exit code with --strict-skips, got %d", code)
+	}
+
+	if !strings.Contains(out.String(), "1 skipped test case(s) fail the run") || !strings.Contains(out.String(), "users / delete: JIRA-1") {
+		t.Errorf("Expected skipped test case to be listed, got: %s", out.String())
+	}
 }
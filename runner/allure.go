@@ -0,0 +1,240 @@
+package runner
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// AllureReporter writes test results in Allure 2 format.
+// Every test case is stored as separate '<uuid>-result.json' file,
+// request and response dumps are stored as attachments next to it.
+type AllureReporter struct {
+	// output directory
+	OutPath string
+}
+
+const (
+	allureStatusPassed  = "passed"
+	allureStatusFailed  = "failed"
+	allureStatusBroken  = "broken"
+	allureStatusSkipped = "skipped"
+
+	allureStageFinished = "finished"
+)
+
+type allureResult struct {
+	UUID          string              `json:"uuid"`
+	HistoryID     string              `json:"historyId"`
+	Name          string              `json:"name"`
+	FullName      string              `json:"fullName"`
+	Status        string              `json:"status"`
+	StatusDetails *allureStatusDetail `json:"statusDetails,omitempty"`
+	Stage         string              `json:"stage"`
+	Start         int64               `json:"start"`
+	Stop          int64               `json:"stop"`
+	Steps         []allureStep        `json:"steps"`
+	Attachments   []allureAttachment  `json:"attachments"`
+	Labels        []allureLabel       `json:"labels"`
+}
+
+type allureStatusDetail struct {
+	Message string `json:"message,omitempty"`
+	Trace   string `json:"trace,omitempty"`
+}
+
+type allureStep struct {
+	Name          string              `json:"name"`
+	Status        string              `json:"status"`
+	StatusDetails *allureStatusDetail `json:"statusDetails,omitempty"`
+	Stage         string              `json:"stage"`
+	Start         int64               `json:"start"`
+	Stop          int64               `json:"stop"`
+	Steps         []allureStep        `json:"steps"`
+	Attachments   []allureAttachment  `json:"attachments"`
+}
+
+type allureAttachment struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`
+	Type   string `json:"type"`
+}
+
+type allureLabel struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Init prepares output directory
+func (r *AllureReporter) Init() {
+	err := os.MkdirAll(r.OutPath, 0777)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Report writes result file for every test case of the suite
+func (r *AllureReporter) Report(results []TestResult) {
+	for _, result := range results {
+		r.writeResult(r.toAllure(result))
+	}
+}
+
+func (r *AllureReporter) toAllure(result TestResult) allureResult {
+	fullName := result.Suite.FullName() + "." + result.Case.Name
+
+	res := allureResult{
+		UUID:        newUUID(),
+		HistoryID:   fmt.Sprintf("%x", md5.Sum([]byte(fullName))),
+		Name:        result.Case.Name,
+		FullName:    fullName,
+		Status:      allureStatusPassed,
+		Stage:       allureStageFinished,
+		Start:       toMillis(result.ExecFrame.Start),
+		Stop:        toMillis(result.ExecFrame.End),
+		Steps:       []allureStep{},
+		Attachments: []allureAttachment{},
+		Labels: []allureLabel{
+			{Name: "framework", Value: "bozr"},
+			{Name: "suite", Value: result.Suite.Name},
+			{Name: "package", Value: result.Suite.PackageName()},
+		},
+	}
+
+	if result.Skipped {
+		res.Status = allureStatusSkipped
+		res.StatusDetails = &allureStatusDetail{Message: result.SkippedMsg}
+		return res
+	}
+
+	for _, trace := range result.Traces {
+		step := r.toStep(trace)
+		res.Steps = append(res.Steps, step)
+
+		if step.Status != allureStatusPassed {
+			res.Status = step.Status
+			res.StatusDetails = step.StatusDetails
+		}
+	}
+
+	return res
+}
+
+func (r *AllureReporter) toStep(trace *CallTrace) allureStep {
+	name := fmt.Sprintf("Call #%d", trace.Num+1)
+	if trace.RequestMethod != "" {
+		name = fmt.Sprintf("%s %s %s", name, trace.RequestMethod, trace.RequestURL)
+	}
+
+	step := allureStep{
+		Name:        name,
+		Status:      allureStatusPassed,
+		Stage:       allureStageFinished,
+		Start:       toMillis(trace.ExecFrame.Start),
+		Stop:        toMillis(trace.ExecFrame.End),
+		Steps:       []allureStep{},
+		Attachments: []allureAttachment{},
+	}
+
+	// steps are sorted, so results of the same test case are stable between runs
+	for _, exp := range sortedStepNames(trace.ExpDesc) {
+		status := allureStatusPassed
+		if trace.ExpDesc[exp] {
+			status = allureStatusFailed
+		}
+
+		step.Steps = append(step.Steps, allureStep{
+			Name:        exp,
+			Status:      status,
+			Stage:       allureStageFinished,
+			Start:       step.Start,
+			Stop:        step.Stop,
+			Steps:       []allureStep{},
+			Attachments: []allureAttachment{},
+		})
+	}
+
+	for _, exp := range trace.skippedExps() {
+		step.Steps = append(step.Steps, allureStep{
+			Name:          exp,
+			Status:        allureStatusSkipped,
+			StatusDetails: &allureStatusDetail{Message: trace.ExpSkipped[exp]},
+			Stage:         allureStageFinished,
+			Start:         step.Start,
+			Stop:          step.Stop,
+			Steps:         []allureStep{},
+			Attachments:   []allureAttachment{},
+		})
+	}
+
+	if trace.HasError() {
+		step.Status = allureStatusFailed
+		if trace.Terminated() {
+			step.Status = allureStatusBroken
+		}
+		step.StatusDetails = &allureStatusDetail{Message: trace.ErrorCause.Error()}
+	}
+
+	if trace.RequestDump != "" {
+		step.Attachments = append(step.Attachments, r.writeAttachment("Request", trace.RequestDump))
+	}
+
+	if trace.ResponseDump != "" {
+		step.Attachments = append(step.Attachments, r.writeAttachment("Response", trace.ResponseDump))
+	}
+
+	return step
+}
+
+func sortedStepNames(expectations map[string]bool) []string {
+	names := make([]string, 0, len(expectations))
+	for exp := range expectations {
+		names = append(names, exp)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (r *AllureReporter) writeAttachment(name string, content string) allureAttachment {
+	source := newUUID() + "-attachment.txt"
+
+	err := ioutil.WriteFile(filepath.Join(r.OutPath, source), []byte(content), 0666)
+	if err != nil {
+		panic(err)
+	}
+
+	return allureAttachment{Name: name, Source: source, Type: "text/plain"}
+}
+
+func (r *AllureReporter) writeResult(res allureResult) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		panic(err)
+	}
+
+	fp := filepath.Join(r.OutPath, res.UUID+"-result.json")
+	err = ioutil.WriteFile(fp, data, 0666)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Flush does nothing since results are written on Report
+func (r *AllureReporter) Flush() {
+
+}
+
+// NewAllureReporter returns reporter that writes Allure results into provided directory
+func NewAllureReporter(outdir string) Reporter {
+	return &AllureReporter{OutPath: outdir}
+}
+
+func toMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
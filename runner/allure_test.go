@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAllureReporterWritesRequiredFields(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "allure")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	results := []TestResult{
+		{
+			Suite: TestSuite{Name: "users", Dir: "api"},
+			Case:  TestCase{Name: "Create user"},
+			Traces: []*CallTrace{
+				{
+					RequestMethod: "POST",
+					RequestURL:    "http://example.com/users",
+					RequestDump:   "POST http://example.com/users HTTP/1.1",
+					ResponseDump:  "500 Internal Server Error",
+					ExpDesc:       map[string]bool{"Unexpected Status Code. Expected: 201, Actual: 500": true},
+					ErrorCause:    errors.New("Unexpected Status Code. Expected: 201, Actual: 500"),
+					ExecFrame:     TimeFrame{Start: now, End: now.Add(time.Second)},
+				},
+			},
+			ExecFrame: TimeFrame{Start: now, End: now.Add(time.Second)},
+		},
+	}
+
+	reporter := NewAllureReporter(dir)
+	reporter.Init()
+
+	// when
+	reporter.Report(results)
+	reporter.Flush()
+
+	// then
+	files, _ := filepath.Glob(filepath.Join(dir, "*-result.json"))
+	if len(files) != 1 {
+		t.Fatalf("Expected exactly one result file, found: %v", files)
+	}
+
+	data, err := ioutil.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var res map[string]interface{}
+	if err := json.Unmarshal(data, &res); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, field := range []string{"uuid", "name", "status"} {
+		if v, ok := res[field].(string); !ok || v == "" {
+			t.Errorf("Required field '%s' is missing in %s", field, data)
+		}
+	}
+
+	if res["status"] != allureStatusFailed {
+		t.Errorf("Unexpected status: %v", res["status"])
+	}
+
+	attachments, _ := filepath.Glob(filepath.Join(dir, "*-attachment.txt"))
+	if len(attachments) != 2 {
+		t.Errorf("Expected request and response attachments, found: %v", attachments)
+	}
+}
+
+func TestAllureReporterSkippedCase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "allure")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := &AllureReporter{OutPath: dir}
+
+	res := reporter.toAllure(TestResult{
+		Suite:      TestSuite{Name: "users"},
+		Case:       TestCase{Name: "Delete user"},
+		Skipped:    true,
+		SkippedMsg: "not implemented yet",
+	})
+
+	if res.Status != allureStatusSkipped {
+		t.Errorf("Unexpected status: %s", res.Status)
+	}
+
+	if res.StatusDetails == nil || res.StatusDetails.Message != "not implemented yet" {
+		t.Errorf("Skip reason is not reported: %+v", res.StatusDetails)
+	}
+}
+
+func TestAllureReporterStepsOrder(t *testing.T) {
+	// given
+	trace := &CallTrace{
+		ExpDesc:    map[string]bool{"c": false, "a": false, "d": true, "b": false},
+		ExpSkipped: map[string]string{"f": "env", "e": "env"},
+	}
+
+	for i := 0; i < 10; i++ {
+		// when
+		step := (&AllureReporter{}).toStep(trace)
+
+		// then
+		var names []string
+		for _, s := range step.Steps {
+			names = append(names, s.Name)
+		}
+
+		if strings.Join(names, "") != "abcdef" {
+			t.Fatalf("Expected sorted steps, got %v", names)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package runner
+
+import (
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/go-ntlmssp"
+)
+
+const (
+	authDigest = "digest"
+	authNTLM   = "ntlm"
+)
+
+// Auth describes credentials of challenge-response authentication performed transparently
+type Auth struct {
+	// 'digest' (RFC 7616) or 'ntlm' (NTLMv2, username could be prefixed with domain as 'DOMAIN\user')
+	Type     string `json:"type"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func (a *Auth) populateWith(tmplCtx *TemplateContext) *Auth {
+	if a == nil {
+		return nil
+	}
+
+	return &Auth{Type: a.Type, Username: tmplCtx.ApplyTo(a.Username), Password: tmplCtx.ApplyTo(a.Password)}
+}
+
+// doWithAuth sends request and answers authentication challenge of '401 Unauthorized' response with the following requests
+func doWithAuth(client *http.Client, req *http.Request, auth *Auth) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil || auth == nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	switch auth.Type {
+	case authDigest:
+		return answerDigest(client, req, resp, auth)
+	case authNTLM:
+		return answerNTLM(client, req, resp, auth)
+	}
+
+	return resp, nil
+}
+
+// answerDigest repeats request with 'Authorization' answering Digest challenge of the response
+func answerDigest(client *http.Client, req *http.Request, resp *http.Response, auth *Auth) (*http.Response, error) {
+	challenge, ok := digestChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+
+	authorization, err := digestAuthorization(challenge, req.Method, req.URL.RequestURI(), auth)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	discardBody(resp)
+	return resendWithAuthorization(client, req, authorization)
+}
+
+// answerNTLM performs NTLM handshake: negotiate message is sent in reply to 'NTLM' (or 'Negotiate') challenge,
+// then challenge message of the server is answered with authenticate message.
+// Handshake requests are sent over the same keep-alive connection as server authenticates the connection.
+func answerNTLM(client *http.Client, req *http.Request, resp *http.Response, auth *Auth) (*http.Response, error) {
+	scheme, _, ok := ntlmChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok {
+		return resp, nil
+	}
+
+	user, domain, domainNeeded := ntlmssp.GetDomain(auth.Username)
+	negotiate, err := ntlmssp.NewNegotiateMessage(domain, "")
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	discardBody(resp)
+	resp, err = resendWithAuthorization(client, req, scheme+" "+base64.StdEncoding.EncodeToString(negotiate))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	_, challenge, ok := ntlmChallenge(resp.Header.Values("WWW-Authenticate"))
+	if !ok || len(challenge) == 0 {
+		return resp, nil
+	}
+
+	authenticate, err := ntlmssp.ProcessChallenge(challenge, user, auth.Password, domainNeeded)
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("Can't answer NTLM challenge: %s", err)
+	}
+
+	discardBody(resp)
+	return resendWithAuthorization(client, req, scheme+" "+base64.StdEncoding.EncodeToString(authenticate))
+}
+
+// discardBody reads the rest of response body, so connection is reused by the next request
+func discardBody(resp *http.Response) {
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// resendWithAuthorization sends copy of the request with 'Authorization' header
+func resendWithAuthorization(client *http.Client, req *http.Request, authorization string) (*http.Response, error) {
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		var err error
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", authorization)
+
+	return client.Do(retry)
+}
+
+// ntlmChallenge finds NTLM or Negotiate challenge among 'WWW-Authenticate' values and decodes its message, if any
+func ntlmChallenge(values []string) (string, []byte, bool) {
+	for _, value := range values {
+		fields := strings.Fields(value)
+		if len(fields) == 0 || (!strings.EqualFold(fields[0], "NTLM") && !strings.EqualFold(fields[0], "Negotiate")) {
+			continue
+		}
+
+		if len(fields) == 1 {
+			return fields[0], nil, true
+		}
+
+		message, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			continue
+		}
+
+		return fields[0], message, true
+	}
+
+	return "", nil, false
+}
+
+// digestChallenge finds Digest challenge among 'WWW-Authenticate' values and parses its parameters
+func digestChallenge(values []string) (map[string]string, bool) {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if len(value) < 7 || !strings.EqualFold(value[:7], "Digest ") {
+			continue
+		}
+
+		return parseAuthParams(value[7:]), true
+	}
+
+	return nil, false
+}
+
+// parseAuthParams parses comma separated 'name=value' pairs, values could be quoted
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+
+	for len(s) > 0 {
+		s = strings.TrimLeft(s, " \t,")
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 {
+			break
+		}
+
+		name := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = strings.TrimLeft(s[eq+1:], " \t")
+
+		var value strings.Builder
+		if strings.HasPrefix(s, `"`) {
+			i := 1
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				value.WriteByte(s[i])
+			}
+			if i < len(s) {
+				// closing quote
+				i++
+			}
+			s = s[i:]
+		} else {
+			end := strings.IndexByte(s, ',')
+			if end < 0 {
+				end = len(s)
+			}
+			value.WriteString(strings.TrimSpace(s[:end]))
+			s = s[end:]
+		}
+
+		params[name] = value.String()
+	}
+
+	return params
+}
+
+// digestAuthorization builds 'Authorization' header value answering the challenge
+func digestAuthorization(challenge map[string]string, method, uri string, auth *Auth) (string, error) {
+	algorithm := challenge["algorithm"]
+	if algorithm == "" {
+		algorithm = "MD5"
+	}
+
+	session := strings.HasSuffix(strings.ToUpper(algorithm), "-SESS")
+
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "MD5":
+		newHash = md5.New
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		return "", fmt.Errorf("Unsupported Digest algorithm '%s'", algorithm)
+	}
+
+	h := func(parts ...string) string {
+		digest := newHash()
+		io.WriteString(digest, strings.Join(parts, ":"))
+		return hex.EncodeToString(digest.Sum(nil))
+	}
+
+	nonce := challenge["nonce"]
+	cnonce := newCNonce()
+	nc := "00000001"
+
+	ha1 := h(auth.Username, challenge["realm"], auth.Password)
+	if session {
+		ha1 = h(ha1, nonce, cnonce)
+	}
+	ha2 := h(method, uri)
+
+	qop := ""
+	for _, option := range strings.Split(challenge["qop"], ",") {
+		if strings.TrimSpace(option) == "auth" {
+			qop = "auth"
+		}
+	}
+
+	var response string
+	if qop != "" {
+		response = h(ha1, nonce, nc, cnonce, qop, ha2)
+	} else {
+		response = h(ha1, nonce, ha2)
+	}
+
+	header := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", algorithm=%s, response="%s"`,
+		auth.Username, challenge["realm"], nonce, uri, algorithm, response)
+	if qop != "" {
+		header += fmt.Sprintf(`, qop=%s, nc=%s, cnonce="%s"`, qop, nc, cnonce)
+	}
+	if opaque, ok := challenge["opaque"]; ok {
+		header += fmt.Sprintf(`, opaque="%s"`, opaque)
+	}
+
+	return header, nil
+}
+
+func newCNonce() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/md4"
+)
+
+func md5Hex(parts ...string) string {
+	digest := md5.New()
+	io.WriteString(digest, strings.Join(parts, ":"))
+	return hex.EncodeToString(digest.Sum(nil))
+}
+
+func TestDigestAuth(t *testing.T) {
+	// given
+	const realm, nonce = "test@example.com", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(req.Body)
+
+		authorization := req.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "Digest ") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="`+realm+`", qop="auth,auth-int", nonce="`+nonce+`", opaque="5ccc"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		params := parseAuthParams(authorization[7:])
+		ha1 := md5Hex("Mufasa", realm, "Circle of Life")
+		ha2 := md5Hex(req.Method, req.URL.RequestURI())
+		expected := md5Hex(ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2)
+
+		if params["response"] != expected || params["opaque"] != "5ccc" || string(body) != `{"a":1}` {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+	}))
+	defer server.Close()
+
+	c := Call{
+		On: On{
+			Method: "POST",
+			URL:    server.URL + "/dir/index.html?q=1",
+			Body:   json.RawMessage(`{"a":1}`),
+			Auth:   &Auth{Type: authDigest, Username: "{user}", Password: "Circle of Life"},
+		},
+		Expect: Expect{StatusCode: 200},
+	}
+
+	vars := NewVars("")
+	vars.Add("user", "Mufasa")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Error(trace.ErrorCause)
+	}
+	if attempts != 2 {
+		t.Errorf("Expected 2 requests, got %d", attempts)
+	}
+}
+
+func TestDigestAuthWithoutChallenge(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL, Auth: &Auth{Type: authDigest, Username: "user"}},
+		Expect: Expect{StatusCode: 401},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Error(trace.ErrorCause)
+	}
+}
+
+// utf16le encodes ASCII string as NTLM unicode string
+func utf16le(s string) []byte {
+	b := make([]byte, 0, 2*len(s))
+	for _, c := range []byte(s) {
+		b = append(b, c, 0)
+	}
+	return b
+}
+
+func hmacMD5(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(md5.New, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+// ntlmTestChallenge is CHALLENGE message of 'CORP' domain with empty target info
+func ntlmTestChallenge(serverChallenge []byte) []byte {
+	const unicode, ntlm, targetInfo = 0x1, 0x200, 0x800000
+
+	target := utf16le("CORP")
+	m := append([]byte("NTLMSSP\x00"), 2, 0, 0, 0)
+	m = append(m, byte(len(target)), 0, byte(len(target)), 0, 48, 0, 0, 0)
+	m = append(m, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(m[len(m)-4:], unicode|ntlm|targetInfo)
+	m = append(m, serverChallenge...)
+	m = append(m, make([]byte, 8)...)
+	m = append(m, 4, 0, 4, 0, byte(48+len(target)), 0, 0, 0)
+	m = append(m, target...)
+	// MsvAvEOL
+	return append(m, 0, 0, 0, 0)
+}
+
+// ntlmField reads security buffer of AUTHENTICATE message at the offset
+func ntlmField(m []byte, offset int) []byte {
+	size := int(binary.LittleEndian.Uint16(m[offset:]))
+	start := int(binary.LittleEndian.Uint32(m[offset+4:]))
+	return m[start : start+size]
+}
+
+func TestNTLMAuth(t *testing.T) {
+	// given
+	serverChallenge := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	var steps []string
+	connections := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		connections[req.RemoteAddr] = true
+
+		authorization := req.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "NTLM ") {
+			steps = append(steps, "anonymous")
+			w.Header().Set("WWW-Authenticate", "NTLM")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		message, err := base64.StdEncoding.DecodeString(authorization[5:])
+		if err != nil || !bytes.HasPrefix(message, []byte("NTLMSSP\x00")) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		switch message[8] {
+		case 1:
+			steps = append(steps, "negotiate")
+			w.Header().Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(ntlmTestChallenge(serverChallenge)))
+			w.WriteHeader(http.StatusUnauthorized)
+		case 3:
+			steps = append(steps, "authenticate")
+
+			// NTLMv2 response is HMAC of server challenge and client blob keyed with hash of password, user and domain
+			md4Hash := md4.New()
+			md4Hash.Write(utf16le("Secret1"))
+			ntlmV2Hash := hmacMD5(md4Hash.Sum(nil), utf16le("ALICE"+"CORP"))
+
+			response := ntlmField(message, 20)
+			if !bytes.Equal(response[:16], hmacMD5(ntlmV2Hash, serverChallenge, response[16:])) ||
+				!bytes.Equal(ntlmField(message, 28), utf16le("CORP")) || !bytes.Equal(ntlmField(message, 36), utf16le("alice")) {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			if string(body) != `{"a":1}` {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+	}))
+	defer server.Close()
+
+	c := Call{
+		On: On{
+			Method: "POST",
+			URL:    server.URL + "/reports",
+			Body:   json.RawMessage(`{"a":1}`),
+			Auth:   &Auth{Type: authNTLM, Username: `{domain}\alice`, Password: "Secret1"},
+		},
+		Expect: Expect{StatusCode: 200},
+	}
+
+	vars := NewVars("")
+	vars.Add("domain", "CORP")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Error(trace.ErrorCause)
+	}
+
+	if strings.Join(steps, ",") != "anonymous,negotiate,authenticate" {
+		t.Errorf("Unexpected handshake: %v", steps)
+	}
+
+	if len(connections) != 1 {
+		t.Errorf("Expected handshake over single connection, got %v", connections)
+	}
+}
+
+func TestParseAuthParams(t *testing.T) {
+	params := parseAuthParams(`realm="a, \"b\"", qop="auth,auth-int", algorithm=MD5, nonce="xyz"`)
+
+	expected := map[string]string{"realm": `a, "b"`, "qop": "auth,auth-int", "algorithm": "MD5", "nonce": "xyz"}
+	for k, v := range expected {
+		if params[k] != v {
+			t.Errorf("%s: expected %q, got %q", k, v, params[k])
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// BodyFormat serializes structured request body into wire format and parses responses of its media types
+type BodyFormat struct {
+	// 'Content-Type' of serialized request body unless the request sets it
+	ContentType string
+	// responses with these media types are parsed with the format
+	MediaTypes []string
+	// serializes body decoded from JSON, numbers are json.Number
+	Marshal func(v interface{}) ([]byte, error)
+	// parses body into the same values as JSON is parsed: maps, slices, strings, float64 numbers, booleans and nil
+	Unmarshal func(data []byte) (interface{}, error)
+}
+
+// registered body formats by name
+var bodyFormats = make(map[string]BodyFormat)
+
+// RegisterBodyFormat makes format available by name for 'on.format' of calls.
+// It panics if the name is already registered.
+func RegisterBodyFormat(name string, format BodyFormat) {
+	if _, ok := bodyFormats[name]; ok {
+		panic("body format '" + name + "' is already registered")
+	}
+
+	bodyFormats[name] = format
+}
+
+// BodyFormatNames returns sorted names of registered body formats
+func BodyFormatNames() []string {
+	names := make([]string, 0, len(bodyFormats))
+	for name := range bodyFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// encodeBody serializes JSON request body into the named format
+func encodeBody(name string, body string) (string, BodyFormat, error) {
+	format, ok := bodyFormats[name]
+	if !ok {
+		return "", format, fmt.Errorf("Unknown body format '%s'. Available formats: %s", name, strings.Join(BodyFormatNames(), ", "))
+	}
+
+	data, err := marshalBody(name, format, body)
+	return data, format, err
+}
+
+// marshalBody serializes JSON request body with the format, numbers are decoded as json.Number
+func marshalBody(name string, format BodyFormat, body string) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("Request body is not a valid JSON to serialize as %s: %s", name, err)
+	}
+
+	data, err := format.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("Can't serialize request body as %s: %s", name, err)
+	}
+
+	return string(data), nil
+}
+
+// responseFormat finds format parsing responses of the media type
+func responseFormat(mediaType string) (BodyFormat, bool) {
+	for _, name := range BodyFormatNames() {
+		for _, t := range bodyFormats[name].MediaTypes {
+			if t == mediaType {
+				return bodyFormats[name], true
+			}
+		}
+	}
+
+	return BodyFormat{}, false
+}
+
+// integer returns value of JSON number as integer if it has no fractional part
+func integer(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		if i, err := n.Int64(); err == nil {
+			return i, true
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return 0, false
+		}
+		return integer(f)
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), true
+		}
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+// float returns value of JSON number
+func float(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+// wireValue converts JSON numbers of request body into integers or floats, so formats write them as numbers
+func wireValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, ok := integer(value); ok {
+			return i
+		}
+		f, _ := float(value)
+		return f
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			m[k] = wireValue(item)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, item := range value {
+			s[i] = wireValue(item)
+		}
+		return s
+	}
+
+	return v
+}
+
+// jsonValue converts value decoded by format library into the same values as JSON is parsed:
+// numbers become float64, map keys become strings, binary data becomes string, times are formatted as RFC 3339 and tags are ignored
+func jsonValue(v interface{}) (interface{}, error) {
+	switch value := v.(type) {
+	case nil, bool, string, float64:
+		return value, nil
+	case int:
+		return float64(value), nil
+	case int8:
+		return float64(value), nil
+	case int16:
+		return float64(value), nil
+	case int32:
+		return float64(value), nil
+	case int64:
+		return float64(value), nil
+	case uint:
+		return float64(value), nil
+	case uint8:
+		return float64(value), nil
+	case uint16:
+		return float64(value), nil
+	case uint32:
+		return float64(value), nil
+	case uint64:
+		return float64(value), nil
+	case float32:
+		return float64(value), nil
+	case []byte:
+		return string(value), nil
+	case big.Int:
+		f, _ := new(big.Float).SetInt(&value).Float64()
+		return f, nil
+	case time.Time:
+		return value.Format(time.RFC3339Nano), nil
+	case cbor.Tag:
+		return jsonValue(value.Content)
+	case []interface{}:
+		s := make([]interface{}, len(value))
+		for i, item := range value {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			s[i] = converted
+		}
+		return s, nil
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = converted
+		}
+		return m, nil
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(value))
+		for k, item := range value {
+			key, err := jsonValue(k)
+			if err != nil {
+				return nil, err
+			}
+			converted, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(key)] = converted
+		}
+		return m, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %v (%T)", v, v)
+}
+
+// sortedKeys returns keys of the map in order, so serialized bodies are stable
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func init() {
+	RegisterBodyFormat("yaml", BodyFormat{
+		ContentType: "application/yaml",
+		MediaTypes:  []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"},
+		Marshal:     marshalYAML,
+		Unmarshal:   unmarshalYAML,
+	})
+
+	RegisterBodyFormat("msgpack", BodyFormat{
+		ContentType: "application/msgpack",
+		MediaTypes:  []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		Marshal:     marshalMsgPack,
+		Unmarshal:   unmarshalMsgPack,
+	})
+
+	RegisterBodyFormat("cbor", BodyFormat{
+		ContentType: "application/cbor",
+		MediaTypes:  []string{"application/cbor"},
+		Marshal:     marshalCBOR,
+		Unmarshal:   unmarshalCBOR,
+	})
+}
@@ -0,0 +1,249 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// parsedJSON parses JSON the same way as response bodies are parsed
+func parsedJSON(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+const formatSample = `{"name": "orders", "replicas": 3, "ratio": 0.5, "enabled": true, "owner": null,
+	"tags": ["api", "v2"], "empty": {}, "none": [], "limits": {"cpu": "500m", "memory": "1Gi"},
+	"ports": [{"name": "http", "port": 8080}, {"name": "grpc", "port": -1}], "matrix": [[1, 2], [3]],
+	"quoted": ["yes", "123", "a: b", "# not a comment", "", "line\nbreak"]}`
+
+func TestBodyFormatsRoundTrip(t *testing.T) {
+	for _, name := range BodyFormatNames() {
+		t.Run(name, func(t *testing.T) {
+			encoded, format, err := encodeBody(name, formatSample)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			decoded, err := format.Unmarshal([]byte(encoded))
+			if err != nil {
+				t.Fatalf("%s\n%s", err, encoded)
+			}
+
+			if expected := parsedJSON(t, formatSample); !reflect.DeepEqual(decoded, expected) {
+				t.Errorf("Unexpected round trip.\nExpected: %v\nActual:   %v\nEncoded:\n%s", expected, decoded, encoded)
+			}
+		})
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	encoded, _, err := encodeBody("yaml", `{"service": "orders", "replicas": 3, "ports": [{"name": "http", "port": 8080}], "tags": ["api", "true"]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `ports:
+  - name: http
+    port: 8080
+replicas: 3
+service: orders
+tags:
+  - api
+  - "true"
+`
+	if encoded != expected {
+		t.Errorf("Unexpected YAML.\nExpected:\n%s\nActual:\n%s", expected, encoded)
+	}
+}
+
+func TestUnmarshalYAML(t *testing.T) {
+	yaml := `---
+# service config
+service: orders  # name
+replicas: 3
+endpoints:
+- path: /orders
+  methods: [GET, POST]
+- path: '/orders/{id}'
+  auth: {scheme: bearer, scopes: ["read", "write"]}
+description: |
+  Orders API
+    v2
+summary: >-
+  folded
+  text
+empty:
+`
+
+	expected := parsedJSON(t, `{"service": "orders", "replicas": 3, "empty": null,
+		"endpoints": [{"path": "/orders", "methods": ["GET", "POST"]}, {"path": "/orders/{id}", "auth": {"scheme": "bearer", "scopes": ["read", "write"]}}],
+		"description": "Orders API\n  v2\n", "summary": "folded text"}`)
+
+	actual, err := unmarshalYAML([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected YAML value.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestUnmarshalYAMLAnchors(t *testing.T) {
+	yaml := `defaults: &defaults
+  timeout: 5
+  retries: 2
+orders:
+  <<: *defaults
+  timeout: 10
+hosts: &hosts [a.example.com, b.example.com]
+mirrors: *hosts
+`
+
+	expected := parsedJSON(t, `{"defaults": {"timeout": 5, "retries": 2}, "orders": {"timeout": 10, "retries": 2},
+		"hosts": ["a.example.com", "b.example.com"], "mirrors": ["a.example.com", "b.example.com"]}`)
+
+	actual, err := unmarshalYAML([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected YAML value.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestUnmarshalYAMLMultipleDocuments(t *testing.T) {
+	yaml := `---
+kind: Service
+name: orders
+---
+kind: Deployment
+replicas: 3
+...
+`
+
+	expected := parsedJSON(t, `[{"kind": "Service", "name": "orders"}, {"kind": "Deployment", "replicas": 3}]`)
+
+	actual, err := unmarshalYAML([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected YAML value.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestUnmarshalYAMLFlowStyle(t *testing.T) {
+	yaml := `{service: orders, ports: [{name: http, port: 8080}, {name: grpc, port: 9090}],
+  labels: {tier: "backend", "app.kubernetes.io/name": orders}, enabled: yes, ids: [0x1F, 1e3, ~]}`
+
+	expected := parsedJSON(t, `{"service": "orders", "ports": [{"name": "http", "port": 8080}, {"name": "grpc", "port": 9090}],
+		"labels": {"tier": "backend", "app.kubernetes.io/name": "orders"}, "enabled": "yes", "ids": [31, 1000, null]}`)
+
+	actual, err := unmarshalYAML([]byte(yaml))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected YAML value.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+}
+
+func TestUnmarshalYAMLInvalid(t *testing.T) {
+	for _, yaml := range []string{"key: [unclosed", "a: *undefined", "a: 1\n b: 2"} {
+		if _, err := unmarshalYAML([]byte(yaml)); err == nil || !strings.HasPrefix(err.Error(), "invalid YAML: ") {
+			t.Errorf("Expected %q to fail, got %v", yaml, err)
+		}
+	}
+}
+
+func TestMarshalMsgPack(t *testing.T) {
+	encoded, _, err := encodeBody("msgpack", `{"id": 1, "n": -200, "ok": true, "r": 0.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []byte{0x84, 0xa2, 'i', 'd', 0x01, 0xa1, 'n', 0xd1, 0xff, 0x38, 0xa2, 'o', 'k', 0xc3,
+		0xa1, 'r', 0xcb, 0x3f, 0xe0, 0, 0, 0, 0, 0, 0}
+	if !bytes.Equal([]byte(encoded), expected) {
+		t.Errorf("Unexpected MessagePack.\nExpected: % x\nActual:   % x", expected, []byte(encoded))
+	}
+}
+
+func TestUnmarshalCBOR(t *testing.T) {
+	// {"a": [1, -2, 1.5 (half float)], "b": indefinite length "xy"} with tag 32 (URI) on "b" value
+	data := []byte{0xa2, 0x61, 'a', 0x83, 0x01, 0x21, 0xf9, 0x3e, 0x00, 0x61, 'b', 0xd8, 0x20, 0x7f, 0x61, 'x', 0x61, 'y', 0xff}
+
+	actual, err := unmarshalCBOR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := parsedJSON(t, `{"a": [1, -2, 1.5], "b": "xy"}`); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected CBOR value.\nExpected: %v\nActual:   %v", expected, actual)
+	}
+
+	if _, err = unmarshalCBOR(data[:5]); err == nil || !strings.HasPrefix(err.Error(), "invalid CBOR: ") {
+		t.Errorf("Expected truncated CBOR to fail, got %v", err)
+	}
+}
+
+func TestEncodeBodyUnknownFormat(t *testing.T) {
+	_, _, err := encodeBody("toml", `{}`)
+
+	if err == nil || err.Error() != "Unknown body format 'toml'. Available formats: cbor, msgpack, yaml" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestCallYAMLBodyAndMsgPackResponse(t *testing.T) {
+	// given
+	var contentType, received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		contentType, received = req.Header.Get("Content-Type"), string(body)
+
+		// {"id": 7, "status": "created"}
+		w.Header().Set("Content-Type", "application/msgpack")
+		w.Write([]byte{0x82, 0xa2, 'i', 'd', 0x07, 0xa6, 's', 't', 'a', 't', 'u', 's', 0xa7, 'c', 'r', 'e', 'a', 't', 'e', 'd'})
+	}))
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "POST", URL: server.URL, Format: "yaml", Body: []byte(`{"name": "{name}", "items": [{"sku": "A-1", "qty": 2}]}`)},
+		Expect: Expect{StatusCode: 200, BPath: map[string]interface{}{"id": 7.0, "status": "created"}},
+	}
+
+	vars := NewVars("")
+	vars.Add("name", "order")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if contentType != "application/yaml" {
+		t.Errorf("Unexpected Content-Type: %s", contentType)
+	}
+
+	expected := "items:\n  - qty: 2\n    sku: A-1\nname: order\n"
+	if received != expected {
+		t.Errorf("Unexpected request body.\nExpected:\n%s\nActual:\n%s", expected, received)
+	}
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxBodyLog is max size of request and response body written into call trace
+const DefaultMaxBodyLog = 8 * 1024
+
+// maxBodyLog returns limit of logged body size, zero means no limit
+func maxBodyLog() int {
+	if options.MaxBodyLog < 0 {
+		return 0
+	}
+
+	if options.MaxBodyLog == 0 {
+		return DefaultMaxBodyLog
+	}
+
+	return options.MaxBodyLog
+}
+
+// logBody prepares body to be written into call trace: binary content is replaced with its size, long text is truncated
+func logBody(body []byte, contentType string) string {
+	if isBinaryBody(body, contentType) {
+		return fmt.Sprintf("<binary %d bytes>", len(body))
+	}
+
+	return truncateBody(string(body), maxBodyLog())
+}
+
+// isBinaryBody checks body is not text either by content type or by content
+func isBinaryBody(body []byte, contentType string) bool {
+	if len(body) == 0 {
+		return false
+	}
+
+	if contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && !isTextMediaType(mediaType) {
+			return true
+		}
+	}
+
+	return !utf8.Valid(body)
+}
+
+func isTextMediaType(mediaType string) bool {
+	switch mediaType {
+	case "application/x-www-form-urlencoded", "application/javascript", "application/graphql":
+		return true
+	}
+
+	return strings.HasPrefix(mediaType, "text/") || isJSONMediaType(mediaType) || isXMLMediaType(mediaType)
+}
+
+// truncateBody cuts text longer than limit (in bytes) keeping UTF-8 characters whole, zero limit means no truncation
+func truncateBody(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+
+	return fmt.Sprintf("%s\n... <truncated, %d of %d bytes shown>", text[:cut], cut, len(text))
+}
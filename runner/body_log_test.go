@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogBodyTruncated(t *testing.T) {
+	defer func(opts Options) { options = opts }(options)
+	options.MaxBodyLog = 10
+
+	got := logBody([]byte(`{"name": "ääääää"}`), "application/json")
+
+	expected := "{\"name\": \"\n... <truncated, 10 of 24 bytes shown>"
+	if got != expected {
+		t.Errorf("Unexpected body log. Expected: %q, Actual: %q", expected, got)
+	}
+
+	// multibyte character is not split
+	options.MaxBodyLog = 12
+	if got = logBody([]byte(`{"name": "ääääää"}`), "application/json"); !strings.HasPrefix(got, "{\"name\": \"ä\n") {
+		t.Errorf("Unexpected body log: %q", got)
+	}
+
+	options.MaxBodyLog = -1
+	if got = logBody([]byte(strings.Repeat("a", DefaultMaxBodyLog+1)), "text/plain"); len(got) != DefaultMaxBodyLog+1 {
+		t.Errorf("Expected body not to be truncated, got %d bytes", len(got))
+	}
+}
+
+func TestLogBodyBinary(t *testing.T) {
+	tests := []struct {
+		body        []byte
+		contentType string
+		expected    string
+	}{
+		{[]byte{0x89, 'P', 'N', 'G'}, "image/png", "<binary 4 bytes>"},
+		{[]byte("plain"), "application/octet-stream", "<binary 5 bytes>"},
+		{[]byte{'a', 0xff, 0xfe}, "text/plain", "<binary 3 bytes>"},
+		{[]byte{'a', 0xff}, "", "<binary 2 bytes>"},
+		{[]byte("a=1&b=2"), "application/x-www-form-urlencoded", "a=1&b=2"},
+		{[]byte(`{"a": 1}`), "application/problem+json; charset=utf-8", `{"a": 1}`},
+	}
+
+	for _, tt := range tests {
+		if got := logBody(tt.body, tt.contentType); got != tt.expected {
+			t.Errorf("Unexpected body log for %s. Expected: %s, Actual: %s", tt.contentType, tt.expected, got)
+		}
+	}
+}
+
+func TestResponseDumpBinary(t *testing.T) {
+	resp, _ := newResponse(&http.Response{
+		Status: "200 OK",
+		Header: map[string][]string{"Content-Type": {"application/pdf"}},
+	}, []byte("%PDF-1.4\x00\x01"))
+
+	dump := resp.ToString()
+
+	if !strings.HasSuffix(dump, "<binary 10 bytes>") {
+		t.Error("Expected binary body to be replaced in dump, got:", dump)
+	}
+}
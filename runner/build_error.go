@@ -0,0 +1,14 @@
+package runner
+
+// RequestBuildError is a failure to build request before sending it, e.g. invalid URL or unresolved template
+type RequestBuildError struct {
+	Err error
+}
+
+func (e *RequestBuildError) Error() string {
+	return "request could not be built: " + e.Err.Error()
+}
+
+func (e *RequestBuildError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestCallMalformedURLTemplate(t *testing.T) {
+	// given
+	c := Call{On: On{Method: "GET", URL: "http://[::1/users/{id}"}, Expect: Expect{StatusCode: 200}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	var buildErr *RequestBuildError
+	if !errors.As(trace.ErrorCause, &buildErr) {
+		t.Fatalf("Expected request build error, got %v", trace.ErrorCause)
+	}
+
+	if !trace.Terminated() {
+		t.Error("Expected call to be terminated rather than failed expectation")
+	}
+
+	results := []TestResult{{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "get user"}, Traces: []*CallTrace{trace}}}
+
+	writer := MockWriter{expectedWriting: "request could not be built: "}
+	color.Output = &writer
+	console := &ConsoleReporter{Writer: &writer, ioMutex: &sync.Mutex{}}
+	console.Report(results)
+
+	if !writer.passed() {
+		t.Errorf("Expected writing %s was not met in %s", writer.expectedWriting, writer.actualWriting)
+	}
+
+	dir, err := ioutil.TempDir("", "bozr-junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	junit := &JUnitXMLReporter{OutPath: dir}
+	junit.Report(results)
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := string(data)
+	for _, expected := range []string{`errors="1"`, `failures="0"`, `<error type="RequestBuildError" message="request could not be built: `} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %s in %s", expected, report)
+		}
+	}
+}
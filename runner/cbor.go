@@ -0,0 +1,30 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR maps are written with canonically sorted keys
+var cborEncMode, _ = cbor.EncOptions{Sort: cbor.SortCanonical}.EncMode()
+
+// marshalCBOR serializes JSON values into CBOR, integral numbers are written as integers
+func marshalCBOR(v interface{}) ([]byte, error) {
+	return cborEncMode.Marshal(wireValue(v))
+}
+
+// unmarshalCBOR parses CBOR into JSON values, tags are ignored and byte strings become strings
+func unmarshalCBOR(data []byte) (interface{}, error) {
+	var v interface{}
+	if err := cbor.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("invalid CBOR: %s", err)
+	}
+
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CBOR: %s", err)
+	}
+
+	return value, nil
+}
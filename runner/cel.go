@@ -0,0 +1,233 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/common/types/ref"
+	"google.golang.org/protobuf/proto"
+)
+
+// CELExpressions are expressions given either as a single string or as an array of strings
+type CELExpressions []string
+
+// UnmarshalJSON accepts a single expression or an array of them
+func (e *CELExpressions) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*e = CELExpressions{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+
+	*e = list
+	return nil
+}
+
+// CELExpectation validates the response with Common Expression Language expression, which must be true.
+// Expressions are type checked: 'status' and 'durationMs' are int, 'headers' is map(string, string) and 'body' is dyn,
+// numbers of JSON body are doubles.
+type CELExpectation struct {
+	Expression string
+	program    cel.Program
+}
+
+func (e CELExpectation) check(resp *Response) error {
+	value, _, err := e.program.Eval(celActivation(resp))
+	if err != nil {
+		return fmt.Errorf("CEL expression '%s' failed: %s", e.Expression, err)
+	}
+
+	if value != types.True {
+		return fmt.Errorf("CEL expression '%s' is %s", e.Expression, celFormat(value))
+	}
+
+	return nil
+}
+
+func (e CELExpectation) desc() string {
+	return fmt.Sprintf("CEL: %s", e.Expression)
+}
+
+// celActivation returns values of CEL variables for the response, names of headers are in lower case
+func celActivation(resp *Response) map[string]interface{} {
+	headers := make(map[string]string, len(resp.http.Header))
+	for name, values := range resp.http.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+
+	body, err := resp.Body()
+	if err != nil {
+		body = nil
+	}
+
+	return map[string]interface{}{
+		"status":     int64(resp.http.StatusCode),
+		"headers":    headers,
+		"body":       body,
+		"durationMs": resp.duration.Milliseconds(),
+	}
+}
+
+// celFormat describes result of expression which is not true, e.g. 'false' or '"open" of type string'
+func celFormat(value ref.Val) string {
+	if value.Type() == types.BoolType {
+		return fmt.Sprint(value.Value())
+	}
+
+	if data, err := json.Marshal(value.Value()); err == nil {
+		return fmt.Sprintf("%s of type %s, expected bool", data, value.Type().TypeName())
+	}
+
+	return fmt.Sprintf("%v of type %s, expected bool", value.Value(), value.Type().TypeName())
+}
+
+// placeholders of variables, e.g. 'durationMs < {loginMs} * 2', they are resolved before the check
+var celPlaceholderPattern = regexp.MustCompile(`\{[\w.-]+(:[\w.-]+)?\}`)
+
+var (
+	celEnvOnce sync.Once
+	celEnv     *cel.Env
+	celEnvErr  error
+)
+
+// celEnvironment declares variables available in expressions of 'expect.cel'
+func celEnvironment() (*cel.Env, error) {
+	celEnvOnce.Do(func() {
+		celEnv, celEnvErr = cel.NewEnv(cel.Declarations(
+			decls.NewVar("status", decls.Int),
+			decls.NewVar("headers", decls.NewMapType(decls.String, decls.String)),
+			decls.NewVar("body", decls.Dyn),
+			decls.NewVar("durationMs", decls.Int),
+		))
+	})
+
+	return celEnv, celEnvErr
+}
+
+// max number of cached programs, expressions with resolved placeholders differ from call to call,
+// so the cache is cleared once it is full
+const celProgramsLimit = 1000
+
+// compiled programs by expression
+var (
+	celProgramsMu sync.Mutex
+	celPrograms   = make(map[string]cel.Program)
+)
+
+// compileCEL parses and type checks the expression, it has to refer only known variables and be of bool type
+func compileCEL(expr string) (cel.Program, error) {
+	celProgramsMu.Lock()
+	cached, ok := celPrograms[expr]
+	celProgramsMu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	env, err := celEnvironment()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expr)
+	if issues != nil && issues.Err() != nil {
+		messages := make([]string, 0, len(issues.Errors()))
+		for _, e := range issues.Errors() {
+			messages = append(messages, fmt.Sprintf("%s at %d", e.Message, e.Location.Column()))
+		}
+		return nil, fmt.Errorf("Invalid CEL expression '%s': %s", expr, strings.Join(messages, "; "))
+	}
+
+	if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+		return nil, fmt.Errorf("Invalid CEL expression '%s': expected bool result, got %s", expr, cel.FormatType(ast.ResultType()))
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid CEL expression '%s': %s", expr, err)
+	}
+
+	celProgramsMu.Lock()
+	if len(celPrograms) >= celProgramsLimit {
+		celPrograms = make(map[string]cel.Program)
+	}
+	celPrograms[expr] = program
+	celProgramsMu.Unlock()
+
+	return program, nil
+}
+
+// validateCELExpressions compiles expressions of 'cel' expectations of the suite, including nested and environment specific ones.
+// Placeholders of variables are checked as numbers, since their values are known only when the call is made.
+func validateCELExpressions(suiteContent interface{}) error {
+	cases, _ := suiteContent.([]interface{})
+	for _, c := range cases {
+		testCase, _ := c.(map[string]interface{})
+		calls, _ := testCase["calls"].([]interface{})
+		for _, call := range calls {
+			callMap, _ := call.(map[string]interface{})
+			sections := []interface{}{callMap["expect"]}
+			if envs, ok := callMap["expectEnv"].(map[string]interface{}); ok {
+				for _, section := range envs {
+					sections = append(sections, section)
+				}
+			}
+
+			for _, section := range sections {
+				if err := validateCELSection(section); err != nil {
+					return fmt.Errorf("Test case '%v': %s", testCase["name"], err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateCELSection(section interface{}) error {
+	expect, ok := section.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var exprs []interface{}
+	switch cel := expect["cel"].(type) {
+	case string:
+		exprs = []interface{}{cel}
+	case []interface{}:
+		exprs = cel
+	}
+
+	for _, expr := range exprs {
+		if s, ok := expr.(string); ok {
+			if _, err := compileCEL(celPlaceholderPattern.ReplaceAllString(s, "0")); err != nil {
+				return err
+			}
+		}
+	}
+
+	nested := []interface{}{expect["not"]}
+	for _, key := range []string{"and", "or"} {
+		if sections, ok := expect[key].([]interface{}); ok {
+			nested = append(nested, sections...)
+		}
+	}
+
+	for _, section := range nested {
+		if err := validateCELSection(section); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
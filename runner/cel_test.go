@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+)
+
+func TestCELExpectation(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": [{"id": 1, "price": 10}, {"id": 2, "price": 5.5}], "status": "open"}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		expr string
+		fail string
+	}{
+		{"true", "status == 200 && body.items.size() > 0 && durationMs < 5000", ""},
+		{"headers and macros", "headers['content-type'].startsWith('application/json') && body.items.all(i, i.price > 0.0) && 2.0 in body.items.map(i, i.id)", ""},
+		{"false", "body.items.size() > 2", "CEL expression 'body.items.size() > 2' is false"},
+		{"not bool", "body.status", `CEL expression 'body.status' is "open" of type string, expected bool`},
+		{"evaluation error", "body.missing == 1.0", "CEL expression 'body.missing == 1.0' failed: no such key: missing"},
+		{"no overload", "body.items[0].price > 5", "CEL expression 'body.items[0].price > 5' failed: no such overload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Call{
+				On:     On{Method: "GET", URL: server.URL},
+				Expect: Expect{CEL: CELExpressions{tt.expr}},
+			}
+
+			// when
+			trace := call("", c, NewVars(""))
+
+			// then
+			if tt.fail == "" {
+				if trace.HasError() {
+					t.Fatal(trace.ErrorCause)
+				}
+				if _, ok := trace.ExpDesc["CEL: "+tt.expr]; !ok {
+					t.Errorf("Expected expression in descriptions, got %v", trace.ExpDesc)
+				}
+				return
+			}
+
+			if trace.ErrorCause == nil || !strings.HasPrefix(trace.ErrorCause.Error(), tt.fail) {
+				t.Fatalf("Expected failure %s, got %v", tt.fail, trace.ErrorCause)
+			}
+
+			if failed := trace.ExpDesc[trace.ErrorCause.Error()]; !failed {
+				t.Errorf("Expected failure in descriptions, got %v", trace.ExpDesc)
+			}
+		})
+	}
+}
+
+func TestCELCompileErrorOnLoad(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"a.suite.json": `[{"name": "a", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"or": [{"cel": ["status == 200", "bdy.items.size() > 0"]}]}}]}]`,
+		"b.suite.json": `[{"name": "b", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"cel": "status == (200"}}]}]`,
+		"c.suite.json": `[{"name": "c", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"cel": "durationMs < {loginMs} * 2 && body.token != '{token}'"}}]}]`,
+		"d.suite.json": `[{"name": "d", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"cel": "status + 1"}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt)
+	if err == nil {
+		t.Fatal("Expected compile error")
+	}
+
+	for _, msg := range []string{"undeclared reference to 'bdy' (in container '') at 0", "Syntax error: missing ')' at '<EOF>' at 14", "expected bool result, got int"} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("Expected error %s, got %s", msg, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "c.suite.json") {
+		t.Errorf("Expected placeholders of variables to be accepted, got %s", err)
+	}
+}
+
+func TestCELEval(t *testing.T) {
+	vars := map[string]interface{}{
+		"status":     int64(201),
+		"headers":    map[string]string{"x-id": "abc-1"},
+		"body":       map[string]interface{}{"name": "Bozr", "tags": []interface{}{"a", "b"}, "nested": map[string]interface{}{"n": 3.0}},
+		"durationMs": int64(12),
+	}
+
+	tests := []string{
+		"status >= 200 && status < 300",
+		"-status + 1 * 2 % 3 == -199",
+		"body.name + '!' == 'Bozr!'",
+		`"a" in body.tags && !("c" in body.tags)`,
+		"size(body.tags) == 2 && body.name.size() == 4",
+		"has(body.nested.n) && !has(body.missing)",
+		"body.tags.filter(t, t != 'a') == ['b']",
+		"body.tags.exists_one(t, t == 'b')",
+		"headers['x-id'].matches('^[a-z]+-[0-9]$')",
+		"int('12') + 1 == 13 && double('0.5') + 12.0 == 12.5",
+		"(status == 201 ? 'created' : 'other') == 'created'",
+		"{'k': [1, 2]}.k[1] == 2",
+		"!(false && body.missing)",
+		"body.nested.n / 2.0 == 1.5",
+		"10 / 4 == 2 && 10.0 / 4.0 == 2.5",
+		"type(status) == int && type(body.nested.n) == double && type(body.name) == string && type(body.tags) == list",
+		"uint(durationMs) == 12u",
+	}
+
+	for _, expr := range tests {
+		program, err := compileCEL(expr)
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+
+		actual, _, err := program.Eval(vars)
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+
+		if actual != types.True {
+			t.Errorf("%s: expected true, got %v", expr, actual)
+		}
+	}
+}
+
+func TestCELProgramsLimit(t *testing.T) {
+	celPrograms = make(map[string]cel.Program)
+	defer func() { celPrograms = make(map[string]cel.Program) }()
+
+	for i := 0; i <= celProgramsLimit; i++ {
+		if _, err := compileCEL(fmt.Sprintf("status == %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(celPrograms) != 1 {
+		t.Errorf("Expected cache to be cleared once it is full, got %d programs", len(celPrograms))
+	}
+}
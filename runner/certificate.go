@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CertificateAssert describes expected details of the certificate served by the server
+type CertificateAssert struct {
+	// subject common name
+	CommonName string `json:"commonName"`
+	// DNS names and IP addresses all listed in Subject Alternative Names
+	SAN []string `json:"san"`
+	// issuer common name
+	Issuer string `json:"issuer"`
+	// minimal time left until expiration, e.g. '30d' or '12h'
+	ExpiresInMoreThan string `json:"expiresInMoreThan"`
+}
+
+// CertificateExpectation validates leaf certificate of TLS connection the response is received over
+type CertificateExpectation struct {
+	CertificateAssert
+	// minimal time left until expiration, parsed ExpiresInMoreThan
+	MinValidity time.Duration
+}
+
+func (e CertificateExpectation) check(resp *Response) error {
+	if resp.http.TLS == nil || len(resp.http.TLS.PeerCertificates) == 0 {
+		return errors.New("Response is not received over TLS, no server certificate")
+	}
+
+	cert := resp.http.TLS.PeerCertificates[0]
+
+	if e.CommonName != "" && cert.Subject.CommonName != e.CommonName {
+		return fmt.Errorf("Expected certificate common name '%s', actual '%s'", e.CommonName, cert.Subject.CommonName)
+	}
+
+	if e.Issuer != "" && cert.Issuer.CommonName != e.Issuer {
+		return fmt.Errorf("Expected certificate issuer '%s', actual '%s'", e.Issuer, cert.Issuer.CommonName)
+	}
+
+	san := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		san = append(san, ip.String())
+	}
+	for _, name := range e.SAN {
+		if !containsString(san, name) {
+			return fmt.Errorf("Expected certificate alternative name '%s', actual names: %s", name, strings.Join(san, ", "))
+		}
+	}
+
+	if e.MinValidity > 0 {
+		left := time.Until(cert.NotAfter)
+		if left < e.MinValidity {
+			return fmt.Errorf("Expected certificate to expire in more than %s, but it expires at %s (in %s)",
+				e.ExpiresInMoreThan, cert.NotAfter.UTC().Format(time.RFC3339), left.Round(time.Hour))
+		}
+	}
+
+	return nil
+}
+
+func (e CertificateExpectation) desc() string {
+	var checks []string
+	if e.CommonName != "" {
+		checks = append(checks, "common name "+e.CommonName)
+	}
+	if e.Issuer != "" {
+		checks = append(checks, "issuer "+e.Issuer)
+	}
+	if len(e.SAN) > 0 {
+		checks = append(checks, "alternative names "+strings.Join(e.SAN, ", "))
+	}
+	if e.ExpiresInMoreThan != "" {
+		checks = append(checks, "expires in more than "+e.ExpiresInMoreThan)
+	}
+
+	return "Server certificate: " + strings.Join(checks, "; ")
+}
+
+// parseValidity parses duration that could be set in days, e.g. '30d'
+func parseValidity(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, fmt.Errorf("Invalid duration '%s'", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	return time.ParseDuration(value)
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCertServer starts TLS server with self-signed certificate valid for specified period
+func newCertServer(t *testing.T, commonName string, validFor time.Duration) *httptest.Server {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		Issuer:       pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName, "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(validFor),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	server.StartTLS()
+
+	return server
+}
+
+func TestCertificateExpectation(t *testing.T) {
+	// given
+	server := newCertServer(t, "api.example.com", 90*24*time.Hour)
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+	options.Insecure = true
+
+	c := Call{
+		On: On{Method: "GET", URL: server.URL},
+		Expect: Expect{Certificate: &CertificateAssert{
+			CommonName:        "api.example.com",
+			SAN:               []string{"localhost", "127.0.0.1"},
+			Issuer:            "api.example.com",
+			ExpiresInMoreThan: "30d",
+		}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if len(trace.PeerCertificates) != 1 || trace.PeerCertificates[0].Subject.CommonName != "api.example.com" {
+		t.Errorf("Expected server certificate in the trace, got %v", trace.PeerCertificates)
+	}
+}
+
+func TestCertificateNearExpiry(t *testing.T) {
+	// given
+	server := newCertServer(t, "api.example.com", 10*24*time.Hour)
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+	options.Insecure = true
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL},
+		Expect: Expect{Certificate: &CertificateAssert{CommonName: "api.example.com", ExpiresInMoreThan: "30d"}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "Expected certificate to expire in more than 30d") {
+		t.Errorf("Expected near expiry error, got %v", trace.ErrorCause)
+	}
+}
+
+func TestCertificateOverPlainHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{Certificate: &CertificateAssert{CommonName: "api.example.com"}}}
+
+	trace := call("", c, NewVars(""))
+
+	if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "not received over TLS") {
+		t.Errorf("Expected plain HTTP error, got %v", trace.ErrorCause)
+	}
+}
@@ -0,0 +1,138 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// keys of suite file that refer other files, paths are relative to the suite directory
+var suiteIncludeKeys = map[string]bool{
+	"bodyFile":       true,
+	"bodySchemaFile": true,
+	"specFile":       true,
+	"descriptorSet":  true,
+}
+
+// ChangedSuites selects suite files in the root directory affected by changed files: the suite file itself,
+// files it includes ('bodyFile', 'bodySchemaFile', 'specFile' of OpenAPI, 'descriptorSet' of protobuf and schemas referred from them with '$ref') or named matchers file shared by all suites.
+// Result contains absolute paths of selected suite files.
+func ChangedSuites(rootDir string, changedFiles []string) (map[string]bool, error) {
+	changed := make(map[string]bool, len(changedFiles))
+	for _, file := range changedFiles {
+		path, err := filepath.Abs(strings.TrimSpace(file))
+		if err != nil {
+			return nil, err
+		}
+		changed[path] = true
+	}
+
+	matchersPath, _ := filepath.Abs(filepath.Join(rootDir, MatchersFile))
+
+	source := &DirSuiteFileIterator{RootDir: rootDir, SuiteExt: SuiteExt, XSuiteExt: IgnoredSuiteExt}
+	source.init()
+
+	selected := make(map[string]bool)
+	for source.HasNext() {
+		sf := source.Next()
+
+		path, err := filepath.Abs(sf.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		deps, err := suiteIncludes(path)
+		if err != nil {
+			return nil, err
+		}
+		deps = append(deps, path, matchersPath)
+
+		for _, dep := range deps {
+			if changed[dep] {
+				selected[path] = true
+				break
+			}
+		}
+	}
+
+	return selected, nil
+}
+
+// suiteIncludes lists absolute paths of files included by the suite file, including transitive schema references
+func suiteIncludes(suitePath string) ([]string, error) {
+	var includes []string
+	visited := make(map[string]bool)
+
+	// included JSON schema could refer other files with '$ref'
+	var addInclude func(path string)
+	addInclude = func(path string) {
+		if visited[path] {
+			return
+		}
+		visited[path] = true
+		includes = append(includes, path)
+
+		// missing or invalid file is reported when suite is executed
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return
+		}
+
+		var doc interface{}
+		if json.Unmarshal(data, &doc) != nil {
+			return
+		}
+
+		for _, ref := range collectKeyValues(doc, map[string]bool{"$ref": true}) {
+			if strings.HasPrefix(ref, "#") || strings.Contains(ref, "://") {
+				continue
+			}
+
+			addInclude(filepath.Join(filepath.Dir(path), strings.SplitN(ref, "#", 2)[0]))
+		}
+	}
+
+	data, err := ioutil.ReadFile(suitePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var suite interface{}
+	if err := json.Unmarshal(data, &suite); err != nil {
+		return nil, err
+	}
+
+	for _, file := range collectKeyValues(suite, suiteIncludeKeys) {
+		path := file
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(suitePath), file)
+		}
+
+		addInclude(path)
+	}
+
+	return includes, nil
+}
+
+// collectKeyValues walks JSON document and returns string values of the keys
+func collectKeyValues(doc interface{}, keys map[string]bool) []string {
+	var values []string
+
+	switch typed := doc.(type) {
+	case map[string]interface{}:
+		for key, value := range typed {
+			if str, ok := value.(string); ok && keys[key] {
+				values = append(values, str)
+				continue
+			}
+			values = append(values, collectKeyValues(value, keys)...)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			values = append(values, collectKeyValues(item, keys)...)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestChangedSuites(t *testing.T) {
+	// given
+	dir := writeTestFiles(t, map[string]string{
+		"orders/create.suite.json":          `[{"calls": [{"on": {"method": "POST", "url": "/orders", "bodyFile": "order.json"}}]}]`,
+		"orders/order.json":                 `{"id": 1}`,
+		"users/get.suite.json":              `{"cases": [{"calls": [{"on": {"method": "GET", "url": "/users/1"}, "expect": {"bodySchemaFile": "schemas/user.json"}}]}]}`,
+		"users/schemas/user.json":           `{"properties": {"address": {"$ref": "common/address.json#/definitions/address"}, "id": {"$ref": "#/definitions/id"}}}`,
+		"users/schemas/common/address.json": `{"definitions": {"address": {"type": "string"}}}`,
+		"health.suite.json":                 `[{"calls": [{"on": {"method": "GET", "url": "/health"}}]}]`,
+		"shop/orders.suite.json":            `[{"calls": [{"on": {"method": "POST", "url": "/shop.v1.Orders/GetOrder", "protobuf": {"descriptorSet": "proto/shop.pb", "request": "shop.v1.GetOrderRequest"}}}]}]`,
+		"shop/proto/shop.pb":                "\n\nshop.proto",
+	})
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		changed  []string
+		expected []string
+	}{
+		{[]string{"orders/create.suite.json"}, []string{"orders/create.suite.json"}},
+		{[]string{"orders/order.json", "README.md"}, []string{"orders/create.suite.json"}},
+		{[]string{"users/schemas/common/address.json"}, []string{"users/get.suite.json"}},
+		{[]string{"shop/proto/shop.pb"}, []string{"shop/orders.suite.json"}},
+		{[]string{"matchers.json"}, []string{"health.suite.json", "orders/create.suite.json", "shop/orders.suite.json", "users/get.suite.json"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		changed := make([]string, 0, len(tt.changed))
+		for _, file := range tt.changed {
+			changed = append(changed, filepath.Join(dir, file))
+		}
+
+		// when
+		selected, err := ChangedSuites(dir, changed)
+
+		// then
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]string, 0, len(selected))
+		for path := range selected {
+			rel, _ := filepath.Rel(dir, path)
+			actual = append(actual, filepath.ToSlash(rel))
+		}
+		sort.Strings(actual)
+
+		if strings.Join(actual, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("Changed %v: expected suites %v, got %v", tt.changed, tt.expected, actual)
+		}
+	}
+}
+
+func TestRunOnlyChanged(t *testing.T) {
+	// given
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"users.suite.json":  `[{"name": "list users", "calls": [{"on": {"method": "GET", "url": "/users"}, "expect": {"statusCode": 200}}]}]`,
+		"health.suite.json": `[{"name": "health", "calls": [{"on": {"method": "GET", "url": "/health"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{
+		Path:         dir,
+		BaseURL:      server.URL,
+		ChangedFiles: []string{filepath.Join(dir, "users.suite.json")},
+	})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || len(paths) != 1 || paths[0] != "/users" {
+		t.Errorf("Expected only changed suite to run, got requests %v", paths)
+	}
+}
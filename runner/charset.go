@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// decodeCharset transcodes body into UTF-8 according to charset of the content type.
+// Body is returned as is if charset is absent or UTF-8 compatible. Charset names and labels are the ones known to browsers,
+// e.g. 'ISO-8859-2', 'windows-1251', 'Shift_JIS' or 'GBK'. Unknown charset fails the response.
+func decodeCharset(body []byte, contentType string) ([]byte, bool, error) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return body, false, nil
+	}
+
+	charset := params["charset"]
+	if charset == "" || isUTF8Compatible(charset) {
+		return body, false, nil
+	}
+
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return body, false, fmt.Errorf("Unsupported charset '%s' of response 'Content-Type'", charset)
+	}
+
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return body, false, nil
+	}
+
+	decoded, err := enc.NewDecoder().Bytes(body)
+	if err != nil {
+		return body, false, fmt.Errorf("Can't decode response body from charset '%s': %s", charset, err)
+	}
+
+	return decoded, true, nil
+}
+
+func isUTF8Compatible(charset string) bool {
+	switch strings.ToLower(charset) {
+	case "utf-8", "utf8", "us-ascii", "ascii":
+		return true
+	}
+
+	return false
+}
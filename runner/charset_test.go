@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLatin1ResponseBody(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/json" {
+			w.Header().Set("Content-Type", "application/json; charset=ISO-8859-1")
+			w.Write([]byte("{\"name\": \"Jos\xe9\"}"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+		w.Write([]byte("Caf\xe9 cr\xe8me"))
+	}))
+	defer server.Close()
+
+	size := 10
+	calls := []Call{
+		{
+			On:     On{Method: "GET", URL: server.URL + "/text"},
+			Expect: Expect{BodyContains: "Café crème", BodySize: &BodySizeAssert{Bytes: &size}},
+		},
+		{
+			On:     On{Method: "GET", URL: server.URL + "/json"},
+			Expect: Expect{BPath: map[string]interface{}{"name": "José"}},
+		},
+	}
+
+	for _, c := range calls {
+		// when
+		trace := call("", c, NewVars(""))
+
+		// then
+		if trace.HasError() {
+			t.Error(trace.ErrorCause)
+		}
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	cases := []struct {
+		contentType string
+		body        string
+		expected    string
+		decoded     bool
+	}{
+		{"text/plain", "Café", "Café", false},
+		{"text/plain; charset=utf-8", "Café", "Café", false},
+		{"text/plain; charset=latin1", "Caf\xe9", "Café", true},
+		{"text/plain; charset=windows-1252", "\x80 5", "€ 5", true},
+		{"text/plain; charset=koi8-r", "\xe9", "И", true},
+		{"text/plain; charset=ISO-8859-2", "\xb3\xf3d\xbc", "łódź", true},
+		{"text/plain; charset=Shift_JIS", "\x93\xfa\x96\x7b", "日本", true},
+	}
+
+	for _, c := range cases {
+		body, decoded, err := decodeCharset([]byte(c.body), c.contentType)
+		if err != nil || string(body) != c.expected || decoded != c.decoded {
+			t.Errorf("%s: expected %q (%v), got %q (%v), error %v", c.contentType, c.expected, c.decoded, string(body), decoded, err)
+		}
+	}
+}
+
+func TestUnsupportedCharset(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=x-klingon")
+		w.Write([]byte("Qapla'"))
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{BodyContains: "Qapla'"}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.ErrorCause == nil || trace.ErrorCause.Error() != "Unsupported charset 'x-klingon' of response 'Content-Type'" {
+		t.Error("Expected unsupported charset error, got:", trace.ErrorCause)
+	}
+}
@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ClientOptions configures HTTP client of suite requests, overriding options of the run
+type ClientOptions struct {
+	// limit of the request including reading of the response body, e.g. '5s'. Options.RequestTimeout if not set
+	Timeout string `json:"timeout"`
+	// server certificates are not verified
+	Insecure bool `json:"insecure"`
+	// URL of the proxy, e.g. 'http://proxy.local:3128'. Environment proxy (HTTP_PROXY, HTTPS_PROXY) if not set
+	Proxy string `json:"proxy"`
+	// redirect responses are followed unless false, call could override it
+	FollowRedirects *bool `json:"followRedirects"`
+}
+
+func (c *ClientOptions) validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.Timeout != "" {
+		if _, err := time.ParseDuration(c.Timeout); err != nil {
+			return fmt.Errorf("Invalid client timeout '%s': %s", c.Timeout, err)
+		}
+	}
+
+	if c.Proxy != "" {
+		if u, err := url.Parse(c.Proxy); err != nil || u.Host == "" {
+			return fmt.Errorf("Invalid client proxy '%s'", c.Proxy)
+		}
+	}
+
+	return nil
+}
+
+// clientConfig is effective configuration of HTTP client, clients are shared by calls of the same configuration
+type clientConfig struct {
+	timeout     time.Duration
+	insecure    bool
+	proxy       string
+	noRedirects bool
+}
+
+// newClientConfig merges settings of the call, suite and the run, the most specific one wins
+func newClientConfig(on On) clientConfig {
+	cfg := clientConfig{timeout: options.RequestTimeout, insecure: options.Insecure}
+
+	followRedirects := on.FollowRedirects
+	if suite := on.Client; suite != nil {
+		if suite.Timeout != "" {
+			cfg.timeout, _ = time.ParseDuration(suite.Timeout)
+		}
+		cfg.insecure = cfg.insecure || suite.Insecure
+		cfg.proxy = suite.Proxy
+
+		if followRedirects == nil {
+			followRedirects = suite.FollowRedirects
+		}
+	}
+	cfg.noRedirects = followRedirects != nil && !*followRedirects
+
+	return cfg
+}
+
+var (
+	clientsMu sync.Mutex
+	clients   = make(map[clientConfig]*http.Client)
+)
+
+// httpClient returns client of the configuration, it is created once unless test requests are sent with Options.Transport
+func httpClient(cfg clientConfig) *http.Client {
+	if options.Transport != nil {
+		return newHTTPClient(cfg, options.Transport)
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	client, ok := clients[cfg]
+	if !ok {
+		client = newHTTPClient(cfg, configuredTransport(cfg))
+		clients[cfg] = client
+	}
+
+	return client
+}
+
+func newHTTPClient(cfg clientConfig, transport http.RoundTripper) *http.Client {
+	client := &http.Client{Transport: transport, Timeout: cfg.timeout}
+	if cfg.noRedirects {
+		client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	return client
+}
+
+// configuredTransport returns transport with TLS verification and proxy of the configuration
+func configuredTransport(cfg clientConfig) http.RoundTripper {
+	if cfg.proxy == "" {
+		if cfg.insecure {
+			return insecureTransport
+		}
+		return http.DefaultTransport
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL, err := url.Parse(cfg.proxy); err == nil {
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if cfg.insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return transport
+}
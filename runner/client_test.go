@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSuiteClientTimeouts(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"impatient.suite.json": `{"client": {"timeout": "50ms"}, "cases": [{"name": "slow", "calls": [{"on": {"method": "GET", "url": "/slow"}, "expect": {"statusCode": 200}}]}]}`,
+		"patient.suite.json":   `{"client": {"timeout": "5s"}, "cases": [{"name": "slow", "calls": [{"on": {"method": "GET", "url": "/slow"}, "expect": {"statusCode": 200}}]}]}`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, RequestTimeout: 10 * time.Millisecond})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		switch result.Suite.Name {
+		case "impatient":
+			var transportErr *TransportError
+			if !result.HasError() || !errors.As(result.Traces[0].ErrorCause, &transportErr) || transportErr.Category != TransportErrorTimeout {
+				t.Errorf("Expected timeout of impatient suite, got %v", result.Traces[0].ErrorCause)
+			}
+		case "patient":
+			if result.HasError() {
+				t.Errorf("Expected patient suite to pass, got %v", result.Traces[0].ErrorCause)
+			}
+		}
+	}
+}
+
+func TestHTTPClientSharedByConfiguration(t *testing.T) {
+	defer func(opts Options) { options = opts }(options)
+	options = Options{}
+
+	noRedirects := false
+	suite := &ClientOptions{Timeout: "3s", Proxy: "http://proxy.local:3128"}
+
+	first := httpClient(newClientConfig(On{Client: suite}))
+	second := httpClient(newClientConfig(On{Client: &ClientOptions{Timeout: "3s", Proxy: "http://proxy.local:3128"}}))
+	if first != second {
+		t.Error("Expected client to be shared by suites of the same settings")
+	}
+
+	if first.Timeout != 3*time.Second {
+		t.Errorf("Unexpected timeout %s", first.Timeout)
+	}
+
+	if other := httpClient(newClientConfig(On{Client: suite, FollowRedirects: &noRedirects})); other == first || other.CheckRedirect == nil {
+		t.Error("Expected separate client not following redirects")
+	}
+}
+
+func TestClientOptionsInvalid(t *testing.T) {
+	for _, c := range []ClientOptions{{Timeout: "5"}, {Proxy: "proxy.local"}} {
+		if err := c.validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", c)
+		}
+	}
+}
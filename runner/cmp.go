@@ -1,4 +1,4 @@
-package main
+package runner
 
 import (
 	"encoding/json"
@@ -13,18 +13,28 @@ import (
 )
 
 type bodyDiffReporter struct {
-	cmp.Option
-
 	strict bool
 
-	ndiffs int // Total number of differences
-	nbytes int // Number of bytes in diffs
-	nlines int // Number of lines in diffs
+	path   cmp.Path // Path of the current comparison
+	ndiffs int      // Total number of differences
+	nbytes int      // Number of bytes in diffs
+	nlines int      // Number of lines in diffs
 	diffs  []string
 	errors []error
 }
 
-func (r *bodyDiffReporter) Report(expected, actual reflect.Value, eq bool, p cmp.Path) {
+func (r *bodyDiffReporter) PushStep(ps cmp.PathStep) {
+	r.path = append(r.path, ps)
+}
+
+func (r *bodyDiffReporter) PopStep() {
+	r.path = r.path[:len(r.path)-1]
+}
+
+func (r *bodyDiffReporter) Report(rs cmp.Result) {
+	expected, actual := r.path.Last().Values()
+	eq := rs.Equal()
+	p := r.path
 
 	missing := func(v reflect.Value) bool {
 		return !v.IsValid()
@@ -72,13 +82,25 @@ func (r *bodyDiffReporter) recordDiff(expected, actual reflect.Value, eq bool, p
 			sx = Format(expected, FormatConfig{PrintPrimitiveType: true})
 			sy = Format(actual, FormatConfig{PrintPrimitiveType: true})
 		}
-		s := fmt.Sprintf("%#v:\n\tExpected: %s\n\tActual: %s\n", p, sx, sy)
+		s := fmt.Sprintf("%#v:\n\tExpected: %s\n\tActual: %s\n", withoutTypeAssertions(p), sx, sy)
 		r.diffs = append(r.diffs, s)
 		r.nbytes += len(s)
 		r.nlines += strings.Count(s, "\n")
 	}
 }
 
+// withoutTypeAssertions drops type assertions of interface values from the path, e.g. root["a"][1]
+func withoutTypeAssertions(p cmp.Path) cmp.Path {
+	filtered := make(cmp.Path, 0, len(p))
+	for _, step := range p {
+		if _, ok := step.(cmp.TypeAssertion); !ok {
+			filtered = append(filtered, step)
+		}
+	}
+
+	return filtered
+}
+
 func (r bodyDiffReporter) String() string {
 	return strings.Join(r.diffs, "\n")
 }
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"regexp"
+	"strconv"
+)
+
+// numberString matches string that is a JSON number, e.g. "5", "-1.5", "2e3"
+var numberString = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// coerceNumbers returns copy of the value where strings holding JSON numbers are replaced with numbers,
+// so "5" and 5 are equal in comparison. Parsed response body is cached, it is not modified.
+func coerceNumbers(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case string:
+		if !numberString.MatchString(typed) {
+			return typed
+		}
+
+		number, err := strconv.ParseFloat(typed, 64)
+		if err != nil {
+			return typed
+		}
+
+		return number
+	case map[string]interface{}:
+		coerced := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			coerced[key] = coerceNumbers(item)
+		}
+		return coerced
+	case []interface{}:
+		coerced := make([]interface{}, len(typed))
+		for i, item := range typed {
+			coerced[i] = coerceNumbers(item)
+		}
+		return coerced
+	default:
+		return value
+	}
+}
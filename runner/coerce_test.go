@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestBodyPathCoercedNumbersEqual(t *testing.T) {
+	// given
+	m, err := jsonAsMap(`{"id": 5, "owner.id": "7", "items.id": [1, 2]}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m, coerceNumbers: true}
+
+	// when
+	err = exp.check(testResponse(http.Header{"Content-Type": {"application/json"}}, `{"id": "5", "owner": {"id": 7.0}, "items": [{"id": "1"}, {"id": 2}]}`))
+
+	// then
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyPathStrictNumbersNotEqual(t *testing.T) {
+	// given
+	m, err := jsonAsMap(`{"id": 5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	// when
+	err = exp.check(testResponse(http.Header{"Content-Type": {"application/json"}}, `{"id": "5"}`))
+
+	// then
+	if err == nil {
+		t.Error("Expected string \"5\" to differ from number 5 without coercion")
+	}
+}
+
+func TestBodyCoercedNumbers(t *testing.T) {
+	tests := []struct {
+		name   string
+		coerce bool
+		body   string
+		passes bool
+	}{
+		{name: "coerced equal", coerce: true, body: `{"id": "5", "name": "five"}`, passes: true},
+		{name: "strict not equal", coerce: false, body: `{"id": "5", "name": "five"}`, passes: false},
+		{name: "not a JSON number", coerce: true, body: `{"id": "05", "name": "five"}`, passes: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := BodyExpectation{ExpectedBody: map[string]interface{}{"id": 5.0, "name": "five"}, CoerceNumbers: tt.coerce}
+
+			err := exp.check(testResponse(http.Header{"Content-Type": {"application/json"}}, tt.body))
+			if (err == nil) != tt.passes {
+				t.Errorf("Expected passed %v, got error %v", tt.passes, err)
+			}
+		})
+	}
+}
+
+func TestCoerceNumbersKeepsOriginal(t *testing.T) {
+	// given
+	body := map[string]interface{}{"id": "5"}
+
+	// when
+	coerceNumbers(body)
+
+	// then
+	if body["id"] != "5" {
+		t.Errorf("Expected original value to be kept, got %#v", body["id"])
+	}
+}
@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// modes of colored console output
+const (
+	// color only if output is a terminal
+	ColorAuto = "auto"
+	// color even if output is piped
+	ColorAlways = "always"
+	// no color
+	ColorNever = "never"
+)
+
+// ValidateColorMode checks value of '--color' option
+func ValidateColorMode(mode string) error {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+
+	return fmt.Errorf("Invalid color mode '%s', expected %s, %s or %s", mode, ColorAuto, ColorAlways, ColorNever)
+}
+
+// ColorEnabled decides whether output written to w is colored in the mode.
+// In auto mode w must be a terminal and 'TERM' must not be 'dumb'.
+func ColorEnabled(mode string, w io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	return os.Getenv("TERM") != "dumb" && isTerminal(w)
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
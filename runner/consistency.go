@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConsistencyAssert compares response body with the snapshot remembered by a previous call of the suite, e.g. for idempotency
+type ConsistencyAssert struct {
+	// name of the snapshot given in 'remember.snapshot'
+	Snapshot string `json:"snapshot"`
+	// paths excluded from comparison, e.g. generated timestamps
+	Ignore []string `json:"ignore"`
+
+	// body of the snapshot, resolved before the check
+	body  interface{}
+	found bool
+}
+
+// ConsistencyExpectation validates body equals the snapshot except ignored paths
+type ConsistencyExpectation struct {
+	ConsistencyAssert
+}
+
+func (e ConsistencyExpectation) check(resp *Response) error {
+	if !e.found {
+		return fmt.Errorf("Snapshot '%s' is not remembered, it must be remembered with 'remember.snapshot' by a previous call of the suite", e.Snapshot)
+	}
+
+	body, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("Can't parse response body. %s", err)
+	}
+
+	matcher := NewBodyMatcher{Strict: true, ExpectedBody: withoutPaths(e.body, e.Ignore)}
+	if err := matcher.check(withoutPaths(body, e.Ignore)); err != nil {
+		return fmt.Errorf("Body differs from snapshot '%s'. %s", e.Snapshot, err)
+	}
+
+	return nil
+}
+
+func (e ConsistencyExpectation) desc() string {
+	if len(e.Ignore) > 0 {
+		return fmt.Sprintf("Body is consistent with snapshot '%s' ignoring %v", e.Snapshot, e.Ignore)
+	}
+
+	return fmt.Sprintf("Body is consistent with snapshot '%s'", e.Snapshot)
+}
+
+// withoutPaths returns copy of the body without values on paths, paths traverse arrays the same way as 'bodyPath'
+func withoutPaths(body interface{}, paths []string) interface{} {
+	for _, path := range paths {
+		body = withoutPath(body, strings.Split(path, expectationPathSeparator))
+	}
+
+	return body
+}
+
+func withoutPath(value interface{}, path []string) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(typed))
+		for k, v := range typed {
+			if k != path[0] {
+				m[k] = v
+				continue
+			}
+
+			if len(path) > 1 {
+				m[k] = withoutPath(v, path[1:])
+			}
+		}
+		return m
+	case []interface{}:
+		items := make([]interface{}, 0, len(typed))
+		for _, item := range typed {
+			items = append(items, withoutPath(item, path))
+		}
+		return items
+	}
+
+	return value
+}
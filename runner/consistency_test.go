@@ -0,0 +1,98 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunConsistentWith(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		requests++
+		n := requests
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/stable" {
+			fmt.Fprintf(w, `{"items": [{"id": 1, "viewedAt": %d}], "total": 1, "generatedAt": %d}`, n, n)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [{"id": %d}], "total": 1}`, n)
+	}))
+	defer server.Close()
+
+	suite := func(path string, ignore string) string {
+		return `[
+			{"name": "first", "calls": [{"on": {"method": "GET", "url": "` + server.URL + path + `"}, "expect": {"statusCode": 200}, "remember": {"snapshot": "orders"}}]},
+			{"name": "second", "calls": [{"on": {"method": "GET", "url": "` + server.URL + path + `"}, "expect": {"consistentWith": {"snapshot": "orders"` + ignore + `}}}]}
+		]`
+	}
+
+	dir := writeTestFiles(t, map[string]string{
+		"stable.suite.json":   suite("/stable", `, "ignore": ["generatedAt", "items.viewedAt"]`),
+		"unstable.suite.json": suite("/unstable", ""),
+		"missing.suite.json":  `[{"name": "no snapshot", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/stable"}, "expect": {"consistentWith": {"snapshot": "orders"}}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	failures := make(map[string]string)
+	for _, result := range results {
+		if result.HasError() {
+			failures[result.Suite.Name+"/"+result.Case.Name] = result.Error()
+		}
+	}
+
+	if len(failures) != 2 {
+		t.Fatalf("Expected 2 failed test cases, got %v", failures)
+	}
+
+	if msg := failures["unstable/second"]; !strings.HasPrefix(msg, "Body differs from snapshot 'orders'") {
+		t.Errorf("Expected differing body to fail, got %s", msg)
+	}
+
+	if msg := failures["missing/no snapshot"]; !strings.HasPrefix(msg, "Snapshot 'orders' is not remembered") {
+		t.Errorf("Expected snapshot of another suite not to be available, got %s", msg)
+	}
+}
+
+func TestWithoutPaths(t *testing.T) {
+	body := map[string]interface{}{
+		"total": 2.0,
+		"meta":  map[string]interface{}{"generatedAt": "now", "version": 1.0},
+		"items": []interface{}{map[string]interface{}{"id": 1.0, "seen": 1.0}, map[string]interface{}{"id": 2.0}},
+	}
+
+	actual := withoutPaths(body, []string{"meta.generatedAt", "items.seen", "unknown.path"})
+
+	expected := map[string]interface{}{
+		"total": 2.0,
+		"meta":  map[string]interface{}{"version": 1.0},
+		"items": []interface{}{map[string]interface{}{"id": 1.0}, map[string]interface{}{"id": 2.0}},
+	}
+	if err := (NewBodyMatcher{Strict: true, ExpectedBody: expected}).check(actual); err != nil {
+		t.Error(err)
+	}
+
+	if _, ok := body["meta"].(map[string]interface{})["generatedAt"]; !ok {
+		t.Error("Expected original body not to be modified")
+	}
+}
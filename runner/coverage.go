@@ -0,0 +1,221 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// methods of OpenAPI path item in the order of the coverage report
+var coverageMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+var pathParamPattern = regexp.MustCompile(`\{[^}/]+\}`)
+
+// CoverageReporter tracks which operations of OpenAPI document are called during the run.
+// Requests are matched to operations by method and path template, e.g. 'GET /users/42' to 'GET /users/{id}'.
+type CoverageReporter struct {
+	SpecFile string
+	// destination of the coverage report, os.Stdout if not set
+	Writer io.Writer
+	// Check fails if any operation is not called
+	Required bool
+
+	operations []coverageOperation
+	// path prefixes of the API, e.g. '/v1' of 'servers' or 'basePath'
+	basePaths []string
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+type coverageOperation struct {
+	Method string
+	Path   string
+
+	pattern *regexp.Regexp
+	// number of literal characters of the path template, more specific template wins
+	literal int
+}
+
+func (op coverageOperation) String() string {
+	return op.Method + " " + op.Path
+}
+
+// NewCoverageReporter reads operations of OpenAPI 3 (or Swagger 2) document in JSON
+func NewCoverageReporter(specFile string, required bool) (*CoverageReporter, error) {
+	data, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read OpenAPI document: %s", err)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("Invalid OpenAPI document %s: %s", specFile, err)
+	}
+
+	r := &CoverageReporter{SpecFile: specFile, Required: required, calls: make(map[string]int)}
+
+	paths, _ := spec["paths"].(map[string]interface{})
+	for _, path := range sortedKeys(paths) {
+		item, _ := paths[path].(map[string]interface{})
+		literals := pathParamPattern.Split(path, -1)
+		for i := range literals {
+			literals[i] = regexp.QuoteMeta(literals[i])
+		}
+		pattern := "^" + strings.Join(literals, "[^/]+") + "/?$"
+		literal := len(pathParamPattern.ReplaceAllString(path, ""))
+
+		for _, method := range coverageMethods {
+			if _, ok := item[method]; ok {
+				r.operations = append(r.operations, coverageOperation{
+					Method:  strings.ToUpper(method),
+					Path:    path,
+					pattern: regexp.MustCompile(pattern),
+					literal: literal,
+				})
+			}
+		}
+	}
+
+	if len(r.operations) == 0 {
+		return nil, fmt.Errorf("OpenAPI document %s has no operations", specFile)
+	}
+
+	r.basePaths = specBasePaths(spec)
+	return r, nil
+}
+
+// specBasePaths returns path prefixes of servers (OpenAPI 3) or basePath (Swagger 2), the longest first
+func specBasePaths(spec map[string]interface{}) []string {
+	basePaths := []string{""}
+
+	if basePath, ok := spec["basePath"].(string); ok {
+		basePaths = append(basePaths, strings.TrimSuffix(basePath, "/"))
+	}
+
+	servers, _ := spec["servers"].([]interface{})
+	for _, server := range servers {
+		server, _ := server.(map[string]interface{})
+		serverURL, _ := server["url"].(string)
+		if u, err := url.Parse(serverURL); err == nil && !strings.Contains(u.Path, "{") {
+			basePaths = append(basePaths, strings.TrimSuffix(u.Path, "/"))
+		}
+	}
+
+	sort.Slice(basePaths, func(i, j int) bool { return len(basePaths[i]) > len(basePaths[j]) })
+	return basePaths
+}
+
+// match finds operation of the request, the most specific path template wins, e.g. '/users/me' over '/users/{id}'
+func (r *CoverageReporter) match(method string, path string) (coverageOperation, bool) {
+	for _, basePath := range r.basePaths {
+		if !strings.HasPrefix(path, basePath+"/") && path != basePath {
+			continue
+		}
+		rest := "/" + strings.TrimPrefix(path[len(basePath):], "/")
+
+		var found *coverageOperation
+		for i, op := range r.operations {
+			if op.Method != strings.ToUpper(method) || !op.pattern.MatchString(rest) {
+				continue
+			}
+			if found == nil || op.literal > found.literal {
+				found = &r.operations[i]
+			}
+		}
+
+		if found != nil {
+			return *found, true
+		}
+	}
+
+	return coverageOperation{}, false
+}
+
+// Init does nothing since calls are counted across runs
+func (r *CoverageReporter) Init() {
+
+}
+
+// Report counts calls of operations made by test cases
+func (r *CoverageReporter) Report(results []TestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, result := range results {
+		for _, trace := range result.Traces {
+			if trace.RequestMethod == "" {
+				continue
+			}
+
+			u, err := url.Parse(trace.RequestURL)
+			if err != nil {
+				continue
+			}
+
+			if op, ok := r.match(trace.RequestMethod, u.Path); ok {
+				r.calls[op.String()]++
+			}
+		}
+	}
+}
+
+// Flush writes number of calls of every operation and lists untested operations
+func (r *CoverageReporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	w := r.Writer
+	if w == nil {
+		w = os.Stdout
+	}
+
+	untested := r.untested()
+	covered := len(r.operations) - len(untested)
+	fmt.Fprintf(w, "\nEndpoint coverage of %s: %d/%d operations (%.1f%%)\n", r.SpecFile, covered, len(r.operations), 100*float64(covered)/float64(len(r.operations)))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, op := range r.operations {
+		if calls := r.calls[op.String()]; calls > 0 {
+			fmt.Fprintf(tw, "    %s\t%s\t%d\n", op.Method, op.Path, calls)
+		}
+	}
+	tw.Flush()
+
+	if len(untested) > 0 {
+		fmt.Fprintf(w, "Untested operations:\n    %s\n", strings.Join(untested, "\n    "))
+	}
+}
+
+func (r *CoverageReporter) untested() []string {
+	var untested []string
+	for _, op := range r.operations {
+		if r.calls[op.String()] == 0 {
+			untested = append(untested, op.String())
+		}
+	}
+
+	return untested
+}
+
+// Check fails if coverage is required and any operation is not called
+func (r *CoverageReporter) Check() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	untested := r.untested()
+	if !r.Required || len(untested) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("Endpoint coverage is incomplete (--require-coverage), %d operation(s) of %s are not called:\n    %s",
+		len(untested), r.SpecFile, strings.Join(untested, "\n    "))
+}
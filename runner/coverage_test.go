@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const coverageSpec = `{
+	"openapi": "3.0.0",
+	"servers": [{"url": "https://api.example.com/v1"}],
+	"paths": {
+		"/users": {"get": {}, "post": {}},
+		"/users/{id}": {"get": {}, "delete": {}},
+		"/users/me": {"get": {}}
+	}
+}`
+
+func TestCoverageReporter(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"openapi.json": coverageSpec,
+		"users.suite.json": `[{"name": "users", "calls": [
+			{"on": {"method": "GET", "url": "` + server.URL + `/v1/users"}, "expect": {"statusCode": 200}},
+			{"on": {"method": "GET", "url": "` + server.URL + `/v1/users/42"}, "expect": {"statusCode": 200}},
+			{"on": {"method": "GET", "url": "` + server.URL + `/v1/users/42"}, "expect": {"statusCode": 200}},
+			{"on": {"method": "GET", "url": "` + server.URL + `/v1/users/me"}, "expect": {"statusCode": 200}},
+			{"on": {"method": "POST", "url": "` + server.URL + `/v1/users"}, "expect": {"statusCode": 201}}
+		]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	for _, required := range []bool{false, true} {
+		coverage, err := NewCoverageReporter(filepath.Join(dir, "openapi.json"), required)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		coverage.Writer = &out
+
+		// when
+		if _, err := Run(context.Background(), Options{Path: filepath.Join(dir, "users.suite.json"), Reporter: coverage}); err != nil {
+			t.Fatal(err)
+		}
+
+		// then
+		report := out.String()
+		if !strings.Contains(report, "4/5 operations (80.0%)") || !strings.Contains(report, "Untested operations:\n    DELETE /users/{id}\n") {
+			t.Errorf("Unexpected coverage report: %s", report)
+		}
+
+		// failed call of POST counts too, path template with literal 'me' is preferred over parameter
+		for _, line := range []string{"GET   /users       1", "POST  /users       1", "GET   /users/{id}  2", "GET   /users/me    1"} {
+			if !strings.Contains(report, line) {
+				t.Errorf("Expected '%s' in coverage report: %s", line, report)
+			}
+		}
+
+		err = coverage.Check()
+		if !required && err != nil {
+			t.Errorf("Expected incomplete coverage not to fail without requirement, got %s", err)
+		}
+		if required && (err == nil || !strings.HasSuffix(err.Error(), "1 operation(s) of "+filepath.Join(dir, "openapi.json")+" are not called:\n    DELETE /users/{id}")) {
+			t.Errorf("Expected untested operation to fail the run, got %v", err)
+		}
+	}
+}
+
+func TestCoverageReporterMatch(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"swagger.json": `{"swagger": "2.0", "basePath": "/api/", "paths": {"/orders/{id}/items": {"put": {}}}}`})
+	defer os.RemoveAll(dir)
+
+	coverage, err := NewCoverageReporter(filepath.Join(dir, "swagger.json"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method  string
+		path    string
+		matched bool
+	}{
+		{"PUT", "/api/orders/7/items", true},
+		{"put", "/orders/7/items/", true},
+		{"GET", "/api/orders/7/items", false},
+		{"PUT", "/api/orders/7/items/1", false},
+		{"PUT", "/apiorders/7/items", false},
+	}
+
+	for _, tt := range tests {
+		if _, matched := coverage.match(tt.method, tt.path); matched != tt.matched {
+			t.Errorf("%s %s: expected matched %v", tt.method, tt.path, tt.matched)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// Deprecation is an occurrence of deprecated but still supported syntax in a suite file
+type Deprecation struct {
+	Path    string
+	Line    int
+	Message string
+}
+
+func (d Deprecation) String() string {
+	return fmt.Sprintf("%s:%d: %s", d.Path, d.Line, d.Message)
+}
+
+// deprecatedSyntax describes deprecated value of a key
+type deprecatedSyntax struct {
+	// keys of the path, '*' stands for array index, e.g. 'calls.*.on.body'. Matched against the end of the value path
+	path    string
+	applies func(value json.RawMessage) bool
+	message string
+}
+
+var deprecatedSyntaxes = []deprecatedSyntax{
+	{
+		path:    "calls.*.on.body",
+		applies: isJSONInString,
+		message: "JSON request body as a string is deprecated, use JSON object or array instead",
+	},
+}
+
+// isJSONInString checks string value is sent as JSON object or array, e.g. "[1, 2]". Quotes of body string are removed, escapes are kept
+func isJSONInString(value json.RawMessage) bool {
+	if len(value) < 2 || value[0] != '"' {
+		return false
+	}
+
+	content := bytes.TrimSpace(value[1 : len(value)-1])
+	return len(content) > 0 && (content[0] == '{' || content[0] == '[') && json.Valid(content)
+}
+
+// FindDeprecations lists occurrences of deprecated syntax in test suites of the root directory
+func FindDeprecations(rootDir, suiteExt, xsuiteExt string) []Deprecation {
+	source := &DirSuiteFileIterator{RootDir: rootDir, SuiteExt: suiteExt, XSuiteExt: xsuiteExt}
+	source.init()
+
+	var found []Deprecation
+	for source.HasNext() {
+		sf := source.Next()
+		if sf == nil {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(sf.Path)
+		if err != nil {
+			continue
+		}
+
+		found = append(found, findDeprecations(sf.Path, content)...)
+	}
+
+	return found
+}
+
+func findDeprecations(path string, content []byte) []Deprecation {
+	var found []Deprecation
+	walkJSON(content, 0, "", func(valuePath string, value json.RawMessage, offset int64) {
+		for _, syntax := range deprecatedSyntaxes {
+			if (valuePath == syntax.path || strings.HasSuffix(valuePath, "."+syntax.path)) && syntax.applies(value) {
+				line, _ := textPosition(content, offset)
+				found = append(found, Deprecation{Path: path, Line: line, Message: syntax.message})
+			}
+		}
+	})
+
+	return found
+}
+
+// walkJSON visits nested values of the document with their path of keys, '*' stands for array index.
+// Offset of the value is the end of its key (or preceding element of array) in the document.
+func walkJSON(data json.RawMessage, base int64, path string, visit func(path string, value json.RawMessage, offset int64)) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return
+	}
+
+	for dec.More() {
+		key := "*"
+		if delim == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return
+			}
+			key, _ = tok.(string)
+		}
+		offset := dec.InputOffset()
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return
+		}
+
+		valuePath := key
+		if path != "" {
+			valuePath = path + "." + key
+		}
+
+		visit(valuePath, value, base+offset)
+		walkJSON(value, base+dec.InputOffset()-int64(len(value)), valuePath, visit)
+	}
+}
+
+// writeDeprecations warns about deprecated syntax, the run is not affected
+func writeDeprecations(w io.Writer, deprecations []Deprecation) {
+	if len(deprecations) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "Warning: deprecated syntax is used, it is still supported but will be removed in future versions:")
+	for _, d := range deprecations {
+		fmt.Fprintln(w, "  "+d.String())
+	}
+	fmt.Fprintln(w)
+}
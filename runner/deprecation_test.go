@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRunWarnsAboutDeprecatedSyntax(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	var mu sync.Mutex
+	received := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		received[req.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"users.suite.json": `[
+  {
+    "name": "create user",
+    "calls": [
+      {
+        "on": {
+          "method": "POST",
+          "url": "/users",
+          "headers": { "Content-Type": "application/json" },
+          "body": "[1, 2, 3]"
+        },
+        "expect": { "statusCode": 200 }
+      }
+    ]
+  }
+]`,
+		"orders.suite.json": `[{"name": "create order", "calls": [{"on": {"method": "POST", "url": "/orders", "body": [{"id": 1}]}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	var warnings bytes.Buffer
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, WarningOutput: &warnings})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := filepath.Join(dir, "users.suite.json") + ":10: JSON request body as a string is deprecated"
+	if !strings.Contains(warnings.String(), expected) {
+		t.Errorf("Expected warning '%s', got:\n%s", expected, warnings.String())
+	}
+
+	if strings.Contains(warnings.String(), "orders.suite.json") {
+		t.Errorf("Unexpected warning about JSON body:\n%s", warnings.String())
+	}
+
+	for _, result := range results {
+		if result.HasError() {
+			t.Errorf("Expected run to proceed, '%s' failed: %v", result.Case.Name, result.Traces[0].ErrorCause)
+		}
+	}
+
+	if received["/users"] != "[1, 2, 3]" || received["/orders"] != `[{"id": 1}]` {
+		t.Errorf("Unexpected bodies %v", received)
+	}
+}
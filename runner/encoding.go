@@ -0,0 +1,75 @@
+package runner
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// decodeContentEncoding returns body decompressed according to 'Content-Encoding' and the encoding sent by the server.
+// Response decompressed by the client transparently has no 'Content-Encoding' anymore, it is always gzip in this case.
+// Body of other encodings (e.g. br) is returned as is.
+func decodeContentEncoding(resp *http.Response, body []byte) ([]byte, string, error) {
+	if resp.Uncompressed {
+		return body, "gzip", nil
+	}
+
+	encoding := resp.Header.Get("Content-Encoding")
+	if len(body) == 0 {
+		return body, encoding, nil
+	}
+
+	var reader io.ReadCloser
+	var err error
+	switch strings.ToLower(encoding) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(bytes.NewReader(body))
+	case "deflate":
+		reader, err = zlib.NewReader(bytes.NewReader(body))
+	default:
+		return body, encoding, nil
+	}
+	if err != nil {
+		return nil, encoding, fmt.Errorf("Can't decode %s response body: %s", encoding, err)
+	}
+	defer reader.Close()
+
+	decoded, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, encoding, fmt.Errorf("Can't decode %s response body: %s", encoding, err)
+	}
+
+	// the same as the client does for transparently decompressed response
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return decoded, encoding, nil
+}
+
+// ContentEncodingExpectation validates 'Content-Encoding' of the response as sent by the server, before decompression.
+type ContentEncodingExpectation struct {
+	Value string
+}
+
+func (e ContentEncodingExpectation) check(resp *Response) error {
+	actual := resp.contentEncoding
+	if actual == "" {
+		actual = "identity"
+	}
+
+	if !strings.EqualFold(actual, e.Value) {
+		return fmt.Errorf("Unexpected Content-Encoding. Expected: %s, actual: %s", e.Value, actual)
+	}
+	return nil
+}
+
+func (e ContentEncodingExpectation) desc() string {
+	return fmt.Sprintf("Content-Encoding is '%s'", e.Value)
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newGzipServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(req.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte(`{"name": "plain"}`))
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(`{"name": "compressed"}`))
+		gz.Close()
+	}))
+}
+
+func TestContentEncodingRequestedExplicitly(t *testing.T) {
+	// given
+	server := newGzipServer()
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL, Headers: map[string]string{"Accept-Encoding": "gzip"}},
+		Expect: Expect{ContentEncoding: "gzip", BPath: map[string]interface{}{"name": "compressed"}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+}
+
+func TestContentEncodingDecompressedByClient(t *testing.T) {
+	// given
+	server := newGzipServer()
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL},
+		Expect: Expect{ContentEncoding: "gzip", BPath: map[string]interface{}{"name": "compressed"}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+}
+
+func TestContentEncodingIdentity(t *testing.T) {
+	// given
+	server := newGzipServer()
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL, Headers: map[string]string{"Accept-Encoding": "identity"}},
+		Expect: Expect{ContentEncoding: "gzip"},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || trace.ErrorCause.Error() != "Unexpected Content-Encoding. Expected: gzip, actual: identity" {
+		t.Errorf("Expected Content-Encoding mismatch, got %v", trace.ErrorCause)
+	}
+}
@@ -0,0 +1,182 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// EventStreamOn describes reading of server-sent events (text/event-stream) response of the call.
+// Events received within the timeout become response body: {"events": [{"event": "...", "data": "...", "id": "..."}]}
+type EventStreamOn struct {
+	// how long to read events, e.g. '2s'
+	Timeout string `json:"timeout"`
+	// reading stops once the number of events is received, 0 means no limit
+	MaxEvents int `json:"maxEvents"`
+	// reading stops once matching event is received
+	Until *EventMatch `json:"until"`
+}
+
+// EventMatch matches server-sent event by type and data
+type EventMatch struct {
+	Event string `json:"event"`
+	// data contains the text
+	Data string `json:"data"`
+}
+
+// ServerEvent is a single event received from text/event-stream response
+type ServerEvent struct {
+	Event string `json:"event"`
+	Data  string `json:"data"`
+	ID    string `json:"id,omitempty"`
+	// data parsed if it is JSON
+	JSON interface{} `json:"json,omitempty"`
+}
+
+const eventStreamDefaultTimeout = 5 * time.Second
+
+func (m *EventMatch) matches(e ServerEvent) bool {
+	if m.Event != "" && m.Event != e.Event {
+		return false
+	}
+
+	return strings.Contains(e.Data, m.Data)
+}
+
+func (s EventStreamOn) timeout() (time.Duration, error) {
+	if s.Timeout == "" {
+		return eventStreamDefaultTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(s.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid event stream timeout '%s': %s", s.Timeout, err)
+	}
+
+	return timeout, nil
+}
+
+// readEventStream reads events until the stream ends, timeout expires or stop condition is met.
+// Received events are recorded into the trace and returned as JSON body.
+func readEventStream(s *EventStreamOn, resp *http.Response, trace *CallTrace) ([]byte, error) {
+	timeout, err := s.timeout()
+	if err != nil {
+		return nil, err
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/event-stream" {
+		return nil, fmt.Errorf("Expected text/event-stream response, got Content-Type '%s' (status %d)", resp.Header.Get("Content-Type"), resp.StatusCode)
+	}
+
+	events := make(chan ServerEvent)
+	done := make(chan error, 1)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		done <- parseEventStream(resp.Body, events, stop)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	received := []ServerEvent{}
+read:
+	for {
+		select {
+		case event := <-events:
+			received = append(received, event)
+			if (s.MaxEvents > 0 && len(received) >= s.MaxEvents) || (s.Until != nil && s.Until.matches(event)) {
+				break read
+			}
+		case err := <-done:
+			if err != nil {
+				return nil, fmt.Errorf("Error reading event stream: %s", err)
+			}
+			break read
+		case <-timer.C:
+			break read
+		}
+	}
+
+	// unblocks reading of the stream
+	resp.Body.Close()
+
+	trace.Events = received
+
+	body, err := json.Marshal(map[string]interface{}{"events": received})
+	if err != nil {
+		return nil, err
+	}
+
+	// events are asserted as JSON body
+	resp.Header.Set("Content-Type", "application/json")
+	return body, nil
+}
+
+// parseEventStream parses event stream framing and sends dispatched events until the stream ends or stop is closed
+func parseEventStream(r io.Reader, events chan<- ServerEvent, stop <-chan struct{}) error {
+	scanner := bufio.NewScanner(r)
+
+	event := ServerEvent{}
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if data == nil {
+				event = ServerEvent{}
+				continue
+			}
+
+			if event.Event == "" {
+				event.Event = "message"
+			}
+			event.Data = strings.Join(data, "\n")
+			if json.Valid([]byte(event.Data)) {
+				json.Unmarshal([]byte(event.Data), &event.JSON)
+			}
+
+			select {
+			case events <- event:
+			case <-stop:
+				return nil
+			}
+
+			event, data = ServerEvent{}, nil
+			continue
+		}
+
+		// comment
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			field, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			event.ID = value
+		}
+	}
+
+	select {
+	case <-stop:
+		return nil
+	default:
+		return scanner.Err()
+	}
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newEventStreamServer emits few events and keeps the stream open until the client disconnects
+func newEventStreamServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+
+		fmt.Fprint(w, ": connected\n\n")
+		fmt.Fprint(w, "event: heartbeat\ndata: ping\n\n")
+		flusher.Flush()
+
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, "id: 7\nevent: order.created\ndata: {\"id\": 7,\ndata: \"status\": \"new\"}\n\n")
+		flusher.Flush()
+
+		<-req.Context().Done()
+	}))
+}
+
+func TestEventStreamUntilEvent(t *testing.T) {
+	// given
+	server := newEventStreamServer()
+	defer server.Close()
+
+	c := Call{
+		On: On{Method: "GET", URL: server.URL, EventStream: &EventStreamOn{Timeout: "2s", Until: &EventMatch{Event: "order.created"}}},
+		Expect: Expect{
+			StatusCode: 200,
+			AnyMatch:   map[string]map[string]interface{}{"events": {"event": "order.created", "json.status": "new"}},
+		},
+	}
+
+	// when
+	start := time.Now()
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected reading to stop once the event is received, took %s", time.Since(start))
+	}
+
+	if len(trace.Events) != 2 || trace.Events[1].ID != "7" || trace.Events[1].Data != "{\"id\": 7,\n\"status\": \"new\"}" {
+		t.Errorf("Unexpected events in the trace: %+v", trace.Events)
+	}
+}
+
+func TestEventStreamTimeout(t *testing.T) {
+	// given
+	server := newEventStreamServer()
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL, EventStream: &EventStreamOn{Timeout: "100ms"}},
+		Expect: Expect{AnyMatch: map[string]map[string]interface{}{"events": {"event": "order.cancelled"}}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || len(trace.Events) != 2 {
+		t.Errorf("Expected missing event failure with 2 received events, got %v, %+v", trace.ErrorCause, trace.Events)
+	}
+}
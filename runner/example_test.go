@@ -0,0 +1,45 @@
+package runner_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/kajf/bozr/runner"
+)
+
+func ExampleRun() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "bozr-example")
+	defer os.RemoveAll(dir)
+
+	suite := `[{
+		"name": "health check",
+		"calls": [{
+			"on": {"method": "GET", "url": "/health"},
+			"expect": {"statusCode": 200, "body": {"status": "ok"}}
+		}]
+	}]`
+	ioutil.WriteFile(filepath.Join(dir, "health"+runner.SuiteExt), []byte(suite), 0644)
+
+	results, err := runner.Run(context.Background(), runner.Options{Path: dir, BaseURL: server.URL})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, result := range results {
+		fmt.Printf("%s: failed %v\n", result.Case.Name, result.HasError())
+	}
+
+	// Output: health check: failed false
+}
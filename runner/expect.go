@@ -0,0 +1,1321 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ResponseExpectation is an interface to any validation
+// that needs to be performed on response.
+type ResponseExpectation interface {
+	// check does the response meet expectation
+	check(resp *Response) error
+
+	// desc returns user-friendly description of expectation
+	desc() string
+}
+
+// SkippedExpectation is temporarily disabled expectation that is reported, but not checked.
+type SkippedExpectation struct {
+	exp    ResponseExpectation
+	reason string
+}
+
+func (e SkippedExpectation) check(resp *Response) error {
+	return nil
+}
+
+func (e SkippedExpectation) desc() string {
+	return e.exp.desc()
+}
+
+const severityWarning = "warning"
+
+// WarningExpectation is an advisory expectation, its failure is reported but does not fail the test case
+type WarningExpectation struct {
+	exp ResponseExpectation
+}
+
+func (e WarningExpectation) check(resp *Response) error {
+	return e.exp.check(resp)
+}
+
+func (e WarningExpectation) desc() string {
+	return e.exp.desc()
+}
+
+// StatusCodeExpectation validates response HTTP code.
+type StatusCodeExpectation struct {
+	statusCode int
+}
+
+func (e StatusCodeExpectation) check(resp *Response) error {
+	if resp.http.StatusCode != e.statusCode {
+		return fmt.Errorf("Unexpected Status Code. Expected: %d, Actual: %d", e.statusCode, resp.http.StatusCode)
+	}
+	return nil
+}
+
+func (e StatusCodeExpectation) desc() string {
+	return fmt.Sprintf("Status code is %d", e.statusCode)
+}
+
+// BodyContainsExpectation validates raw response body contains the text
+type BodyContainsExpectation struct {
+	Text string
+}
+
+func (e BodyContainsExpectation) check(resp *Response) error {
+	if !strings.Contains(string(resp.body), e.Text) {
+		return fmt.Errorf("Body does not contain \"%s\"", e.Text)
+	}
+
+	return nil
+}
+
+func (e BodyContainsExpectation) desc() string {
+	return fmt.Sprintf("Body contains '%s'", e.Text)
+}
+
+// BodyMatchesExpectation validates raw response body matches regular expression
+type BodyMatchesExpectation struct {
+	Pattern *regexp.Regexp
+}
+
+func (e BodyMatchesExpectation) check(resp *Response) error {
+	if !e.Pattern.Match(resp.body) {
+		return fmt.Errorf("Body does not match regular expression '%s'", e.Pattern)
+	}
+
+	return nil
+}
+
+func (e BodyMatchesExpectation) desc() string {
+	return fmt.Sprintf("Body matches '%s'", e.Pattern)
+}
+
+// SortedExpectation validates array on path is ordered by element field.
+// Numbers and strings are supported, equal neighbours are allowed.
+type SortedExpectation struct {
+	Path       string
+	By         string
+	Descending bool
+}
+
+func (e SortedExpectation) check(resp *Response) error {
+	body, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("Can't parse response body to Map. %s", err)
+	}
+
+	value, err := GetByPath(body, e.Path)
+	if err != nil {
+		return err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("Value on path [%s] is not an array: %v", e.Path, value)
+	}
+
+	var prev interface{}
+	for i, item := range items {
+		current := item
+		if e.By != "" {
+			if current, err = GetByPath(item, e.By); err != nil {
+				return fmt.Errorf("Can't get [%s] of element #%d on path [%s]. %s", e.By, i, e.Path, err)
+			}
+		}
+
+		if i > 0 {
+			c, err := compareValues(prev, current)
+			if err != nil {
+				return err
+			}
+
+			if (c > 0 && !e.Descending) || (c < 0 && e.Descending) {
+				return fmt.Errorf("Array on path [%s] is not sorted %s: element #%d (%v) is followed by element #%d (%v)",
+					e.Path, e.order(), i-1, prev, i, current)
+			}
+		}
+
+		prev = current
+	}
+
+	return nil
+}
+
+func (e SortedExpectation) order() string {
+	order := "ascending"
+	if e.Descending {
+		order = "descending"
+	}
+
+	if e.By != "" {
+		order += " by [" + e.By + "]"
+	}
+
+	return order
+}
+
+func (e SortedExpectation) desc() string {
+	return fmt.Sprintf("Array '%s' is sorted %s", e.Path, e.order())
+}
+
+// UniqueExpectation validates array elements (or their field values) are not repeated
+type UniqueExpectation struct {
+	Path string
+	By   string
+}
+
+func (e UniqueExpectation) check(resp *Response) error {
+	body, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("Can't parse response body to Map. %s", err)
+	}
+
+	value, err := GetByPath(body, e.Path)
+	if err != nil {
+		return err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("Value on path [%s] is not an array: %v", e.Path, value)
+	}
+
+	seen := make(map[string]int, len(items))
+	for i, item := range items {
+		current := item
+		if e.By != "" {
+			if current, err = GetByPath(item, e.By); err != nil {
+				return fmt.Errorf("Can't get [%s] of element #%d on path [%s]. %s", e.By, i, e.Path, err)
+			}
+		}
+
+		// objects and arrays are compared by content, json keeps map keys sorted
+		key, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+
+		if first, ok := seen[string(key)]; ok {
+			return fmt.Errorf("Array on path [%s] has duplicate %s: %s (elements #%d and #%d)", e.Path, e.subject(), key, first, i)
+		}
+
+		seen[string(key)] = i
+	}
+
+	return nil
+}
+
+func (e UniqueExpectation) subject() string {
+	if e.By != "" {
+		return "[" + e.By + "]"
+	}
+
+	return "element"
+}
+
+func (e UniqueExpectation) desc() string {
+	if e.By != "" {
+		return fmt.Sprintf("Array '%s' has unique [%s]", e.Path, e.By)
+	}
+
+	return fmt.Sprintf("Array '%s' has unique elements", e.Path)
+}
+
+// JSONTypeExpectation validates all values on path are of the JSON type: number, string, boolean, null, array or object
+type JSONTypeExpectation struct {
+	Path string
+	Type string
+}
+
+func (e JSONTypeExpectation) check(resp *Response) error {
+	body, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("Can't parse response body to Map. %s", err)
+	}
+
+	values := Search(body, e.Path)
+	if len(values) == 0 {
+		return fmt.Errorf("%s expected %s, not found", e.Path, e.Type)
+	}
+
+	for _, value := range values {
+		if actual := jsonType(value); actual != e.Type {
+			return fmt.Errorf("%s expected %s, got %s", e.Path, e.Type, actual)
+		}
+	}
+
+	return nil
+}
+
+func (e JSONTypeExpectation) desc() string {
+	return fmt.Sprintf("Value on path '%s' is %s", e.Path, e.Type)
+}
+
+// jsonType returns name of JSON type of the parsed value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+const (
+	matchAny  = "any"
+	matchAll  = "all"
+	matchNone = "none"
+)
+
+// ArrayMatchExpectation validates number of array elements matching the predicate: any, all or none of them
+type ArrayMatchExpectation struct {
+	Path       string
+	Quantifier string
+	// element field path -> expected value
+	Predicate map[string]interface{}
+}
+
+func (e ArrayMatchExpectation) check(resp *Response) error {
+	body, err := resp.Body()
+	if err != nil {
+		return fmt.Errorf("Can't parse response body to Map. %s", err)
+	}
+
+	value, err := GetByPath(body, e.Path)
+	if err != nil {
+		return err
+	}
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("Value on path [%s] is not an array: %v", e.Path, value)
+	}
+
+	matched := 0
+	for _, item := range items {
+		if e.matches(item) {
+			matched++
+		}
+	}
+
+	switch {
+	case e.Quantifier == matchAny && matched == 0,
+		e.Quantifier == matchAll && matched != len(items),
+		e.Quantifier == matchNone && matched != 0:
+		return fmt.Errorf("Expected %s elements of array on path [%s] to match (%s), %d of %d matched",
+			e.Quantifier, e.Path, e.predicate(), matched, len(items))
+	}
+
+	return nil
+}
+
+func (e ArrayMatchExpectation) matches(item interface{}) bool {
+	for path, expected := range e.Predicate {
+		if SearchByPath(item, expected, path) != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e ArrayMatchExpectation) predicate() string {
+	conditions := make([]string, 0, len(e.Predicate))
+	for path, expected := range e.Predicate {
+		conditions = append(conditions, fmt.Sprintf("%s == %v", path, expected))
+	}
+	sort.Strings(conditions)
+
+	return strings.Join(conditions, ", ")
+}
+
+func (e ArrayMatchExpectation) desc() string {
+	return fmt.Sprintf("Array '%s' has %s elements matching (%s)", e.Path, e.Quantifier, e.predicate())
+}
+
+// compareValues compares two numbers or two strings, returns negative value if a < b, zero if equal, positive otherwise
+func compareValues(a interface{}, b interface{}) (int, error) {
+	switch typedA := a.(type) {
+	case float64:
+		if typedB, ok := b.(float64); ok {
+			switch {
+			case typedA < typedB:
+				return -1, nil
+			case typedA > typedB:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case string:
+		if typedB, ok := b.(string); ok {
+			return strings.Compare(typedA, typedB), nil
+		}
+	}
+
+	return 0, fmt.Errorf("Can't compare values %v (%T) and %v (%T)", a, a, b, b)
+}
+
+// comparisonOps checks result of compareValues
+var comparisonOps = map[string]func(c int) bool{
+	"==": func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+}
+
+// FieldComparisonExpectation validates relation of two fields of the same response body
+type FieldComparisonExpectation struct {
+	FieldComparison
+}
+
+func (e FieldComparisonExpectation) check(resp *Response) error {
+	body, err := resp.Body() // cached
+	if err != nil {
+		return errors.New("Can't parse response body. " + err.Error())
+	}
+
+	left, err := GetByPath(body, e.Left)
+	if err != nil {
+		return err
+	}
+
+	right, err := GetByPath(body, e.Right)
+	if err != nil {
+		return err
+	}
+
+	c, err := compareValues(left, right)
+	if err != nil {
+		// objects, arrays, booleans and nulls could be checked for equality only
+		if e.Op != "==" && e.Op != "!=" {
+			return err
+		}
+
+		c = 1
+		if reflect.DeepEqual(left, right) {
+			c = 0
+		}
+	}
+
+	if !comparisonOps[e.Op](c) {
+		return fmt.Errorf("Expected %s %s %s, but %s is %s and %s is %s", e.Left, e.Op, e.Right, e.Left, fmtValue(left), e.Right, fmtValue(right))
+	}
+
+	return nil
+}
+
+func (e FieldComparisonExpectation) desc() string {
+	return fmt.Sprintf("Field %s %s %s", e.Left, e.Op, e.Right)
+}
+
+// fmtValue formats value of response body as JSON
+func fmtValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(data)
+}
+
+// ValidJSONExpectation validates response body is well-formed JSON regardless of its content
+type ValidJSONExpectation struct {
+}
+
+func (e ValidJSONExpectation) check(resp *Response) error {
+	if len(bytes.TrimSpace(resp.body)) == 0 {
+		return errors.New("Body is not valid JSON: body is empty")
+	}
+
+	var v interface{}
+	err := json.Unmarshal(resp.body, &v)
+	if syntaxErr, ok := err.(*json.SyntaxError); ok {
+		// offset points after the invalid byte
+		line, column := textPosition(resp.body, syntaxErr.Offset-1)
+		return fmt.Errorf("Body is not valid JSON: %s at line %d, column %d", err, line, column)
+	}
+	if err != nil {
+		return fmt.Errorf("Body is not valid JSON: %s", err)
+	}
+
+	return nil
+}
+
+func (e ValidJSONExpectation) desc() string {
+	return "Body is valid JSON"
+}
+
+// ValidXMLExpectation validates response body is well-formed XML regardless of its content
+type ValidXMLExpectation struct {
+}
+
+func (e ValidXMLExpectation) check(resp *Response) error {
+	decoder := xml.NewDecoder(bytes.NewReader(resp.body))
+	decoder.Strict = true
+
+	elements := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("Body is not valid XML: %s", err)
+		}
+
+		if _, ok := token.(xml.StartElement); ok {
+			elements++
+		}
+	}
+
+	if elements == 0 {
+		return errors.New("Body is not valid XML: no root element")
+	}
+
+	return nil
+}
+
+func (e ValidXMLExpectation) desc() string {
+	return "Body is valid XML"
+}
+
+// textPosition returns line and column (both starting from 1) of the byte offset
+func textPosition(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	line, column := 1, 1
+	for _, b := range data[:offset] {
+		if b == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+
+	return line, column
+}
+
+// StatusTextExpectation validates reason phrase of the response status line.
+// Comparison is case insensitive since proxies may rewrite reason phrases.
+type StatusTextExpectation struct {
+	statusText string
+}
+
+func (e StatusTextExpectation) check(resp *Response) error {
+	actual := strings.TrimSpace(strings.TrimPrefix(resp.http.Status, strconv.Itoa(resp.http.StatusCode)))
+
+	if actual == "" {
+		return fmt.Errorf("Unexpected Status Text. Expected: \"%s\", but reason phrase is not sent", e.statusText)
+	}
+
+	if !strings.EqualFold(actual, strings.TrimSpace(e.statusText)) {
+		return fmt.Errorf("Unexpected Status Text. Expected: \"%s\", Actual: \"%s\"", e.statusText, actual)
+	}
+
+	return nil
+}
+
+func (e StatusTextExpectation) desc() string {
+	return fmt.Sprintf("Status text is '%s'", e.statusText)
+}
+
+// BodySchemaExpectation validates response body against schema.
+// Content-Type header is used to identify either json schema or xsd is applied.
+type BodySchemaExpectation struct {
+	schema      []byte
+	displayName string
+}
+
+func (e BodySchemaExpectation) check(resp *Response) error {
+	contentType, _, _ := mime.ParseMediaType(resp.http.Header.Get("content-type"))
+
+	if isJSONMediaType(contentType) {
+		return e.checkJSON(resp)
+	}
+
+	return fmt.Errorf("Unsupported content type: %s", contentType)
+}
+
+func (e BodySchemaExpectation) desc() string {
+	tmpl := "BodyPath matches the schema"
+	if e.displayName == "" {
+		return tmpl
+	}
+
+	return fmt.Sprintf(tmpl+" (%s)", e.displayName)
+}
+
+func (e BodySchemaExpectation) checkJSON(resp *Response) error {
+	schemaLoader := gojsonschema.NewBytesLoader(e.schema)
+	documentLoader := gojsonschema.NewStringLoader(string(resp.body))
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return fmt.Errorf("failed to load schema file: %s", err)
+	}
+
+	if !result.Valid() {
+		msg := "Unexpected Body Schema:"
+		for _, desc := range result.Errors() {
+			msg = fmt.Sprintf(msg+"\n\t%s", desc)
+		}
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+// BodyExpectation validates that expected object is presented in the response.
+// The expected body reflect required part of the response object.
+type BodyExpectation struct {
+	Strict       bool
+	ExpectedBody interface{}
+	// numbers and strings holding them are equal, e.g. "5" and 5
+	CoerceNumbers bool
+}
+
+func (e BodyExpectation) check(resp *Response) error {
+
+	actualBody, err := resp.Body() // cached
+	if err != nil {
+		str := "Can't parse response body."
+		str += " " + err.Error()
+
+		return errors.New(str)
+	}
+
+	expectedBody := e.ExpectedBody
+	if e.CoerceNumbers {
+		expectedBody, actualBody = coerceNumbers(expectedBody), coerceNumbers(actualBody)
+	}
+
+	matcher := NewBodyMatcher{Strict: e.Strict, ExpectedBody: expectedBody}
+	return matcher.check(actualBody)
+}
+
+func (e BodyExpectation) desc() string {
+	return fmt.Sprint("Expected body's structure / values")
+}
+
+// BodyOneOfExpectation validates body exactly matches one of the expected bodies
+type BodyOneOfExpectation struct {
+	ExpectedBodies []interface{}
+	CoerceNumbers  bool
+}
+
+func (e BodyOneOfExpectation) check(resp *Response) error {
+
+	actualBody, err := resp.Body() // cached
+	if err != nil {
+		return errors.New("Can't parse response body. " + err.Error())
+	}
+
+	if e.CoerceNumbers {
+		actualBody = coerceNumbers(actualBody)
+	}
+
+	mismatches := make([]string, 0, len(e.ExpectedBodies))
+	for i, expected := range e.ExpectedBodies {
+		if e.CoerceNumbers {
+			expected = coerceNumbers(expected)
+		}
+
+		matcher := NewBodyMatcher{Strict: true, ExpectedBody: expected}
+
+		err := matcher.check(actualBody)
+		if err == nil {
+			return nil
+		}
+
+		mismatches = append(mismatches, fmt.Sprintf("Option #%d: %s", i+1, err))
+	}
+
+	return fmt.Errorf("The body matches none of %d expected bodies:\n%s", len(e.ExpectedBodies), strings.Join(mismatches, "\n"))
+}
+
+func (e BodyOneOfExpectation) desc() string {
+	return fmt.Sprintf("Body matches one of %d expected bodies", len(e.ExpectedBodies))
+}
+
+// BodyPathExpectation validates values under a certain path in a body.
+// Applies to json and xml.
+type BodyPathExpectation struct {
+	pathExpectations map[string]interface{}
+	coerceNumbers    bool
+}
+
+func (e BodyPathExpectation) check(resp *Response) error {
+
+	checkPath := checkExpectedPath
+	if e.coerceNumbers {
+		checkPath = func(m interface{}, pathItem interface{}) string {
+			return checkExpectedPath(coerceNumbers(m), pathItem)
+		}
+	}
+
+	for pathStr, expectedValue := range e.pathExpectations {
+
+		if e.coerceNumbers {
+			expectedValue = coerceNumbers(expectedValue)
+		}
+
+		err := responseBodyPathCheck(resp, bodyExpectationItem{Path: pathStr, ExpectedValue: expectedValue}, checkPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e BodyPathExpectation) desc() string {
+	return fmt.Sprintf("Expected body's structure / values (%d checks)", len(e.pathExpectations))
+}
+
+type bodyExpectationItem struct {
+	Path          string
+	ExpectedValue interface{}
+}
+
+func checkExpectedPath(m interface{}, pathItem interface{}) string {
+
+	if expectationItem, ok := pathItem.(bodyExpectationItem); ok {
+
+		err := SearchByPath(m, expectationItem.ExpectedValue, expectationItem.Path)
+		if err != nil {
+			return err.Error()
+		}
+
+		return ""
+	}
+
+	return fmt.Sprintf("Path Item: %v is invalid for expectation check", pathItem)
+}
+
+// HeaderExpectation validates one header in a response.
+type HeaderExpectation struct {
+	Name        string
+	Value       string
+	ValueParser func(string) string
+}
+
+func (e HeaderExpectation) check(resp *Response) error {
+	value := resp.http.Header.Get(e.Name)
+	if e.ValueParser != nil {
+		value = e.ValueParser(value)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fmt.Errorf("Missing header. Expected \"%s: %s\"", e.Name, e.Value)
+	}
+	if e.Value != "" && e.Value != value {
+		return fmt.Errorf("Unexpected header. Expected \"%s: %s\". Actual \"%s: %s\"", e.Name, e.Value, e.Name, value)
+	}
+	return nil
+}
+
+func (e HeaderExpectation) desc() string {
+	if e.Value == "" {
+		return fmt.Sprintf("Header '%s' is present", e.Name)
+	}
+	return fmt.Sprintf("Header '%s' matches expected value '%s", e.Name, e.Value)
+}
+
+// TrailerExpectation validates one trailer sent after the response body, e.g. 'grpc-status'.
+type TrailerExpectation struct {
+	Name  string
+	Value string
+}
+
+func (e TrailerExpectation) check(resp *Response) error {
+	values := resp.http.Trailer.Values(e.Name)
+	if len(values) == 0 {
+		return fmt.Errorf("Missing trailer. Expected \"%s: %s\"", e.Name, e.Value)
+	}
+
+	value := strings.TrimSpace(strings.Join(values, ", "))
+	if e.Value != "" && e.Value != value {
+		return fmt.Errorf("Unexpected trailer. Expected \"%s: %s\". Actual \"%s: %s\"", e.Name, e.Value, e.Name, value)
+	}
+	return nil
+}
+
+func (e TrailerExpectation) desc() string {
+	if e.Value == "" {
+		return fmt.Sprintf("Trailer '%s' is present", e.Name)
+	}
+	return fmt.Sprintf("Trailer '%s' matches expected value '%s'", e.Name, e.Value)
+}
+
+// HeaderValuesExpectation validates all occurrences of a header in order, e.g. several 'Set-Cookie'.
+// Number of occurrences must be equal to number of expected values, every value is a prefix of the occurrence.
+type HeaderValuesExpectation struct {
+	Name   string
+	Values []string
+}
+
+func (e HeaderValuesExpectation) check(resp *Response) error {
+	actual := resp.http.Header.Values(e.Name)
+	if len(actual) != len(e.Values) {
+		return fmt.Errorf("Expected %d occurrences of header '%s', got %d: %s", len(e.Values), e.Name, len(actual), formatHeaderValues(actual))
+	}
+
+	for i, expected := range e.Values {
+		if !strings.HasPrefix(actual[i], expected) {
+			return fmt.Errorf("Unexpected occurrence #%d of header '%s'. Expected to start with \"%s\", got \"%s\". All occurrences: %s", i+1, e.Name, expected, actual[i], formatHeaderValues(actual))
+		}
+	}
+
+	return nil
+}
+
+func (e HeaderValuesExpectation) desc() string {
+	return fmt.Sprintf("Header '%s' occurrences in order: %s", e.Name, formatHeaderValues(e.Values))
+}
+
+// HeaderCountExpectation validates number of occurrences of a header.
+type HeaderCountExpectation struct {
+	Name  string
+	Count int
+}
+
+func (e HeaderCountExpectation) check(resp *Response) error {
+	actual := resp.http.Header.Values(e.Name)
+	if len(actual) != e.Count {
+		return fmt.Errorf("Expected %d occurrences of header '%s', got %d: %s", e.Count, e.Name, len(actual), formatHeaderValues(actual))
+	}
+
+	return nil
+}
+
+func (e HeaderCountExpectation) desc() string {
+	return fmt.Sprintf("Header '%s' occurs %d times", e.Name, e.Count)
+}
+
+func formatHeaderValues(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, fmt.Sprintf("%q", value))
+	}
+
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// LocationAssert describes expected 'Location' header of redirect response, all specified matchers must match
+type LocationAssert struct {
+	Equals  string `json:"equals"`
+	Prefix  string `json:"prefix"`
+	Matches string `json:"matches"`
+}
+
+// LocationExpectation validates 'Location' header of redirect response.
+type LocationExpectation struct {
+	LocationAssert
+	// compiled Matches
+	Pattern *regexp.Regexp
+}
+
+func (e LocationExpectation) check(resp *Response) error {
+	location := resp.http.Header.Get("Location")
+	if location == "" {
+		return fmt.Errorf("Missing 'Location' header. Response status: %s", resp.http.Status)
+	}
+
+	if e.Equals != "" && location != e.Equals {
+		return fmt.Errorf("Unexpected 'Location'. Expected: %s, actual: %s", e.Equals, location)
+	}
+
+	if e.Prefix != "" && !strings.HasPrefix(location, e.Prefix) {
+		return fmt.Errorf("Unexpected 'Location'. Expected to start with: %s, actual: %s", e.Prefix, location)
+	}
+
+	if e.Pattern != nil && !e.Pattern.MatchString(location) {
+		return fmt.Errorf("Unexpected 'Location'. Expected to match: %s, actual: %s", e.Pattern, location)
+	}
+
+	return nil
+}
+
+func (e LocationExpectation) desc() string {
+	var checks []string
+	if e.Equals != "" {
+		checks = append(checks, "equals "+e.Equals)
+	}
+	if e.Prefix != "" {
+		checks = append(checks, "starts with "+e.Prefix)
+	}
+	if e.Matches != "" {
+		checks = append(checks, "matches "+e.Matches)
+	}
+	if len(checks) == 0 {
+		return "Header 'Location' is present"
+	}
+
+	return "Header 'Location' " + strings.Join(checks, ", ")
+}
+
+// HeadersAbsentExpectation validates headers are not present in a response.
+type HeadersAbsentExpectation struct {
+	Names []string
+}
+
+func (e HeadersAbsentExpectation) check(resp *Response) error {
+	var present []string
+	for _, name := range e.Names {
+		for _, value := range resp.http.Header.Values(name) {
+			present = append(present, fmt.Sprintf("\"%s: %s\"", http.CanonicalHeaderKey(name), value))
+		}
+	}
+
+	if len(present) > 0 {
+		return fmt.Errorf("Unexpected headers present: %s", strings.Join(present, ", "))
+	}
+	return nil
+}
+
+func (e HeadersAbsentExpectation) desc() string {
+	return fmt.Sprintf("Headers %s are absent", strings.Join(e.Names, ", "))
+}
+
+// headers revealing server software and its version
+var fingerprintHeaders = []string{"Server", "X-Powered-By", "X-AspNet-Version", "X-AspNetMvc-Version"}
+
+// securityHeadersExpectations is a bundle of common security checks of response headers
+func securityHeadersExpectations() []ResponseExpectation {
+	return []ResponseExpectation{
+		HeadersAbsentExpectation{Names: fingerprintHeaders},
+		HeaderExpectation{Name: "Strict-Transport-Security"},
+		HeaderExpectation{Name: "X-Content-Type-Options", Value: "nosniff"},
+	}
+}
+
+// ContentTypeExpectation validates media type returned in the Content-Type header.
+// Parameters are excluded from matching media type, but could be asserted separately.
+// E.g. "application/json;charset=utf-8" header matches "application/json" media type,
+// "application/json; charset=UTF-8" value matches both media type and charset.
+// Media types and parameter values are compared case insensitive, order of parameters does not matter.
+type ContentTypeExpectation struct {
+	Value  string
+	Params map[string]string
+}
+
+func (e ContentTypeExpectation) check(resp *Response) error {
+	header := strings.TrimSpace(resp.http.Header.Get("content-type"))
+	if header == "" {
+		return fmt.Errorf("Missing header. Expected \"content-type: %s\"", e.expectedValue())
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header)
+	if err != nil {
+		return fmt.Errorf("Invalid header \"content-type: %s\": %s", header, err)
+	}
+
+	expectedType, expectedParams := e.expected()
+	if expectedType != "" && expectedType != mediaType {
+		return fmt.Errorf("Unexpected header. Expected \"content-type: %s\". Actual \"content-type: %s\"", expectedType, mediaType)
+	}
+
+	names := make([]string, 0, len(expectedParams))
+	for name := range expectedParams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		actual, ok := params[name]
+		if !ok {
+			return fmt.Errorf("Missing content-type parameter '%s'. Expected: %s, Actual header: \"%s\"", name, expectedParams[name], header)
+		}
+		if !strings.EqualFold(actual, expectedParams[name]) {
+			return fmt.Errorf("Unexpected content-type parameter '%s'. Expected: %s, Actual: %s", name, expectedParams[name], actual)
+		}
+	}
+
+	return nil
+}
+
+// expected returns lower cased media type and parameters of expected value merged with explicit parameters
+func (e ContentTypeExpectation) expected() (string, map[string]string) {
+	params := make(map[string]string)
+
+	mediaType := strings.ToLower(strings.TrimSpace(e.Value))
+	if mediaType != "" {
+		if parsedType, parsedParams, err := mime.ParseMediaType(e.Value); err == nil {
+			mediaType = parsedType
+			params = parsedParams
+		}
+	}
+
+	for name, value := range e.Params {
+		params[strings.ToLower(name)] = value
+	}
+
+	return mediaType, params
+}
+
+func (e ContentTypeExpectation) expectedValue() string {
+	mediaType, params := e.expected()
+	if mediaType == "" {
+		mediaType = "*/*"
+	}
+
+	return mime.FormatMediaType(mediaType, params)
+}
+
+func (e ContentTypeExpectation) desc() string {
+	if e.Value == "" {
+		return fmt.Sprintf("Content Type matches '%s'", e.expectedValue())
+	}
+
+	return fmt.Sprintf("Content Type is '%s'", e.expectedValue())
+}
+
+// NegotiationExpectation validates media type of the response is acceptable
+// according to 'Accept' header of the request.
+type NegotiationExpectation struct {
+}
+
+func (e NegotiationExpectation) check(resp *Response) error {
+	if resp.http.Request == nil || resp.http.Request.Header.Get("Accept") == "" {
+		return errors.New("Request has no 'Accept' header to negotiate content type")
+	}
+
+	accept := resp.http.Request.Header.Get("Accept")
+	contentType, _, err := mime.ParseMediaType(resp.http.Header.Get("content-type"))
+	if err != nil {
+		return fmt.Errorf("Content type is not negotiated. Invalid response Content-Type: %s", err)
+	}
+
+	if !acceptsMediaType(accept, contentType) {
+		return fmt.Errorf("Content type is not negotiated. Expected one of \"%s\", Actual \"%s\"", accept, contentType)
+	}
+
+	return nil
+}
+
+func (e NegotiationExpectation) desc() string {
+	return "Content Type matches requested 'Accept' header"
+}
+
+// acceptsMediaType checks media type matches any media range from 'Accept' header value.
+// Media ranges with zero quality are considered as not acceptable.
+func acceptsMediaType(accept string, mediaType string) bool {
+	for _, item := range strings.Split(accept, ",") {
+		mediaRange, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+
+		if q, ok := params["q"]; ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+
+		if mediaRange == "*/*" || mediaRange == mediaType {
+			return true
+		}
+
+		if strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NotModifiedExpectation validates response to conditional request is '304 Not Modified' without body.
+// ETag of the response (if any) has to match the one sent in 'If-None-Match'.
+type NotModifiedExpectation struct {
+}
+
+func (e NotModifiedExpectation) check(resp *Response) error {
+	if resp.http.StatusCode != http.StatusNotModified {
+		return fmt.Errorf("Unexpected Status Code. Expected: %d, Actual: %d", http.StatusNotModified, resp.http.StatusCode)
+	}
+
+	if len(resp.body) > 0 {
+		return fmt.Errorf("Unexpected body of Not Modified response, %d bytes", len(resp.body))
+	}
+
+	etag := resp.http.Header.Get("ETag")
+	if etag == "" || resp.http.Request == nil {
+		return nil
+	}
+
+	sent := resp.http.Request.Header.Get("If-None-Match")
+	if sent != "" && strings.TrimPrefix(sent, "W/") != strings.TrimPrefix(etag, "W/") {
+		return fmt.Errorf("ETag of Not Modified response %s does not match requested %s", etag, sent)
+	}
+
+	return nil
+}
+
+func (e NotModifiedExpectation) desc() string {
+	return "Not Modified (304)"
+}
+
+// BodySizeExpectation validates size of the response body in bytes.
+// Body size is compared with 'Content-Length' header only if the header is present,
+// e.g. chunked responses are not checked.
+type BodySizeExpectation struct {
+	Bytes              *int
+	MatchContentLength bool
+}
+
+func (e BodySizeExpectation) check(resp *Response) error {
+	size := resp.size()
+
+	if e.Bytes != nil && size != *e.Bytes {
+		return fmt.Errorf("body %d bytes, expected %d", size, *e.Bytes)
+	}
+
+	if e.MatchContentLength && resp.http.ContentLength >= 0 && int64(size) != resp.http.ContentLength {
+		return fmt.Errorf("body %d bytes, expected %d (Content-Length)", size, resp.http.ContentLength)
+	}
+
+	return nil
+}
+
+func (e BodySizeExpectation) desc() string {
+	var parts []string
+	if e.Bytes != nil {
+		parts = append(parts, fmt.Sprintf("is %d bytes", *e.Bytes))
+	}
+
+	if e.MatchContentLength {
+		parts = append(parts, "matches Content-Length")
+	}
+
+	return "Body size " + strings.Join(parts, " and ")
+}
+
+// CookieExpectation validates cookie set by the response using 'Set-Cookie' header.
+type CookieExpectation struct {
+	Name     string
+	Expected CookieAssert
+}
+
+func (e CookieExpectation) check(resp *Response) error {
+	var cookie *http.Cookie
+	for _, c := range resp.http.Cookies() {
+		if c.Name == e.Name {
+			cookie = c
+		}
+	}
+
+	if cookie == nil {
+		return fmt.Errorf("Missing cookie '%s'", e.Name)
+	}
+
+	exp := e.Expected
+
+	if exp.Value != "" && exp.Value != cookie.Value {
+		return fmt.Errorf("cookie '%s' value expected '%s', actual '%s'", e.Name, exp.Value, cookie.Value)
+	}
+
+	if exp.ValueRegex != "" {
+		re, err := regexp.Compile(exp.ValueRegex)
+		if err != nil {
+			return fmt.Errorf("cookie '%s' value regex is invalid: %s", e.Name, err)
+		}
+
+		if !re.MatchString(cookie.Value) {
+			return fmt.Errorf("cookie '%s' value '%s' does not match '%s'", e.Name, cookie.Value, exp.ValueRegex)
+		}
+	}
+
+	if exp.HTTPOnly != nil && *exp.HTTPOnly != cookie.HttpOnly {
+		return fmt.Errorf("cookie '%s' HttpOnly expected %t", e.Name, *exp.HTTPOnly)
+	}
+
+	if exp.Secure != nil && *exp.Secure != cookie.Secure {
+		return fmt.Errorf("cookie '%s' Secure expected %t", e.Name, *exp.Secure)
+	}
+
+	if exp.SameSite != "" && !strings.EqualFold(exp.SameSite, sameSiteName(cookie.SameSite)) {
+		return fmt.Errorf("cookie '%s' SameSite expected '%s', actual '%s'", e.Name, exp.SameSite, sameSiteName(cookie.SameSite))
+	}
+
+	if exp.MaxAge != nil {
+		if cookie.MaxAge == 0 {
+			return fmt.Errorf("cookie '%s' Max-Age expected %d, actual not set", e.Name, *exp.MaxAge)
+		} // MaxAge=0 means attribute is absent
+
+		actual := cookie.MaxAge
+		if actual < 0 {
+			actual = 0
+		} // MaxAge<0 means 'Max-Age: 0' was sent
+
+		if *exp.MaxAge != actual {
+			return fmt.Errorf("cookie '%s' Max-Age expected %d, actual %d", e.Name, *exp.MaxAge, actual)
+		}
+	}
+
+	return nil
+}
+
+func (e CookieExpectation) desc() string {
+	return fmt.Sprintf("Cookie '%s' matches expected value and attributes", e.Name)
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return ""
+	}
+}
+
+// AbsentExpectation validates paths are absent in response body
+type AbsentExpectation struct {
+	paths []string
+	// field with null value is treated as absent, otherwise it is present
+	nullAsAbsent bool
+}
+
+func (e AbsentExpectation) check(resp *Response) error {
+
+	checkPath := checkAbsentPath
+	if e.nullAsAbsent {
+		checkPath = checkAbsentOrNullPath
+	}
+
+	for _, pathStr := range e.paths {
+		err := responseBodyPathCheck(resp, pathStr, checkPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e AbsentExpectation) desc() string {
+	buf := bytes.NewBufferString("")
+
+	if e.nullAsAbsent {
+		buf.WriteString("Absent or null fields:")
+	} else {
+		buf.WriteString("Absent fields:")
+	}
+	for _, path := range e.paths {
+		buf.WriteString(fmt.Sprintf("\n  - %s", path))
+	}
+
+	return buf.String()
+}
+
+type pathCheckFunc func(m interface{}, pathItem interface{}) string
+
+func responseBodyPathCheck(resp *Response, pathItem interface{}, checkPath pathCheckFunc) error {
+
+	m, err := resp.Body() // cached
+	if err != nil {
+		str := "Can't parse response body to Map." // TODO specific message for functions
+		str += " " + err.Error()
+
+		return errors.New(str)
+	}
+
+	str := checkPath(m, pathItem)
+	if str != "" {
+		return errors.New(str)
+	}
+
+	return nil
+}
+
+func checkAbsentPath(m interface{}, pathItem interface{}) string {
+
+	if pathStr, ok := pathItem.(string); ok {
+
+		searchResult := Search(m, absentPath(pathStr))
+		if len(searchResult) > 0 && allNull(searchResult) {
+			return fmt.Sprintf("Field expected to be absent is present with null value, path: %v", pathStr)
+		}
+		if len(searchResult) > 0 {
+			return fmt.Sprintf("Value expected to be absent was found: %v, path: %v", searchResult, pathStr)
+		}
+
+		return ""
+	}
+
+	return fmt.Sprintf("Path Item: %v is invalid for absence check", pathItem)
+}
+
+// checkAbsentOrNullPath is checkAbsentPath that accepts fields with null value
+func checkAbsentOrNullPath(m interface{}, pathItem interface{}) string {
+
+	if pathStr, ok := pathItem.(string); ok {
+
+		var found []interface{}
+		for _, value := range Search(m, absentPath(pathStr)) {
+			if value != nil {
+				found = append(found, value)
+			}
+		}
+
+		if len(found) > 0 {
+			return fmt.Sprintf("Value expected to be absent or null was found: %v, path: %v", found, pathStr)
+		}
+
+		return ""
+	}
+
+	return fmt.Sprintf("Path Item: %v is invalid for absence check", pathItem)
+}
+
+// absentPath accepts JSONPath root of the body, e.g. '$.user.password' is 'user.password'
+func absentPath(pathStr string) string {
+	return strings.TrimPrefix(pathStr, "$"+expectationPathSeparator)
+}
+
+func allNull(values []interface{}) bool {
+	for _, value := range values {
+		if value != nil {
+			return false
+		}
+	}
+
+	return true
+}
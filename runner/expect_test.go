@@ -0,0 +1,975 @@
+package runner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TODO .size() only counts last array not all arrays in search
+
+func TestExpectedStatusCode(t *testing.T) {
+	exp := StatusCodeExpectation{statusCode: 200}
+	err := exp.check(&Response{
+		http: &http.Response{
+			StatusCode: 400,
+		},
+		body: nil,
+	})
+
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestUnexpectedStatusCode(t *testing.T) {
+	exp := StatusCodeExpectation{statusCode: 200}
+	err := exp.check(&Response{
+		http: &http.Response{
+			StatusCode: 200,
+		},
+		body: nil,
+	})
+
+	if err != nil {
+		t.Fail()
+	}
+
+}
+
+func TestStatusTextExpectation(t *testing.T) {
+	exp := StatusTextExpectation{statusText: "Not Found"}
+	resp := func(status string) *Response {
+		return &Response{http: &http.Response{StatusCode: 404, Status: status}}
+	}
+
+	if err := exp.check(resp("404 Not Found")); err != nil {
+		t.Error(err)
+	}
+
+	if err := exp.check(resp("404 NOT FOUND")); err != nil {
+		t.Error("Reason phrase comparison should ignore case:", err)
+	}
+
+	err := exp.check(resp("404 Missing"))
+	if err == nil || err.Error() != `Unexpected Status Text. Expected: "Not Found", Actual: "Missing"` {
+		t.Error("Unexpected mismatch error:", err)
+	}
+
+	err = exp.check(resp("404"))
+	if err == nil || !strings.Contains(err.Error(), "reason phrase is not sent") {
+		t.Error("Unexpected error for omitted reason phrase:", err)
+	}
+}
+
+func TestExpectedHeader(t *testing.T) {
+	exp := HeaderExpectation{Name: "X-Test", Value: "PASS"}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"X-Test": {"PASS"}},
+		},
+		body: nil,
+	})
+
+	if err != nil {
+		t.Fail()
+	}
+}
+
+func TestUnexpectedHeader(t *testing.T) {
+	exp := HeaderExpectation{Name: "X-Test", Value: "PASS"}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"X-Test": {"FAILED"}},
+		},
+		body: nil,
+	})
+
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestSecurityHeadersLeakingServer(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Server", "nginx/1.18.0")
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{SecurityHeaders: true}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), `Unexpected headers present: "Server: nginx/1.18.0"`) {
+		t.Error("Unexpected error for leaking Server header:", trace.ErrorCause)
+	}
+}
+
+func TestSecurityHeadersPresent(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{SecurityHeaders: true, HeadersAbsent: []string{"x-debug-token"}}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Error(trace.ErrorCause)
+	}
+}
+
+func TestHeadersAbsent(t *testing.T) {
+	exp := HeadersAbsentExpectation{Names: []string{"x-powered-by", "Server"}}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"X-Powered-By": {"PHP/7.4", "Express"}},
+		},
+	})
+
+	expected := `Unexpected headers present: "X-Powered-By: PHP/7.4", "X-Powered-By: Express"`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %s, got %v", expected, err)
+	}
+}
+
+func TestExpectedContentType(t *testing.T) {
+	exp := ContentTypeExpectation{Value: "application/json"}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: nil,
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestExpectedContentTypeIgnoreEncoding(t *testing.T) {
+	exp := ContentTypeExpectation{Value: "application/json"}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json; charset=utf-8"}},
+		},
+		body: nil,
+	})
+
+	if err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestUnexpectedContentType(t *testing.T) {
+	exp := ContentTypeExpectation{Value: "application/json"}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"text/html"}},
+		},
+		body: nil,
+	})
+
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestContentTypeMediaTypeMatch(t *testing.T) {
+	resp := &Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"Application/JSON; Charset=UTF-8; version=2"}},
+		},
+	}
+
+	for _, value := range []string{"application/json", "application/json; version=2; charset=utf-8"} {
+		if err := (ContentTypeExpectation{Value: value}).check(resp); err != nil {
+			t.Errorf("'%s' expected to match: %s", value, err)
+		}
+	}
+
+	err := ContentTypeExpectation{Value: "application/json; charset=iso-8859-1"}.check(resp)
+	if err == nil || err.Error() != "Unexpected content-type parameter 'charset'. Expected: iso-8859-1, Actual: UTF-8" {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestContentTypeCharset(t *testing.T) {
+	exp := ContentTypeExpectation{Params: map[string]string{"charset": "utf-8"}}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"text/plain; charset=utf-8"}},
+		},
+	})
+	if err != nil {
+		t.Error(err)
+	}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"text/plain"}},
+		},
+	})
+	if err == nil || !strings.HasPrefix(err.Error(), "Missing content-type parameter 'charset'") {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestBodyExpectationBool(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"flag": true
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"flag":true}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyExpectationInt(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"len": 2
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"len":2}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyExpectationSize(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"items.size()": 0
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"items":[]}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyExpectationSearchBool(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"flag": true
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"flag":true}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyExpectationSearchInt(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"len": 2
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"len":2}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyExpectationSearchArray(t *testing.T) {
+	m, err := jsonAsMap(`{
+		"items": "ONE"
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	exp := BodyPathExpectation{pathExpectations: m}
+
+	err = exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"items":["ONE", "TWO"]}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheckAbsentPath(t *testing.T) {
+	m, err := jsonAsMap(`{
+			"items": {
+				"test": 1
+			}
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	errorMsg := checkAbsentPath(m, "items.test")
+
+	if errorMsg == "" {
+		t.Error(
+			"For", "items.test",
+			"expected", "error",
+			"got", "empty message",
+		)
+	}
+}
+
+func TestCheckAbsentNotExactPath(t *testing.T) {
+	m, err := jsonAsMap(`{
+			"items": [
+				{"test": 1},
+				{"test": 2}
+			]
+	 	}`)
+	if err != nil {
+		t.Error(err)
+	}
+
+	errorMsg := checkAbsentPath(m, "items.test")
+
+	if errorMsg == "" {
+		t.Error(
+			"For", "items.test",
+			"expected", "error",
+			"got", "empty message",
+		)
+	}
+}
+
+func TestAbsentExpectationNull(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		nullAsAbsent bool
+		fail         string
+	}{
+		{"absent", `{"user": {"name": "a"}}`, false, ""},
+		{"absent with null allowed", `{"user": {"name": "a"}}`, true, ""},
+		{"present null", `{"user": {"password": null}}`, false, "Field expected to be absent is present with null value, path: $.user.password"},
+		{"present null allowed", `{"user": {"password": null}}`, true, ""},
+		{"present value", `{"user": {"password": "secret"}}`, false, "Value expected to be absent was found: [secret], path: $.user.password"},
+		{"present value with null allowed", `{"user": {"password": "secret"}}`, true, "Value expected to be absent or null was found: [secret], path: $.user.password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &Response{http: &http.Response{Header: http.Header{"Content-Type": {"application/json"}}}, body: []byte(tt.body)}
+			exp := AbsentExpectation{paths: []string{"$.user.password"}, nullAsAbsent: tt.nullAsAbsent}
+
+			err := exp.check(resp)
+
+			if tt.fail == "" && err != nil {
+				t.Errorf("Expected to pass, got %s", err)
+			}
+			if tt.fail != "" && (err == nil || err.Error() != tt.fail) {
+				t.Errorf("Expected %s, got %v", tt.fail, err)
+			}
+		})
+	}
+}
+
+// testResponse is a response with status 200 for expectation checks
+func testResponse(header http.Header, body string) *Response {
+	return &Response{
+		http: &http.Response{StatusCode: http.StatusOK, Header: header},
+		body: []byte(body),
+	}
+}
+
+func TestCookieExpectationValue(t *testing.T) {
+	resp := testResponse(http.Header{"Set-Cookie": {"session=abc123; Path=/", "theme=dark"}}, "")
+
+	t.Run("exact value", func(t *testing.T) {
+		exp := CookieExpectation{Name: "session", Expected: CookieAssert{Value: "abc123"}}
+		if err := exp.check(resp); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("regex value", func(t *testing.T) {
+		exp := CookieExpectation{Name: "session", Expected: CookieAssert{ValueRegex: "^[a-z]+[0-9]+$"}}
+		if err := exp.check(resp); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unexpected value", func(t *testing.T) {
+		exp := CookieExpectation{Name: "theme", Expected: CookieAssert{Value: "light"}}
+		if err := exp.check(resp); err == nil {
+			t.Error("Expected value mismatch error")
+		}
+	})
+
+	t.Run("missing cookie", func(t *testing.T) {
+		exp := CookieExpectation{Name: "token", Expected: CookieAssert{}}
+		err := exp.check(resp)
+		if err == nil || !strings.Contains(err.Error(), "Missing cookie 'token'") {
+			t.Error("Expected missing cookie error, got:", err)
+		}
+	})
+}
+
+func TestCookieExpectationAttributes(t *testing.T) {
+	resp := testResponse(http.Header{"Set-Cookie": {"session=abc; Max-Age=3600; Secure; SameSite=Strict"}}, "")
+
+	yes := true
+	maxAge := 3600
+
+	exp := CookieExpectation{Name: "session", Expected: CookieAssert{Secure: &yes, SameSite: "strict", MaxAge: &maxAge}}
+	if err := exp.check(resp); err != nil {
+		t.Error(err)
+	}
+
+	exp = CookieExpectation{Name: "session", Expected: CookieAssert{HTTPOnly: &yes}}
+	err := exp.check(resp)
+	if err == nil || err.Error() != "cookie 'session' HttpOnly expected true" {
+		t.Error("Unexpected HttpOnly check result:", err)
+	}
+
+	otherMaxAge := 60
+	exp = CookieExpectation{Name: "session", Expected: CookieAssert{MaxAge: &otherMaxAge}}
+	err = exp.check(resp)
+	if err == nil || !strings.Contains(err.Error(), "Max-Age expected 60, actual 3600") {
+		t.Error("Unexpected Max-Age check result:", err)
+	}
+}
+
+func TestBodySizeExpectation(t *testing.T) {
+	size := 4
+
+	t.Run("exact match", func(t *testing.T) {
+		resp := &Response{http: &http.Response{ContentLength: 4}, body: []byte("abcd")}
+
+		exp := BodySizeExpectation{Bytes: &size, MatchContentLength: true}
+		if err := exp.check(resp); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("size mismatch", func(t *testing.T) {
+		resp := &Response{http: &http.Response{ContentLength: -1}, body: []byte("abc")}
+
+		err := BodySizeExpectation{Bytes: &size}.check(resp)
+		if err == nil || err.Error() != "body 3 bytes, expected 4" {
+			t.Error("Expected size mismatch error, got:", err)
+		}
+	})
+
+	t.Run("content length mismatch", func(t *testing.T) {
+		resp := &Response{http: &http.Response{ContentLength: 2048}, body: make([]byte, 1024)}
+
+		err := BodySizeExpectation{MatchContentLength: true}.check(resp)
+		if err == nil || !strings.Contains(err.Error(), "body 1024 bytes, expected 2048") {
+			t.Error("Expected Content-Length mismatch error, got:", err)
+		}
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		resp := &Response{http: &http.Response{ContentLength: -1}, body: make([]byte, 1024)}
+
+		if err := (BodySizeExpectation{MatchContentLength: true}).check(resp); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSortedExpectation(t *testing.T) {
+	t.Run("sorted", func(t *testing.T) {
+		body := testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [{"name": "a", "age": 40}, {"name": "b", "age": 30}, {"name": "b", "age": 20}]}`)
+
+		if err := (SortedExpectation{Path: "items", By: "name"}).check(body); err != nil {
+			t.Error(err)
+		}
+		if err := (SortedExpectation{Path: "items", By: "age", Descending: true}).check(body); err != nil {
+			t.Error(err)
+		}
+		if err := (SortedExpectation{Path: ""}).check(testResponse(http.Header{"Content-Type": {"application/json"}}, `[1, 2, 2, 10]`)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unsorted", func(t *testing.T) {
+		body := testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [{"name": "a"}, {"name": "c"}, {"name": "b"}]}`)
+
+		err := SortedExpectation{Path: "items", By: "name"}.check(body)
+		if err == nil || err.Error() != "Array on path [items] is not sorted ascending by [name]: element #1 (c) is followed by element #2 (b)" {
+			t.Error("Unexpected error:", err)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		if err := (SortedExpectation{Path: "items", By: "name"}).check(testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": []}`)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("not comparable", func(t *testing.T) {
+		err := SortedExpectation{Path: "items"}.check(testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [1, "a"]}`))
+		if err == nil || !strings.HasPrefix(err.Error(), "Can't compare values") {
+			t.Error("Unexpected error:", err)
+		}
+	})
+}
+
+func TestUniqueExpectation(t *testing.T) {
+	t.Run("unique", func(t *testing.T) {
+		body := testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [{"id": 1, "owner": {"name": "a"}}, {"id": 2, "owner": {"name": "b"}}], "tags": ["a", "b", 1]}`)
+
+		if err := (UniqueExpectation{Path: "items", By: "id"}).check(body); err != nil {
+			t.Error(err)
+		}
+		if err := (UniqueExpectation{Path: "items", By: "owner"}).check(body); err != nil {
+			t.Error(err)
+		}
+		if err := (UniqueExpectation{Path: "tags"}).check(body); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		body := testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [{"id": 1}, {"id": 2}, {"id": 3}, {"id": 2}]}`)
+
+		err := UniqueExpectation{Path: "items", By: "id"}.check(body)
+		if err == nil || err.Error() != "Array on path [items] has duplicate [id]: 2 (elements #1 and #3)" {
+			t.Error("Unexpected error:", err)
+		}
+	})
+
+	t.Run("duplicate object", func(t *testing.T) {
+		body := testResponse(http.Header{"Content-Type": {"application/json"}}, `{"items": [{"a": 1, "b": "x"}, {"b": "x", "a": 1}]}`)
+
+		err := UniqueExpectation{Path: "items"}.check(body)
+		if err == nil || err.Error() != `Array on path [items] has duplicate element: {"a":1,"b":"x"} (elements #0 and #1)` {
+			t.Error("Unexpected error:", err)
+		}
+	})
+}
+
+func TestJSONTypeExpectation(t *testing.T) {
+	resp := &Response{
+		http: &http.Response{Header: map[string][]string{"Content-Type": {"application/json"}}},
+		body: []byte(`{"count": 5, "createdAt": "2021-01-01", "active": true, "deletedAt": null, "tags": ["a"], "owner": {"id": 1}, "items": [{"id": 1}, {"id": "2"}]}`),
+	}
+
+	passed := map[string]string{
+		"count":     "number",
+		"createdAt": "string",
+		"active":    "boolean",
+		"deletedAt": "null",
+		"tags":      "array",
+		"owner":     "object",
+	}
+	for path, jsonType := range passed {
+		if err := (JSONTypeExpectation{Path: path, Type: jsonType}).check(resp); err != nil {
+			t.Errorf("%s: %s", path, err)
+		}
+	}
+
+	failed := []struct {
+		path     string
+		jsonType string
+		message  string
+	}{
+		{path: "count", jsonType: "string", message: "count expected string, got number"},
+		{path: "deletedAt", jsonType: "object", message: "deletedAt expected object, got null"},
+		{path: "tags", jsonType: "object", message: "tags expected object, got array"},
+		{path: "items.id", jsonType: "number", message: "items.id expected number, got string"},
+		{path: "missing", jsonType: "number", message: "missing expected number, not found"},
+	}
+	for _, tt := range failed {
+		err := JSONTypeExpectation{Path: tt.path, Type: tt.jsonType}.check(resp)
+		if err == nil || err.Error() != tt.message {
+			t.Errorf("Expected error '%s', got %v", tt.message, err)
+		}
+	}
+}
+
+func TestValidJSONExpectation(t *testing.T) {
+	if err := (ValidJSONExpectation{}).check(testResponse(nil, `{"items": [1, 2]}`)); err != nil {
+		t.Error(err)
+	}
+
+	err := ValidJSONExpectation{}.check(testResponse(nil, "{\n  \"items\": [1, 2,]\n}"))
+	if err == nil || err.Error() != "Body is not valid JSON: invalid character ']' looking for beginning of value at line 2, column 18" {
+		t.Error("Unexpected error:", err)
+	}
+
+	err = ValidJSONExpectation{}.check(testResponse(nil, ""))
+	if err == nil || err.Error() != "Body is not valid JSON: body is empty" {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestValidXMLExpectation(t *testing.T) {
+	if err := (ValidXMLExpectation{}).check(testResponse(nil, `<?xml version="1.0"?><items><item id="1"/></items>`)); err != nil {
+		t.Error(err)
+	}
+
+	err := ValidXMLExpectation{}.check(testResponse(nil, "<items>\n<item></items>"))
+	if err == nil || err.Error() != "Body is not valid XML: XML syntax error on line 2: element <item> closed by </items>" {
+		t.Error("Unexpected error:", err)
+	}
+
+	err = ValidXMLExpectation{}.check(testResponse(nil, ""))
+	if err == nil || err.Error() != "Body is not valid XML: no root element" {
+		t.Error("Unexpected error:", err)
+	}
+}
+
+func TestArrayMatchExpectation(t *testing.T) {
+	resp := &Response{
+		http: &http.Response{Header: map[string][]string{"Content-Type": {"application/json"}}},
+		body: []byte(`{"items": [{"id": 5, "type": "user", "status": "active"}, {"id": 6, "type": "user", "status": "active"}, {"id": 7, "type": "user", "status": "blocked"}]}`),
+	}
+
+	tests := []struct {
+		quantifier string
+		predicate  map[string]interface{}
+		err        string
+	}{
+		{matchAny, map[string]interface{}{"id": 5.0, "status": "active"}, ""},
+		{matchAny, map[string]interface{}{"id": 7.0, "status": "active"}, "Expected any elements of array on path [items] to match (id == 7, status == active), 0 of 3 matched"},
+		{matchAll, map[string]interface{}{"type": "user"}, ""},
+		{matchAll, map[string]interface{}{"status": "active"}, "Expected all elements of array on path [items] to match (status == active), 2 of 3 matched"},
+		{matchNone, map[string]interface{}{"status": "deleted"}, ""},
+		{matchNone, map[string]interface{}{"status": "active"}, "Expected none elements of array on path [items] to match (status == active), 2 of 3 matched"},
+	}
+
+	for _, tt := range tests {
+		err := ArrayMatchExpectation{Path: "items", Quantifier: tt.quantifier, Predicate: tt.predicate}.check(resp)
+
+		if tt.err == "" && err != nil {
+			t.Errorf("%s %v: unexpected error %s", tt.quantifier, tt.predicate, err)
+		}
+
+		if tt.err != "" && (err == nil || err.Error() != tt.err) {
+			t.Errorf("%s %v: expected error '%s', got %v", tt.quantifier, tt.predicate, tt.err, err)
+		}
+	}
+}
+
+func TestBodyOneOfMatchesSecondOption(t *testing.T) {
+	var expected []interface{}
+	if err := json.Unmarshal([]byte(`[{"status": "queued"}, {"status": "done", "result": 42}]`), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := BodyOneOfExpectation{ExpectedBodies: expected}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"status": "done", "result": 42}`),
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBodyOneOfNoMatch(t *testing.T) {
+	var expected []interface{}
+	if err := json.Unmarshal([]byte(`[{"status": "queued"}, {"status": "done"}]`), &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := BodyOneOfExpectation{ExpectedBodies: expected}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"status": "failed"}`),
+	})
+
+	if err == nil {
+		t.Fatal("Expected error for body matching none of options")
+	}
+
+	for _, expected := range []string{"matches none of 2 expected bodies", "Option #1: ", "Option #2: ", "queued", "failed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in error: %s", expected, err)
+		}
+	}
+}
+
+func TestFieldComparison(t *testing.T) {
+	resp := &Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"total": 2, "items": [{"id": 1}, {"id": 2}], "a": {"x": 1}, "b": {"x": 1}, "min": 5, "max": 3}`),
+	}
+
+	tests := []struct {
+		comparison FieldComparison
+		err        string
+	}{
+		{FieldComparison{Left: "total", Op: "==", Right: "items.size()"}, ""},
+		{FieldComparison{Left: "a", Op: "==", Right: "b"}, ""},
+		{FieldComparison{Left: "items.0.id", Op: "<", Right: "items.1.id"}, ""},
+		{FieldComparison{Left: "min", Op: "<=", Right: "max"}, "Expected min <= max, but min is 5 and max is 3"},
+		{FieldComparison{Left: "a", Op: "!=", Right: "b"}, `Expected a != b, but a is {"x":1} and b is {"x":1}`},
+		{FieldComparison{Left: "a", Op: ">", Right: "b"}, "Can't compare values"},
+		{FieldComparison{Left: "total", Op: "==", Right: "count"}, "Required exactly one value"},
+	}
+
+	for _, tt := range tests {
+		err := FieldComparisonExpectation{tt.comparison}.check(resp)
+
+		if tt.err == "" && err != nil {
+			t.Errorf("%v: unexpected error %s", tt.comparison, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%v: expected error %q, got %v", tt.comparison, tt.err, err)
+		}
+	}
+}
+
+func TestFieldComparisonLengthVsCount(t *testing.T) {
+	exp := FieldComparisonExpectation{FieldComparison{Left: "total", Op: "==", Right: "items.size()"}}
+
+	err := exp.check(&Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`{"total": 3, "items": [{"id": 1}, {"id": 2}]}`),
+	})
+
+	expected := "Expected total == items.size(), but total is 3 and items.size() is 2"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %s, got %v", expected, err)
+	}
+}
+
+func TestTrailerExpectation(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Write([]byte("payload"))
+		w.(http.Flusher).Flush()
+
+		w.Header().Set("Grpc-Status", "0")
+		w.Header().Set("Grpc-Message", "OK")
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{Trailers: map[string]string{"grpc-status": "0", "Grpc-Message": ""}}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if trace.Trailers.Get("Grpc-Status") != "0" {
+		t.Errorf("Expected trailers in the trace, got %v", trace.Trailers)
+	}
+
+	// unexpected value
+	c.Expect.Trailers = map[string]string{"grpc-status": "14"}
+	trace = call("", c, NewVars(""))
+
+	if !trace.HasError() || trace.ErrorCause.Error() != `Unexpected trailer. Expected "grpc-status: 14". Actual "grpc-status: 0"` {
+		t.Errorf("Expected unexpected trailer error, got %v", trace.ErrorCause)
+	}
+}
+
+func TestHeaderValuesExpectation(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Add("Set-Cookie", "session=abc; Path=/; HttpOnly")
+		w.Header().Add("Set-Cookie", "theme=dark; Path=/")
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{
+		HeaderValues: map[string][]string{"Set-Cookie": {"session={session}", "theme=dark"}},
+		HeaderCount:  map[string]int{"set-cookie": 2, "X-Request-Id": 0},
+	}}
+	vars := NewVars("")
+	vars.Add("session", "abc")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	// wrong order
+	c.Expect = Expect{HeaderValues: map[string][]string{"Set-Cookie": {"theme=", "session="}}}
+	trace = call("", c, NewVars(""))
+
+	if !trace.HasError() || !strings.HasPrefix(trace.ErrorCause.Error(), `Unexpected occurrence #1 of header 'Set-Cookie'. Expected to start with "theme=", got "session=abc; Path=/; HttpOnly"`) {
+		t.Errorf("Expected order failure, got %v", trace.ErrorCause)
+	}
+
+	// missing occurrence
+	c.Expect = Expect{HeaderCount: map[string]int{"Set-Cookie": 3}}
+	trace = call("", c, NewVars(""))
+
+	if !trace.HasError() || !strings.HasPrefix(trace.ErrorCause.Error(), "Expected 3 occurrences of header 'Set-Cookie', got 2") {
+		t.Errorf("Expected count failure, got %v", trace.ErrorCause)
+	}
+}
+
+func TestLocationExpectation(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/login" {
+			http.Redirect(w, req, "https://sso.example.com/authorize?client_id=42&state=xyz", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	vars := NewVars("")
+	vars.Add("clientId", "42")
+
+	noFollow := false
+	c := Call{
+		On: On{Method: "GET", URL: server.URL + "/login", FollowRedirects: &noFollow},
+		Expect: Expect{
+			StatusCode: 302,
+			Location:   &LocationAssert{Prefix: "https://sso.example.com/", Matches: `^https://[^/]+/authorize\?client_id={clientId}&state=\w+$`},
+		},
+	}
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	// mismatch
+	c.Expect.Location = &LocationAssert{Matches: `/logout$`}
+	trace = call("", c, NewVars(""))
+
+	if !trace.HasError() || !strings.HasPrefix(trace.ErrorCause.Error(), "Unexpected 'Location'. Expected to match: /logout$") {
+		t.Errorf("Expected location mismatch, got %v", trace.ErrorCause)
+	}
+}
+
+func TestExpectationsResolveEnvVars(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Version", "1.4.2")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version": "1.4.2"}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("BOZR_TEST_EXPECTED_VERSION", "1.4.2")
+	defer os.Unsetenv("BOZR_TEST_EXPECTED_VERSION")
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{
+		Headers:      map[string]string{"X-Version": "{env:BOZR_TEST_EXPECTED_VERSION}"},
+		BPath:        map[string]interface{}{"version": "{env:BOZR_TEST_EXPECTED_VERSION}"},
+		BodyContains: `"version": "{env:BOZR_TEST_EXPECTED_VERSION}"`,
+	}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	// deployed version differs
+	os.Setenv("BOZR_TEST_EXPECTED_VERSION", "1.5.0")
+	trace = call("", Call{On: c.On, Expect: Expect{Headers: c.Expect.Headers}}, NewVars(""))
+
+	if !trace.HasError() || trace.ErrorCause.Error() != `Unexpected header. Expected "X-Version: 1.5.0". Actual "X-Version: 1.4.2"` {
+		t.Errorf("Expected header mismatch, got %v", trace.ErrorCause)
+	}
+
+	// not set variable is kept as is
+	os.Unsetenv("BOZR_TEST_EXPECTED_VERSION")
+	trace = call("", Call{On: c.On, Expect: Expect{Headers: c.Expect.Headers}}, NewVars(""))
+
+	if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), `Expected "X-Version: {env:BOZR_TEST_EXPECTED_VERSION}"`) {
+		t.Errorf("Expected unresolved placeholder in failure, got %v", trace.ErrorCause)
+	}
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// extractBody scopes response body to the value found on the path, e.g. 'data.order'.
+// The value becomes JSON body of returned response, headers and status are kept.
+func extractBody(resp Response, path string) (Response, error) {
+	body, err := resp.Body()
+	if err != nil {
+		return resp, fmt.Errorf("Can't extract [%s]: %s", path, err)
+	}
+
+	value, err := GetByPath(body, path)
+	if err != nil {
+		return resp, fmt.Errorf("Can't extract [%s]: %s", path, err)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return resp, err
+	}
+
+	extracted := *resp.http
+	extracted.Header = resp.http.Header.Clone()
+	extracted.Header.Set("Content-Type", "application/json")
+	extracted.Header.Del("Content-Length")
+
+	scoped, err := newResponse(&extracted, data)
+	if err != nil {
+		return resp, err
+	}
+	scoped.parsedBody = value
+	scoped.contentEncoding = resp.contentEncoding
+
+	return scoped, nil
+}
@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newOrderServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data": {"order": {"id": "o-1", "customer": {"name": "John", "address": {"city": "Berlin"}}, "lines": [1, 2]}}}`))
+	}))
+}
+
+func TestExtractScopesExpectations(t *testing.T) {
+	// given
+	server := newOrderServer()
+	defer server.Close()
+
+	c := Call{
+		On:       On{Method: "GET", URL: server.URL + "/orders/1"},
+		Extract:  "data.order",
+		Expect:   Expect{StatusCode: 200, BPath: map[string]interface{}{"id": "o-1", "customer.address.city": "Berlin", "lines.size()": 2.0}},
+		Remember: Remember{BPath: map[string]string{"orderId": "data.order.id"}},
+	}
+	vars := NewVars("")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if id := vars.ApplyTo("{orderId}"); id != "o-1" {
+		t.Errorf("Expected value remembered from the whole body, got %v", id)
+	}
+}
+
+func TestExtractMissingPathFails(t *testing.T) {
+	// given
+	server := newOrderServer()
+	defer server.Close()
+
+	c := Call{
+		On:      On{Method: "GET", URL: server.URL + "/orders/1"},
+		Extract: "data.invoice",
+		Expect:  Expect{StatusCode: 200},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "Can't extract [data.invoice]") {
+		t.Errorf("Expected extraction failure, got %v", trace.ErrorCause)
+	}
+}
@@ -0,0 +1,193 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fault is a programmed outcome of a request sent through faultTransport
+type fault struct {
+	// delay before the outcome, request cancellation is respected
+	Latency time.Duration
+	// returned instead of response
+	Err error
+
+	Status int
+	Header http.Header
+	Body   string
+}
+
+// faultTransport replays programmed outcomes in order without a real server, the last one is repeated
+type faultTransport struct {
+	Faults []fault
+
+	mu       sync.Mutex
+	requests []*http.Request
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	f := t.Faults[len(t.Faults)-1]
+	if len(t.requests) < len(t.Faults) {
+		f = t.Faults[len(t.requests)]
+	}
+	t.requests = append(t.requests, req)
+	t.mu.Unlock()
+
+	if f.Latency > 0 {
+		select {
+		case <-time.After(f.Latency):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	status := f.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	header := f.Header
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(f.Body)),
+		Request:    req,
+	}, nil
+}
+
+func (t *faultTransport) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.requests)
+}
+
+// timeoutError imitates timeout of the network connection
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestFaultStatusSequenceRepeatsAuthenticatedRequest(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	transport := &faultTransport{Faults: []fault{
+		{Status: http.StatusUnauthorized, Header: http.Header{"Www-Authenticate": {`Digest realm="api", nonce="abc", qop="auth"`}}},
+		{Status: http.StatusOK, Header: http.Header{"Content-Type": {"application/json"}}, Body: `{"id": 1}`},
+	}}
+	options.Transport = transport
+
+	c := Call{
+		On:     On{Method: "GET", URL: "http://api.test/users/1", Auth: &Auth{Type: authDigest, Username: "admin", Password: "secret"}},
+		Expect: Expect{StatusCode: 200, BPath: map[string]interface{}{"id": 1.0}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if transport.count() != 2 || !strings.HasPrefix(transport.requests[1].Header.Get("Authorization"), "Digest ") {
+		t.Errorf("Expected request to be repeated with credentials, got %d requests", transport.count())
+	}
+}
+
+func TestFaultLatencyExceedsStopTimeout(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"slow.suite.json": `[{"name": "slow", "calls": [{"on": {"method": "GET", "url": "/slow"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	transport := &faultTransport{Faults: []fault{{Latency: time.Minute}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+
+	// when
+	results, err := Run(ctx, Options{Path: dir, BaseURL: "http://api.test", StopTimeout: 10 * time.Millisecond, Transport: transport})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || !strings.Contains(results[0].Error(), "Request is cancelled, stop timeout exceeded") {
+		t.Errorf("Expected request to be cancelled after stop timeout, got %v", results)
+	}
+}
+
+func TestFaultTimeoutErrorClassified(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	options.Transport = &faultTransport{Faults: []fault{{Latency: 5 * time.Millisecond, Err: timeoutError{}}}}
+
+	c := Call{On: On{Method: "GET", URL: "http://api.test/users"}, Expect: Expect{StatusCode: 200}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	var transportErr *TransportError
+	if !errors.As(trace.ErrorCause, &transportErr) || transportErr.Category != TransportErrorTimeout {
+		t.Errorf("Expected timeout error, got %v", trace.ErrorCause)
+	}
+}
+
+func TestFaultTransientErrorsRetried(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	transport := &faultTransport{Faults: []fault{
+		{Err: timeoutError{}},
+		{Status: http.StatusServiceUnavailable},
+		{Status: http.StatusOK},
+	}}
+	options.Transport = transport
+
+	c := Call{
+		On:     On{Method: "GET", URL: "http://api.test/orders"},
+		Expect: Expect{StatusCode: 200},
+		Retry:  &CallRetry{Attempts: 3, Delay: "1ms"},
+	}
+
+	// when
+	trace := callWithRetry(context.Background(), "", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if transport.count() != 3 || trace.Retries != 2 {
+		t.Errorf("Expected call to pass on the second retry, got %d requests and %d retries", transport.count(), trace.Retries)
+	}
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// flag of gRPC-Web frame with trailers, data frames have no flags
+const grpcWebTrailerFlag = 0x80
+
+// frameGRPCWeb prefixes message with flags and big-endian length
+func frameGRPCWeb(message []byte) []byte {
+	frame := make([]byte, 5, 5+len(message))
+	binary.BigEndian.PutUint32(frame[1:], uint32(len(message)))
+
+	return append(frame, message...)
+}
+
+// isGRPCWebResponse checks media type is application/grpc-web or application/grpc-web-text with optional '+proto'
+func isGRPCWebResponse(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	return strings.HasPrefix(mediaType, "application/grpc-web")
+}
+
+// unframeGRPCWeb returns message of gRPC-Web response body, 'grpc-status' and other trailers of
+// the trailer frame are added to response trailers
+func unframeGRPCWeb(resp *http.Response, body []byte) ([]byte, error) {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if strings.HasPrefix(mediaType, "application/grpc-web-text") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(body)))
+		if err != nil {
+			return nil, errors.New("Invalid gRPC-Web text response, body is not base64")
+		}
+		body = decoded
+	}
+
+	var message []byte
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, errors.New("Invalid gRPC-Web response, unexpected end of frame")
+		}
+
+		flags, size := body[0], binary.BigEndian.Uint32(body[1:5])
+		if uint32(len(body)-5) < size {
+			return nil, errors.New("Invalid gRPC-Web response, unexpected end of frame")
+		}
+		payload := body[5 : 5+size]
+		body = body[5+size:]
+
+		if flags&grpcWebTrailerFlag == 0 {
+			message = append(message, payload...)
+			continue
+		}
+
+		if resp.Trailer == nil {
+			resp.Trailer = make(http.Header)
+		}
+		for _, line := range strings.Split(string(payload), "\r\n") {
+			if i := strings.Index(line, ":"); i > 0 {
+				resp.Trailer.Add(strings.TrimSpace(line[:i]), strings.TrimSpace(line[i+1:]))
+			}
+		}
+	}
+
+	// trailers-only response carries status in headers
+	for _, name := range []string{"Grpc-Status", "Grpc-Message"} {
+		if value := resp.Header.Get(name); value != "" && resp.Trailer.Get(name) == "" {
+			if resp.Trailer == nil {
+				resp.Trailer = make(http.Header)
+			}
+			resp.Trailer.Set(name, value)
+		}
+	}
+
+	return message, nil
+}
@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// types of header values compared by HeaderComparison
+const (
+	headerTypeNumber   = "number"
+	headerTypeDuration = "duration"
+	headerTypeDate     = "date"
+)
+
+// HeaderComparison compares typed value of the header with expected one, e.g. 'X-RateLimit-Remaining' > 0
+type HeaderComparison struct {
+	Name string `json:"name"`
+	// one of ==, !=, <, <=, >, >=, '==' if empty
+	Op string `json:"op"`
+	// expected value in the format of the type, date could be relative to current time: 'now', 'now-5m'
+	Value string `json:"value"`
+	// 'number' (default), 'duration' (seconds or Go duration, e.g. '1m30s') or 'date' (HTTP-date or RFC 3339)
+	Type string `json:"type"`
+}
+
+// HeaderComparisonExpectation validates typed value of the header
+type HeaderComparisonExpectation struct {
+	HeaderComparison
+}
+
+func (e HeaderComparisonExpectation) check(resp *Response) error {
+	header := strings.TrimSpace(resp.http.Header.Get(e.Name))
+	if header == "" {
+		return fmt.Errorf("Missing header. Expected \"%s\" %s %s", e.Name, e.Op, e.Value)
+	}
+
+	actual, err := parseHeaderValue(e.Type, header, time.Time{})
+	if err != nil {
+		return fmt.Errorf("Header \"%s: %s\" is not a %s: %s", e.Name, header, e.Type, err)
+	}
+
+	// relative dates are resolved against server time if it is known
+	now := time.Now()
+	if serverTime, err := http.ParseTime(resp.http.Header.Get("Date")); err == nil && e.Type == headerTypeDate {
+		now = serverTime
+	}
+
+	expected, err := parseHeaderValue(e.Type, e.Value, now)
+	if err != nil {
+		return fmt.Errorf("Invalid expected value of header %s: %s", e.Name, err)
+	}
+
+	c, _ := compareValues(actual, expected)
+	if !comparisonOps[e.Op](c) {
+		return fmt.Errorf("Expected header %s %s %s, actual \"%s: %s\"", e.Name, e.Op, e.Value, e.Name, header)
+	}
+
+	return nil
+}
+
+func (e HeaderComparisonExpectation) desc() string {
+	return fmt.Sprintf("Header '%s' %s %s", e.Name, e.Op, e.Value)
+}
+
+// parseHeaderValue converts value of the type into comparable number, dates are relative to now if they start with 'now'
+func parseHeaderValue(valueType string, value string, now time.Time) (float64, error) {
+	value = strings.TrimSpace(value)
+
+	switch valueType {
+	case headerTypeDuration:
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			return seconds * float64(time.Second), nil
+		}
+
+		d, err := time.ParseDuration(value)
+		return float64(d), err
+
+	case headerTypeDate:
+		if strings.HasPrefix(value, "now") && !now.IsZero() {
+			offset := time.Duration(0)
+			if rest := strings.TrimPrefix(value, "now"); rest != "" {
+				d, err := time.ParseDuration(strings.TrimPrefix(rest, "+"))
+				if err != nil {
+					return 0, err
+				}
+				offset = d
+			}
+			return float64(now.Add(offset).UnixNano()), nil
+		}
+
+		t, err := http.ParseTime(value)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, value); err != nil {
+				return 0, fmt.Errorf("expected HTTP-date or RFC 3339 date, got '%s'", value)
+			}
+		}
+		return float64(t.UnixNano()), nil
+	}
+
+	return strconv.ParseFloat(value, 64)
+}
+
+// validate checks operator and type, sets defaults
+func (c *HeaderComparison) validate() error {
+	if c.Op == "" {
+		c.Op = "=="
+	}
+	if _, ok := comparisonOps[c.Op]; !ok {
+		return fmt.Errorf("Invalid header compare operator '%s', expected one of ==, !=, <, <=, >, >=", c.Op)
+	}
+
+	if c.Type == "" {
+		c.Type = headerTypeNumber
+	}
+	if c.Type != headerTypeNumber && c.Type != headerTypeDuration && c.Type != headerTypeDate {
+		return fmt.Errorf("Invalid header compare type '%s', expected one of number, duration, date", c.Type)
+	}
+
+	return nil
+}
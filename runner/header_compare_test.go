@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeaderCompareRateLimit(t *testing.T) {
+	resp := testResponse(http.Header{"X-Ratelimit-Remaining": {"0"}, "Retry-After": {"120"}}, "")
+
+	comparison := HeaderComparison{Name: "X-RateLimit-Remaining", Op: ">", Value: "0"}
+	if err := comparison.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := HeaderComparisonExpectation{comparison}.check(resp)
+	if err == nil || err.Error() != `Expected header X-RateLimit-Remaining > 0, actual "X-RateLimit-Remaining: 0"` {
+		t.Errorf("Expected rate limit comparison failure, got %v", err)
+	}
+
+	retryAfter := HeaderComparison{Name: "Retry-After", Op: "<=", Value: "2m", Type: "duration"}
+	if err := retryAfter.validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (HeaderComparisonExpectation{retryAfter}).check(resp); err != nil {
+		t.Errorf("Expected Retry-After to be within 2m, got %s", err)
+	}
+}
+
+func TestHeaderCompareDate(t *testing.T) {
+	serverTime := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	resp := testResponse(http.Header{
+		"Date":          {serverTime.Format(http.TimeFormat)},
+		"Last-Modified": {serverTime.Add(-10 * time.Minute).Format(http.TimeFormat)},
+		"Expires":       {serverTime.Add(time.Hour).Format(http.TimeFormat)},
+	}, "")
+
+	cases := []struct {
+		comparison HeaderComparison
+		passed     bool
+	}{
+		{HeaderComparison{Name: "Last-Modified", Op: "<", Value: "now", Type: "date"}, true},
+		{HeaderComparison{Name: "Last-Modified", Op: ">=", Value: "now-5m", Type: "date"}, false},
+		{HeaderComparison{Name: "Expires", Op: "==", Value: "2020-03-01T13:00:00Z", Type: "date"}, true},
+		{HeaderComparison{Name: "Expires", Op: ">", Value: "Sun, 01 Mar 2020 13:00:00 GMT", Type: "date"}, false},
+	}
+
+	for _, c := range cases {
+		if err := c.comparison.validate(); err != nil {
+			t.Fatal(err)
+		}
+
+		err := HeaderComparisonExpectation{c.comparison}.check(resp)
+		if (err == nil) != c.passed {
+			t.Errorf("%s %s %s: expected passed %v, got %v", c.comparison.Name, c.comparison.Op, c.comparison.Value, c.passed, err)
+		}
+	}
+}
+
+func TestHeaderCompareInvalid(t *testing.T) {
+	if err := (&HeaderComparison{Name: "Age", Op: "~", Value: "1"}).validate(); err == nil {
+		t.Error("Expected invalid operator error")
+	}
+
+	err := HeaderComparisonExpectation{HeaderComparison{Name: "Age", Op: "==", Value: "1", Type: "number"}}.check(testResponse(http.Header{"Age": {"soon"}}, ""))
+	if err == nil || !strings.HasPrefix(err.Error(), `Header "Age: soon" is not a number`) {
+		t.Errorf("Expected parse error, got %v", err)
+	}
+}
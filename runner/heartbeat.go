@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+type heartbeatKey struct{}
+
+// heartbeatTicks returns channel of ticks with the interval and function to stop them, replaced in tests
+var heartbeatTicks = func(interval time.Duration) (<-chan time.Time, func()) {
+	ticker := time.NewTicker(interval)
+	return ticker.C, ticker.Stop
+}
+
+// heartbeat periodically writes progress of the run, so CI does not kill long run without output as hung
+type heartbeat struct {
+	total int
+	done  int64
+}
+
+// withHeartbeat returns context which test cases report their completion to
+func withHeartbeat(ctx context.Context, h *heartbeat) context.Context {
+	return context.WithValue(ctx, heartbeatKey{}, h)
+}
+
+// reportCaseDone counts finished (or skipped) test case for heartbeat of the run, if any
+func reportCaseDone(ctx context.Context) {
+	if h, ok := ctx.Value(heartbeatKey{}).(*heartbeat); ok {
+		atomic.AddInt64(&h.done, 1)
+	}
+}
+
+// start writes progress line into w every interval until returned stop function is called
+func (h *heartbeat) start(w io.Writer, interval time.Duration) func() {
+	ticks, stopTicks := heartbeatTicks(interval)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-ticks:
+				fmt.Fprintf(w, "... still running, %d/%d cases done\n", atomic.LoadInt64(&h.done), h.total)
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		stopTicks()
+		close(stop)
+		<-stopped
+	}
+}
+
+// countCases returns number of test cases in suites of the source
+func countCases(source DirSuiteFileIterator) int {
+	source.init()
+
+	total := 0
+	for source.HasNext() {
+		if suite := source.Next().ToSuite(); suite != nil {
+			total += len(suite.Cases)
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,121 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock sends tick once the time advanced by the interval
+type fakeClock struct {
+	interval time.Duration
+	elapsed  time.Duration
+	ticks    chan time.Time
+}
+
+func useFakeClock() (*fakeClock, func()) {
+	clock := &fakeClock{ticks: make(chan time.Time)}
+
+	original := heartbeatTicks
+	heartbeatTicks = func(interval time.Duration) (<-chan time.Time, func()) {
+		clock.interval = interval
+		return clock.ticks, func() {}
+	}
+
+	return clock, func() { heartbeatTicks = original }
+}
+
+// advance blocks until heartbeat receives the ticks
+func (c *fakeClock) advance(d time.Duration) {
+	c.elapsed += d
+	for c.elapsed >= c.interval {
+		c.elapsed -= c.interval
+		c.ticks <- time.Now()
+	}
+}
+
+type lineWriter chan string
+
+func (w lineWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestHeartbeatFiresAfterInterval(t *testing.T) {
+	// given
+	clock, restore := useFakeClock()
+	defer restore()
+
+	lines := make(lineWriter, 10)
+	h := &heartbeat{total: 50, done: 12}
+	stop := h.start(lines, 30*time.Second)
+	defer stop()
+
+	// when
+	clock.advance(29 * time.Second)
+
+	// then
+	if len(lines) != 0 {
+		t.Fatalf("Expected no heartbeat before the interval, got '%s'", <-lines)
+	}
+
+	// when
+	clock.advance(time.Second)
+
+	// then
+	select {
+	case line := <-lines:
+		if line != "... still running, 12/50 cases done\n" {
+			t.Errorf("Unexpected heartbeat: %s", line)
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected heartbeat after the interval")
+	}
+}
+
+func TestRunHeartbeat(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	clock, restore := useFakeClock()
+	defer restore()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			clock.advance(clock.interval)
+		}
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"a.suite.json": `[
+			{"name": "fast", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/fast"}, "expect": {"statusCode": 200}}]},
+			{"name": "slow", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/slow"}, "expect": {"statusCode": 200}}]}
+		]`,
+		"b.suite.json": `[{"name": "other", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/fast"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+
+	// when
+	_, err := Run(context.Background(), Options{Path: dir, Heartbeat: 30 * time.Second, HeartbeatOutput: &out})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clock.interval != 30*time.Second {
+		t.Errorf("Expected heartbeat interval 30s, got %s", clock.interval)
+	}
+
+	if !strings.Contains(out.String(), "... still running, 1/3 cases done\n") {
+		t.Errorf("Expected heartbeat line, got '%s'", out.String())
+	}
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const abortBeforeAll = "aborted: before-all hook failed"
+
+// validateHook checks hook suite file exists and has no syntax errors, hook is optional
+func validateHook(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() || !strings.HasSuffix(path, SuiteExt) {
+		return fmt.Errorf("Hook %s is not a %s file", path, SuiteExt)
+	}
+
+	return ValidateSuites(path, SuiteExt, IgnoredSuiteExt)
+}
+
+// runHook executes test cases of hook suite file sequentially and reports its results
+func runHook(ctx context.Context, path string, reporter Reporter) []TestResult {
+	if path == "" {
+		return nil
+	}
+
+	var results []TestResult
+	for suite := range NewSuiteLoader(path, SuiteExt, IgnoredSuiteExt) {
+		suiteResults := runSuite(ctx, suite)
+		reporter.Report(suiteResults)
+
+		results = append(results, suiteResults...)
+	}
+
+	return results
+}
+
+// hookFiles lists absolute paths of hook files to exclude them from regular suites
+func hookFiles(paths ...string) map[string]bool {
+	files := make(map[string]bool)
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(path); err == nil {
+			files[abs] = true
+		}
+	}
+
+	return files
+}
+
+// flushLater postpones Flush of the reporter, so results of after-all hook are reported after results of the suites
+type flushLater struct {
+	Reporter
+}
+
+func (r flushLater) Flush() {}
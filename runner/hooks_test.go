@@ -0,0 +1,108 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunHooksOrder(t *testing.T) {
+	// given
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+		if req.URL.Path == "/orders" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"hooks/seed.suite.json":    `[{"name": "seed", "calls": [{"on": {"method": "POST", "url": "/admin/seed"}, "expect": {"statusCode": 200}}]}]`,
+		"hooks/cleanup.suite.json": `[{"name": "cleanup", "calls": [{"on": {"method": "POST", "url": "/admin/cleanup"}, "expect": {"statusCode": 200}}]}]`,
+		"orders.suite.json":        `[{"name": "list orders", "calls": [{"on": {"method": "GET", "url": "/orders"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{
+		Path:      dir,
+		BaseURL:   server.URL,
+		BeforeAll: filepath.Join(dir, "hooks/seed.suite.json"),
+		AfterAll:  filepath.Join(dir, "hooks/cleanup.suite.json"),
+	})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(paths, ",") != "/admin/seed,/orders,/admin/cleanup" {
+		t.Errorf("Unexpected order of requests: %v", paths)
+	}
+
+	if len(results) != 3 || results[2].Case.Name != "cleanup" || results[2].HasError() || !results[1].HasError() {
+		t.Errorf("Expected after-all to pass after failed suite, got %v", results)
+	}
+}
+
+func TestRunBeforeAllFailureAborts(t *testing.T) {
+	// given
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		paths = append(paths, req.URL.Path)
+		if req.URL.Path == "/admin/seed" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"seed.suite.json":         `[{"name": "seed", "calls": [{"on": {"method": "POST", "url": "/admin/seed"}, "expect": {"statusCode": 200}}]}]`,
+		"cleanup.suite.json":      `[{"name": "cleanup", "calls": [{"on": {"method": "POST", "url": "/admin/cleanup"}, "expect": {"statusCode": 200}}]}]`,
+		"tests/orders.suite.json": `[{"name": "list orders", "calls": [{"on": {"method": "GET", "url": "/orders"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{
+		Path:      filepath.Join(dir, "tests"),
+		BaseURL:   server.URL,
+		BeforeAll: filepath.Join(dir, "seed.suite.json"),
+		AfterAll:  filepath.Join(dir, "cleanup.suite.json"),
+	})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(paths, ",") != "/admin/seed,/admin/cleanup" {
+		t.Errorf("Unexpected requests: %v", paths)
+	}
+
+	if len(results) != 3 || !results[1].Skipped || results[1].SkippedMsg != abortBeforeAll {
+		t.Errorf("Expected suite to be skipped after failed before-all, got %v", results)
+	}
+}
+
+func TestRunInvalidHook(t *testing.T) {
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"orders.suite.json": `[{"name": "list orders", "calls": [{"on": {"method": "GET", "url": "/orders"}, "expect": {"statusCode": 200}}]}]`,
+		"seed.json":         `[]`,
+	})
+	defer os.RemoveAll(dir)
+
+	_, err := Run(context.Background(), Options{Path: dir, BeforeAll: filepath.Join(dir, "seed.json")})
+	if err == nil || !strings.Contains(err.Error(), "is not a .suite.json file") {
+		t.Errorf("Unexpected error for invalid hook: %v", err)
+	}
+}
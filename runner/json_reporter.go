@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	jsonStatusPassed  = "passed"
+	jsonStatusFailed  = "failed"
+	jsonStatusSkipped = "skipped"
+)
+
+// JSONReporter writes results of all test suites into a single JSON file on Flush
+type JSONReporter struct {
+	OutPath string
+	// destination of the report instead of the file, e.g. os.Stdout
+	Writer io.Writer
+
+	mu      sync.Mutex
+	start   time.Time
+	suites  []jsonSuite
+	timings Timings
+}
+
+type jsonReport struct {
+	Summary jsonSummary `json:"summary"`
+	Suites  []jsonSuite `json:"suites"`
+}
+
+type jsonSummary struct {
+	Total      int   `json:"total"`
+	Passed     int   `json:"passed"`
+	Failed     int   `json:"failed"`
+	Skipped    int   `json:"skipped"`
+	DurationMs int64 `json:"durationMs"`
+	// percentiles of request and test case durations
+	Requests *timingsSummary `json:"requests,omitempty"`
+	Cases    *timingsSummary `json:"cases,omitempty"`
+}
+
+type jsonSuite struct {
+	Name    string     `json:"name"`
+	Package string     `json:"package,omitempty"`
+	Cases   []jsonCase `json:"cases"`
+}
+
+type jsonCase struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	DurationMs int64      `json:"durationMs"`
+	Error      string     `json:"error,omitempty"`
+	SkipReason string     `json:"skipReason,omitempty"`
+	Calls      []jsonCall `json:"calls,omitempty"`
+}
+
+type jsonCall struct {
+	Method     string `json:"method,omitempty"`
+	URL        string `json:"url,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+	TTFBMs     int64  `json:"ttfbMs,omitempty"`
+	Error      string `json:"error,omitempty"`
+	// failed expectations of 'warning' severity with the error
+	Warnings map[string]string `json:"warnings,omitempty"`
+	// skipped expectations with the reason
+	Skipped map[string]string `json:"skipped,omitempty"`
+}
+
+// Init remembers start of the run
+func (r *JSONReporter) Init() {
+	r.start = time.Now()
+}
+
+// Report retains results of the suite
+func (r *JSONReporter) Report(results []TestResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	suite := jsonSuite{Name: results[0].Suite.Name, Package: results[0].Suite.PackageName()}
+	for _, result := range results {
+		c := jsonCase{
+			Name:       result.Case.Name,
+			Status:     jsonStatusPassed,
+			DurationMs: int64(result.ExecFrame.Duration() / time.Millisecond),
+		}
+
+		switch {
+		case result.Skipped:
+			c.Status = jsonStatusSkipped
+			c.SkipReason = result.SkippedMsg
+		case result.HasError():
+			c.Status = jsonStatusFailed
+			c.Error = result.Error()
+		}
+
+		for _, trace := range result.Traces {
+			call := jsonCall{
+				Method:     trace.RequestMethod,
+				URL:        trace.RequestURL,
+				DurationMs: int64(trace.ExecFrame.Duration() / time.Millisecond),
+				TTFBMs:     int64(trace.FirstByte / time.Millisecond),
+				Warnings:   trace.ExpWarnings,
+				Skipped:    trace.ExpSkipped,
+			}
+			if trace.HasError() {
+				call.Error = trace.ErrorCause.Error()
+			}
+
+			c.Calls = append(c.Calls, call)
+		}
+
+		suite.Cases = append(suite.Cases, c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.suites = append(r.suites, suite)
+	r.timings.add(results)
+}
+
+// Flush writes the report into the file or writer
+func (r *JSONReporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// suites are reported in order of completion
+	suites := append([]jsonSuite{}, r.suites...)
+	sort.SliceStable(suites, func(i, j int) bool {
+		if suites[i].Package != suites[j].Package {
+			return suites[i].Package < suites[j].Package
+		}
+		return suites[i].Name < suites[j].Name
+	})
+
+	requests, cases := summarize(r.timings.Requests), summarize(r.timings.Cases)
+	report := jsonReport{Suites: suites, Summary: jsonSummary{
+		DurationMs: int64(time.Since(r.start) / time.Millisecond),
+		Requests:   &requests,
+		Cases:      &cases,
+	}}
+	for _, suite := range suites {
+		for _, c := range suite.Cases {
+			report.Summary.Total++
+			switch c.Status {
+			case jsonStatusPassed:
+				report.Summary.Passed++
+			case jsonStatusFailed:
+				report.Summary.Failed++
+			case jsonStatusSkipped:
+				report.Summary.Skipped++
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	if r.Writer != nil {
+		if _, err := r.Writer.Write(append(data, '\n')); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(r.OutPath), 0777)
+	if err == nil {
+		err = writeFileAtomic(r.OutPath, data)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewJSONReporter returns reporter that writes results into provided file
+func NewJSONReporter(outPath string) Reporter {
+	return &JSONReporter{OutPath: outPath}
+}
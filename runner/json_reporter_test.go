@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONReporter(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-json")
+	defer os.RemoveAll(dir)
+
+	reporter := NewJSONReporter(filepath.Join(dir, "out", "report.json"))
+	reporter.Init()
+
+	// when
+	reporter.Report([]TestResult{
+		{Suite: TestSuite{Name: "users", Dir: "api"}, Case: TestCase{Name: "get"}, Traces: []*CallTrace{{RequestMethod: "GET", RequestURL: "/users/1", ExpSkipped: map[string]string{"Header 'ETag'": "JIRA-2"}, ExpWarnings: map[string]string{"Header 'Deprecation'": "Header 'Deprecation' is present"}}}},
+		{Suite: TestSuite{Name: "users", Dir: "api"}, Case: TestCase{Name: "delete"}, Traces: []*CallTrace{{ErrorCause: errors.New("Status code 500")}}},
+	})
+	reporter.Report([]TestResult{
+		{Suite: TestSuite{Name: "health"}, Case: TestCase{Name: "up"}, Skipped: true, SkippedMsg: "JIRA-1"},
+	})
+	reporter.Flush()
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "out", "report.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var report jsonReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Summary.Total != 3 || report.Summary.Passed != 1 || report.Summary.Failed != 1 || report.Summary.Skipped != 1 {
+		t.Errorf("Unexpected summary: %+v", report.Summary)
+	}
+
+	if report.Summary.Requests == nil || report.Summary.Requests.Count != 1 || report.Summary.Cases == nil || report.Summary.Cases.Count != 2 {
+		t.Errorf("Expected durations of completed request and 2 executed cases, got requests %+v, cases %+v", report.Summary.Requests, report.Summary.Cases)
+	} else if _, ok := report.Summary.Requests.Percentiles["p99"]; !ok {
+		t.Errorf("Expected p50/p90/p99 of requests, got %v", report.Summary.Requests.Percentiles)
+	}
+
+	if len(report.Suites) != 2 || report.Suites[0].Name != "health" || report.Suites[1].Package != "api" {
+		t.Fatalf("Unexpected suites: %+v", report.Suites)
+	}
+
+	failed := report.Suites[1].Cases[1]
+	if failed.Status != jsonStatusFailed || failed.Error != "Status code 500" || failed.Calls[0].Error != "Status code 500" {
+		t.Errorf("Unexpected failed case: %+v", failed)
+	}
+
+	passed := report.Suites[1].Cases[0]
+	if passed.Status != jsonStatusPassed || passed.Calls[0].Skipped["Header 'ETag'"] != "JIRA-2" {
+		t.Errorf("Expected skipped expectation of passed case, got: %+v", passed)
+	}
+
+	if passed.Calls[0].Warnings["Header 'Deprecation'"] != "Header 'Deprecation' is present" {
+		t.Errorf("Expected warning of passed case, got: %+v", passed.Calls[0])
+	}
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+// JUnitSuiteNames are values available in templates of suite name and package of junit report,
+// e.g. '{{.Env}}.{{.Package}}' prefixes package with environment of '--env'
+type JUnitSuiteNames struct {
+	// name of the suite, it is derived from the file name
+	Name string
+	// directory of the suite relative to the tests directory with dots, e.g. 'orders.create'
+	Package string
+	// package and name joined with dot
+	FullName string
+	// environment selected with '--env'
+	Env string
+}
+
+var junitTemplateFuncs = template.FuncMap{
+	// OS environment variable, e.g. {{env "CI_ENVIRONMENT"}}
+	"env": os.Getenv,
+}
+
+// ValidateJUnitTemplate checks template of suite name or package could be evaluated
+func ValidateJUnitTemplate(text string) error {
+	_, err := executeJUnitTemplate(text, JUnitSuiteNames{})
+	return err
+}
+
+func executeJUnitTemplate(text string, names JUnitSuiteNames) (string, error) {
+	tmpl, err := template.New("junit").Funcs(junitTemplateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("Invalid junit template '%s': %s", text, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, names); err != nil {
+		return "", fmt.Errorf("Invalid junit template '%s': %s", text, err)
+	}
+
+	return buf.String(), nil
+}
+
+// suiteNames returns name and package of the suite reshaped with templates of the reporter
+func (r *JUnitXMLReporter) suiteNames(suite TestSuite) (string, string) {
+	names := JUnitSuiteNames{Name: suite.Name, Package: suite.PackageName(), FullName: suite.FullName(), Env: options.Env}
+
+	name, pkg := names.Name, names.Package
+	if r.NameTemplate != "" {
+		name = mustExecuteJUnitTemplate(r.NameTemplate, names)
+	}
+	if r.PackageTemplate != "" {
+		pkg = mustExecuteJUnitTemplate(r.PackageTemplate, names)
+	}
+
+	return name, pkg
+}
+
+// mustExecuteJUnitTemplate panics like other failures of writing the report, templates are validated at startup
+func mustExecuteJUnitTemplate(text string, names JUnitSuiteNames) string {
+	value, err := executeJUnitTemplate(text, names)
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
@@ -0,0 +1,1421 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// SuiteFile describes location of the suite file.
+type SuiteFile struct {
+
+	// Path to the file.
+	Path string
+
+	// Base directory from file is loaded.
+	BaseDir string
+
+	// Ext is preserved to create 'clean' suite name
+	Ext string
+
+	// If true then skip all test cases in this suite
+	Ignored bool
+}
+
+// RelDir returns difference between Path and BaseDir.
+func (sf SuiteFile) RelDir() string {
+	dir, _ := filepath.Rel(sf.BaseDir, filepath.Dir(sf.Path))
+	return dir
+}
+
+// FullName returns full name of the suite defined in the file, see TestSuite.FullName
+func (sf SuiteFile) FullName() string {
+	return TestSuite{Name: strings.TrimSuffix(filepath.Base(sf.Path), sf.Ext), Dir: sf.RelDir()}.FullName()
+}
+
+// ToSuite method deserializes suite representation to the object model.
+func (sf SuiteFile) ToSuite() *TestSuite {
+	if sf.Path == "" {
+		return nil
+	}
+
+	path := sf.Path
+	info, err := os.Lstat(path)
+
+	if err != nil {
+		return nil
+	}
+
+	if info.IsDir() {
+		debug.Print("Ignore dir: " + sf.Path)
+		return nil
+	}
+
+	content, e := ioutil.ReadFile(path)
+
+	if e != nil {
+		fmt.Println("Cannot read file:", path, "Error: ", e.Error())
+		return nil
+	}
+
+	matchers, err := LoadMatchers(sf.BaseDir)
+	if err != nil {
+		fmt.Println("Cannot load named matchers:", err.Error())
+		return nil
+	}
+
+	content, err = matchers.resolveSuite(content)
+	if err != nil {
+		fmt.Println("Cannot resolve named matchers in file:", path, "Error: ", err.Error())
+		return nil
+	}
+
+	var def suiteDefinition
+	if isSuiteObject(content) {
+		err = json.Unmarshal(content, &def)
+	} else {
+		err = json.Unmarshal(content, &def.Cases)
+	}
+	if err != nil {
+		fmt.Println("Cannot parse file:", path, "Error: ", err.Error())
+		return nil
+	}
+
+	if err := def.Client.validate(); err != nil {
+		fmt.Println("Invalid client settings in file:", path, "Error: ", err.Error())
+		return nil
+	}
+
+	var cases []TestCase
+	for _, tc := range def.Cases {
+		if err := applyEnvExpectations(tc, options.Env); err != nil {
+			fmt.Println("Cannot apply expectations of environment in file:", path, "Error: ", err.Error())
+			return nil
+		}
+
+		if sf.Ignored {
+			msg := "Ignored suite"
+			tc.Ignore = &msg
+		}
+		cases = append(cases, *tc)
+	}
+
+	su := TestSuite{
+		Name:      strings.TrimSuffix(info.Name(), sf.Ext),
+		Dir:       sf.RelDir(),
+		BaseURL:   def.BaseURL,
+		Auth:      def.Auth,
+		UserAgent: def.UserAgent,
+		Client:    def.Client,
+		SkipIf:    def.SkipIf,
+		RunOnlyOn: def.RunOnlyOn,
+		Cases:     cases,
+	}
+
+	return &su
+}
+
+// suiteDefinition is an object form of the suite file, alternative to the plain array of test cases
+type suiteDefinition struct {
+	BaseURL   string         `json:"baseUrl"`
+	Auth      *Auth          `json:"auth"`
+	UserAgent string         `json:"userAgent"`
+	Client    *ClientOptions `json:"client"`
+	SkipIf    *SkipCondition `json:"skipIf"`
+	RunOnlyOn []string       `json:"runOnlyOn"`
+	Cases     []*TestCase    `json:"cases"`
+}
+
+// applyEnvExpectations overrides or adds expectations of calls declared for the environment in 'expectEnv'
+func applyEnvExpectations(tc *TestCase, env string) error {
+	if env == "" {
+		return nil
+	}
+
+	for i := range tc.Calls {
+		override, ok := tc.Calls[i].ExpectEnv[env]
+		if !ok {
+			continue
+		}
+
+		// maps are merged, other values are replaced
+		if err := decodeEnvExpectations(override, &tc.Calls[i].Expect); err != nil {
+			return fmt.Errorf("'%s' expectations of call #%d: %s", env, i+1, err)
+		}
+	}
+
+	return nil
+}
+
+func decodeEnvExpectations(data []byte, expect *Expect) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(expect)
+}
+
+// validateEnvExpectations checks expectations declared for the environment of the run,
+// so a misspelled one fails the suite file before any call instead of dropping the suite
+func validateEnvExpectations(suiteContent interface{}, env string) error {
+	if env == "" {
+		return nil
+	}
+
+	if def, ok := suiteContent.(map[string]interface{}); ok {
+		suiteContent = def["cases"]
+	}
+
+	cases, _ := suiteContent.([]interface{})
+	for _, c := range cases {
+		testCase, _ := c.(map[string]interface{})
+		calls, _ := testCase["calls"].([]interface{})
+		for i, call := range calls {
+			callMap, _ := call.(map[string]interface{})
+			envs, _ := callMap["expectEnv"].(map[string]interface{})
+			override, ok := envs[env]
+			if !ok {
+				continue
+			}
+
+			data, err := json.Marshal(override)
+			if err != nil {
+				return err
+			}
+
+			if err := decodeEnvExpectations(data, &Expect{}); err != nil {
+				return fmt.Errorf("Test case '%v': '%s' expectations of call #%d: %s", testCase["name"], env, i+1, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isSuiteObject(content []byte) bool {
+	trimmed := bytes.TrimSpace(content)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+// SuiteFileIterator is an interface to iterate over a set of suite files
+// in a given directory
+type SuiteFileIterator interface {
+	HasNext() bool
+	Next() *SuiteFile
+}
+
+// DirSuiteFileIterator iterates over all suite files inside of specified root folder.
+type DirSuiteFileIterator struct {
+	RootDir   string
+	SuiteExt  string
+	XSuiteExt string
+	// absolute paths of suite files to iterate over, all suite files if nil
+	Only map[string]bool
+	// absolute paths of suite files to skip
+	Exclude map[string]bool
+
+	files []SuiteFile
+	pos   int
+}
+
+func (ds *DirSuiteFileIterator) init() {
+	filepath.Walk(ds.RootDir, ds.addSuiteFile)
+	debug.Print("Collected: ", len(ds.files))
+}
+
+func (ds *DirSuiteFileIterator) addSuiteFile(path string, info os.FileInfo, err error) error {
+	if err != nil {
+		return nil
+	}
+
+	if info.IsDir() {
+		return nil
+	}
+
+	fileName := info.Name()
+
+	isSuite := strings.HasSuffix(fileName, ds.SuiteExt)
+	isXSuite := strings.HasSuffix(fileName, ds.XSuiteExt)
+
+	if !(isSuite || isXSuite) {
+		debug.Printf("Skipping non-suite file: %s\n", fileName)
+		return nil
+	}
+
+	if ds.Only != nil {
+		if abs, _ := filepath.Abs(path); !ds.Only[abs] {
+			debug.Printf("Skipping unchanged suite file: %s\n", fileName)
+			return nil
+		}
+	}
+
+	if abs, _ := filepath.Abs(path); ds.Exclude[abs] {
+		debug.Printf("Skipping excluded suite file: %s\n", fileName)
+		return nil
+	}
+
+	ext := ds.SuiteExt
+	if isXSuite {
+		ext = ds.XSuiteExt
+	}
+
+	ds.files = append(ds.files, SuiteFile{
+		Path:    path,
+		BaseDir: ds.RootDir,
+		Ext:     ext,
+		Ignored: isXSuite,
+	})
+
+	return nil
+}
+
+// HasNext returns true in case there is at least one more suite.
+func (ds *DirSuiteFileIterator) HasNext() bool {
+	return len(ds.files) > ds.pos
+}
+
+// Next returns next deserialized suite.
+func (ds *DirSuiteFileIterator) Next() *SuiteFile {
+	if len(ds.files) <= ds.pos {
+		return nil
+	}
+
+	file := ds.files[ds.pos]
+	ds.pos = ds.pos + 1
+	return &file
+}
+
+// NewSuiteLoader returns channel of suites that are read from specified folder.
+func NewSuiteLoader(rootDir, suiteExt, xsuiteExt string) <-chan TestSuite {
+	return newSuiteLoader(&DirSuiteFileIterator{RootDir: rootDir, SuiteExt: suiteExt, XSuiteExt: xsuiteExt})
+}
+
+func newSuiteLoader(source *DirSuiteFileIterator) <-chan TestSuite {
+	channel := make(chan TestSuite)
+
+	source.init()
+
+	go func() {
+		for source.HasNext() {
+			sf := source.Next()
+			if sf == nil {
+				continue
+			}
+			suite := sf.ToSuite()
+			if suite == nil {
+				continue
+			}
+
+			channel <- *suite
+		}
+
+		close(channel)
+	}()
+
+	return channel
+}
+
+// ValidateSuites detects syntax errors in all test suites in the root directory.
+func ValidateSuites(rootDir, suiteExt, xsuiteExt string) error {
+	source := &DirSuiteFileIterator{RootDir: rootDir, SuiteExt: suiteExt, XSuiteExt: xsuiteExt}
+	source.init()
+
+	matchers, err := LoadMatchers(rootDir)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]*SuiteFileError, 0)
+
+	// full suite name -> path of the file
+	suiteNames := make(map[string]string)
+
+	for source.HasNext() {
+		sf := source.Next()
+
+		if sf == nil {
+			continue
+		}
+
+		err := validateSuite(sf.Path)
+		if err == nil {
+			err = validateMatcherRefs(sf.Path, matchers)
+		}
+		if !options.AllowDuplicateNames {
+			name := sf.FullName()
+			if path, ok := suiteNames[name]; ok && err == nil {
+				err = fmt.Errorf("duplicate test suite name '%s', already defined in %s", name, path)
+			} else if !ok {
+				suiteNames[name] = sf.Path
+			}
+		}
+		if err != nil {
+			errs = append(errs, &SuiteFileError{SuiteFile: sf, err: err})
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return SuitesValidationError{errors: errs}
+}
+
+// SuiteFileError desctibes issue during loading of single test suite file
+type SuiteFileError struct {
+	SuiteFile *SuiteFile
+	err       error
+}
+
+func (e SuiteFileError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s: %s", e.SuiteFile.Path, e.err.Error())
+}
+
+// SuitesValidationError groups all errors for individual test suites
+type SuitesValidationError struct {
+	errors []*SuiteFileError
+}
+
+func (e SuitesValidationError) Error() string {
+	msg := make([]string, 0)
+	for _, err := range e.errors {
+		msg = append(msg, err.Error())
+	}
+
+	return strings.Join(msg, "\n")
+}
+
+func isSuite(path string) bool {
+	schemaLoader := gojsonschema.NewStringLoader(suiteShapeSchema)
+
+	path, _ = filepath.Abs(path)
+	documentLoader := gojsonschema.NewReferenceLoader("file:///" + path)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return false
+	}
+
+	return result.Valid()
+}
+
+func validateSuite(path string) error {
+
+	path, _ = filepath.Abs(path)
+	documentLoader := gojsonschema.NewReferenceLoader("file:///" + filepath.ToSlash(path))
+
+	return validateSuiteDetailed(documentLoader)
+}
+
+func validateMatcherRefs(path string, matchers Matchers) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	_, err = matchers.resolveSuite(content)
+	return err
+}
+
+func validateSuiteDetailed(documentLoader gojsonschema.JSONLoader) error {
+	schemaLoader := gojsonschema.NewStringLoader(suiteDetailedSchema)
+
+	result, err := gojsonschema.Validate(schemaLoader, documentLoader)
+	if err != nil {
+		return err
+	}
+
+	if !result.Valid() {
+		msg := make([]string, 0)
+		for _, desc := range result.Errors() {
+			msg = append(msg, fmt.Sprintf("Field: %s, Error: %s", desc.Field(), desc.Description()))
+		}
+
+		return errors.New(strings.Join(msg, "\n"))
+	}
+
+	suiteContent, err := documentLoader.LoadJSON()
+	if err != nil {
+		return err
+	}
+
+	if !options.AllowDuplicateNames {
+		err = validateDuplicateTestNamesInSuite(suiteContent)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := validateEnvExpectations(suiteContent, options.Env); err != nil {
+		return err
+	}
+
+	return validateCELExpressions(suiteContent)
+}
+
+func validateDuplicateTestNamesInSuite(suiteContent interface{}) error {
+
+	duplicateNames := make(map[string]bool)
+
+	var arr []interface{}
+
+	if def, ok := suiteContent.(map[string]interface{}); ok {
+		suiteContent = def["cases"]
+	}
+
+	arr, ok := suiteContent.([]interface{})
+	if !ok {
+		return errors.New("test suite is not an array")
+	}
+
+	usedNames := make(map[string]bool, len(arr))
+
+	for i := 0; i < len(arr); i++ {
+
+		tc, ok := arr[i].(map[string]interface{})
+		if !ok {
+			return errors.New("test case is not a map")
+		}
+
+		name, ok := tc["name"].(string)
+		if !ok {
+			return fmt.Errorf("test suite 'name' is not a string: %v", tc["name"])
+		}
+
+		if usedNames[name] {
+			duplicateNames[name] = true
+		} else {
+			usedNames[name] = true
+		}
+	}
+
+	if len(duplicateNames) > 0 {
+		keys := make([]string, 0, len(duplicateNames))
+		for k := range duplicateNames {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return fmt.Errorf("duplicate test case names: %v", keys)
+	}
+
+	return nil
+}
+
+// used to detect suite
+const suiteShapeSchema = `
+{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "oneOf": [
+    {
+      "$ref": "#/definitions/cases"
+    },
+    {
+      "type": "object",
+      "properties": {
+        "cases": {
+          "$ref": "#/definitions/cases"
+        }
+      },
+      "required": [
+        "cases"
+      ]
+    }
+  ],
+  "definitions": {
+    "cases": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "calls": {
+            "type": "array"
+          }
+        },
+        "required": [
+          "name",
+          "calls"
+        ]
+      }
+    }
+  }
+}
+`
+
+// used to validate suite
+const suiteDetailedSchema = `
+{
+  "$schema": "http://json-schema.org/draft-04/schema#",
+  "oneOf": [
+    {
+      "$ref": "#/definitions/cases"
+    },
+    {
+      "type": "object",
+      "properties": {
+        "baseUrl": {
+          "type": "string"
+        },
+        "auth": {
+          "$ref": "#/definitions/auth"
+        },
+        "userAgent": {
+          "type": "string"
+        },
+        "client": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "timeout": {
+              "type": "string",
+              "minLength": 1
+            },
+            "insecure": {
+              "type": "boolean"
+            },
+            "proxy": {
+              "type": "string",
+              "minLength": 1
+            },
+            "followRedirects": {
+              "type": "boolean"
+            }
+          }
+        },
+        "skipIf": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "env": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            "missingEnvVars": {
+              "type": "array",
+              "items": {
+                "type": "string"
+              }
+            },
+            "reason": {
+              "type": "string"
+            }
+          }
+        },
+        "runOnlyOn": {
+          "type": "array",
+          "minItems": 1,
+          "items": {
+            "type": "string"
+          }
+        },
+        "cases": {
+          "$ref": "#/definitions/cases"
+        }
+      },
+      "required": [
+        "cases"
+      ],
+      "additionalProperties": false
+    }
+  ],
+  "definitions": {
+    "cases": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "description": {
+            "type": "string"
+          },
+          "args": {
+            "type": "object",
+            "minProperties": 1,
+            "additionalProperties": {
+              "type": ["string", "number", "boolean", "null"]
+            }
+          },
+          "timing": {
+            "type": "object",
+            "additionalProperties": false,
+            "properties": {
+              "budget": {
+                "type": "string",
+                "minLength": 2
+              },
+              "between": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "additionalProperties": false,
+                  "required": ["from", "to", "max"],
+                  "properties": {
+                    "from": {
+                      "type": "integer",
+                      "minimum": 0
+                    },
+                    "to": {
+                      "type": "integer",
+                      "minimum": 0
+                    },
+                    "max": {
+                      "type": "string",
+                      "minLength": 2
+                    }
+                  }
+                }
+              },
+              "latency": {
+                "type": "array",
+                "items": {
+                  "type": "object",
+                  "additionalProperties": false,
+                  "required": ["max", "atLeast"],
+                  "properties": {
+                    "max": {
+                      "type": "string",
+                      "minLength": 2
+                    },
+                    "atLeast": {
+                      "type": "number",
+                      "minimum": 0,
+                      "maximum": 100
+                    }
+                  }
+                }
+              }
+            }
+          },
+          "ignore": {
+            "type": "string",
+            "minLength": 10
+          },
+          "calls": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "description": {
+                  "type": "string"
+                },
+                "args": {
+                  "type": "object",
+                  "minProperties": 1,
+                  "additionalProperties": {
+                    "type": ["string", "number", "boolean", "null"]
+                  }
+                },
+                "on": {
+                  "type": "object",
+                  "minProperties": 1,
+                  "properties": {
+                    "method": {
+                      "type": "string",
+                      "enum": [
+                        "GET",
+                        "POST",
+                        "PUT",
+                        "DELETE",
+                        "HEAD",
+                        "OPTIONS",
+                        "PATCH",
+                        "CONNECT",
+                        "TRACE"
+                      ]
+                    },
+                    "url": {
+                      "type": "string"
+                    },
+                    "headers": {
+                      "oneOf": [
+                        {
+                          "type": "object",
+                          "minProperties": 1,
+                          "additionalProperties": {
+                            "type": "string"
+                          }
+                        },
+                        {
+                          "type": "array",
+                          "minItems": 1,
+                          "items": {
+                            "type": "object",
+                            "additionalProperties": false,
+                            "properties": {
+                              "name": {
+                                "type": "string",
+                                "minLength": 1
+                              },
+                              "value": {
+                                "type": "string"
+                              }
+                            },
+                            "required": [
+                              "name",
+                              "value"
+                            ]
+                          }
+                        }
+                      ]
+                    },
+                    "params": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string"
+                      }
+                    },
+                    "body": {
+                      "oneOf": [
+                        {
+                          "type": "string"
+                        },
+                        {
+                          "type": "object"
+                        },
+                        {
+                          "type": "array"
+                        }
+                      ]
+                    },
+                    "bodyFile": {
+                      "type": "string"
+                    },
+                    "format": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "conditional": {
+                      "type": "boolean"
+                    },
+                    "followRedirects": {
+                      "type": "boolean"
+                    },
+                    "auth": {
+                      "$ref": "#/definitions/auth"
+                    },
+                    "websocket": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "properties": {
+                        "send": {
+                          "type": "string"
+                        },
+                        "timeout": {
+                          "type": "string"
+                        }
+                      }
+                    },
+                    "protobuf": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "required": ["descriptorSet"],
+                      "properties": {
+                        "descriptorSet": {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        "request": {
+                          "type": "string"
+                        },
+                        "response": {
+                          "type": "string"
+                        },
+                        "grpcWeb": {
+                          "type": "boolean"
+                        }
+                      }
+                    },
+                    "eventStream": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "properties": {
+                        "timeout": {
+                          "type": "string"
+                        },
+                        "maxEvents": {
+                          "type": "integer",
+                          "minimum": 1
+                        },
+                        "until": {
+                          "type": "object",
+                          "additionalProperties": false,
+                          "minProperties": 1,
+                          "properties": {
+                            "event": {
+                              "type": "string"
+                            },
+                            "data": {
+                              "type": "string"
+                            }
+                          }
+                        }
+                      }
+                    }
+                  },
+                  "required": [
+                    "method",
+                    "url"
+                  ],
+                  "additionalProperties": false
+                },
+                "expect": {
+                  "type": "object",
+                  "minProperties": 1,
+                  "properties": {
+                    "use": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "string"
+                      }
+                    },
+                    "statusText": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "statusCode": {
+                      "type": "integer"
+                    },
+                    "contentType": {
+                      "type": "string"
+                    },
+                    "charset": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "contentEncoding": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "sorted": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": false,
+                        "properties": {
+                          "by": {
+                            "type": "string"
+                          },
+                          "order": {
+                            "type": "string",
+                            "enum": [
+                              "asc",
+                              "desc"
+                            ]
+                          }
+                        }
+                      }
+                    },
+                    "anyMatch": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "object",
+                        "minProperties": 1
+                      }
+                    },
+                    "allMatch": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "object",
+                        "minProperties": 1
+                      }
+                    },
+                    "noneMatch": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "object",
+                        "minProperties": 1
+                      }
+                    },
+                    "unique": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string"
+                      }
+                    },
+                    "types": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string",
+                        "enum": ["number", "string", "boolean", "null", "array", "object"]
+                      }
+                    },
+                    "and": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "object",
+                        "minProperties": 1
+                      }
+                    },
+                    "or": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "object",
+                        "minProperties": 1
+                      }
+                    },
+                    "not": {
+                      "type": "object",
+                      "minProperties": 1
+                    },
+                    "coerceNumbers": {
+                      "type": "boolean"
+                    },
+                    "isJSON": {
+                      "type": "boolean"
+                    },
+                    "isXML": {
+                      "type": "boolean"
+                    },
+                    "bodyContains": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "bodyMatches": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "headers": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string"
+                      }
+                    },
+                    "headerCompare": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "object",
+                        "properties": {
+                          "name": {
+                            "type": "string",
+                            "minLength": 1
+                          },
+                          "op": {
+                            "type": "string",
+                            "enum": ["==", "!=", "<", "<=", ">", ">="]
+                          },
+                          "value": {
+                            "type": "string"
+                          },
+                          "type": {
+                            "type": "string",
+                            "enum": ["number", "duration", "date"]
+                          }
+                        },
+                        "required": ["name", "value"],
+                        "additionalProperties": false
+                      }
+                    },
+                    "headersAbsent": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "string"
+                      }
+                    },
+                    "securityHeaders": {
+                      "type": "boolean"
+                    },
+                    "openapi": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "required": ["specFile"],
+                      "properties": {
+                        "specFile": {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        "operationId": {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        "path": {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        "method": {
+                          "type": "string",
+                          "minLength": 1
+                        }
+                      }
+                    },
+                    "consistentWith": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "required": ["snapshot"],
+                      "properties": {
+                        "snapshot": {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        "ignore": {
+                          "type": "array",
+                          "items": {
+                            "type": "string"
+                          }
+                        }
+                      }
+                    },
+                    "cel": {
+                      "oneOf": [
+                        {
+                          "type": "string",
+                          "minLength": 1
+                        },
+                        {
+                          "type": "array",
+                          "items": {
+                            "type": "string",
+                            "minLength": 1
+                          }
+                        }
+                      ]
+                    },
+                    "increasing": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "string"
+                      }
+                    },
+                    "headerValues": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                          "type": "string"
+                        }
+                      }
+                    },
+                    "headerCount": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "integer",
+                        "minimum": 0
+                      }
+                    },
+                    "trailers": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string"
+                      }
+                    },
+                    "body": {
+                        "type": "object",
+                        "minProperties": 1
+                    },
+                    "exactBody": {
+                        "type": "object",
+                        "minProperties": 1
+                    },
+                    "oneOf": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": ["object", "array"]
+                      }
+                    },
+                    "compare": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "object",
+                        "additionalProperties": false,
+                        "required": ["left", "right"],
+                        "properties": {
+                          "left": {
+                            "type": "string",
+                            "minLength": 1
+                          },
+                          "op": {
+                            "type": "string",
+                            "enum": ["==", "!=", "<", "<=", ">", ">="]
+                          },
+                          "right": {
+                            "type": "string",
+                            "minLength": 1
+                          }
+                        }
+                      }
+                    },
+                    "location": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "minProperties": 1,
+                      "properties": {
+                        "equals": {
+                          "type": "string"
+                        },
+                        "prefix": {
+                          "type": "string"
+                        },
+                        "matches": {
+                          "type": "string"
+                        }
+                      }
+                    },
+                    "certificate": {
+                      "type": "object",
+                      "additionalProperties": false,
+                      "minProperties": 1,
+                      "properties": {
+                        "commonName": {
+                          "type": "string"
+                        },
+                        "san": {
+                          "type": "array",
+                          "items": {
+                            "type": "string"
+                          }
+                        },
+                        "issuer": {
+                          "type": "string"
+                        },
+                        "expiresInMoreThan": {
+                          "type": "string",
+                          "minLength": 2
+                        }
+                      }
+                    },
+                    "bodyPath": {
+                        "type": "object",
+                        "minProperties": 1
+                    },
+                    "bodySchema": {
+                        "type": "object"
+                    },
+                    "bodySchemaFile": {
+                      "type": "string"
+                    },
+                    "bodySchemaURI": {
+                      "type": "string"
+                    },
+                    "contentNegotiated": {
+                      "type": "boolean"
+                    },
+                    "notModified": {
+                      "type": "boolean"
+                    },
+                    "bodySize": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": false,
+                      "properties": {
+                        "bytes": {
+                          "type": "integer",
+                          "minimum": 0
+                        },
+                        "matchContentLength": {
+                          "type": "boolean"
+                        }
+                      }
+                    },
+                    "severity": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string",
+                        "enum": [
+                          "error",
+                          "warning"
+                        ]
+                      }
+                    },
+                    "disabled": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string",
+                        "minLength": 1
+                      }
+                    },
+                    "cookies": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "object",
+                        "properties": {
+                          "value": {"type": "string"},
+                          "valueRegex": {"type": "string"},
+                          "httpOnly": {"type": "boolean"},
+                          "secure": {"type": "boolean"},
+                          "sameSite": {"type": "string", "enum": ["Lax", "Strict", "None"]},
+                          "maxAge": {"type": "integer"}
+                        },
+                        "additionalProperties": false
+                      }
+                    },
+                    "absent": {
+                      "type": "array",
+                      "minItems": 1,
+                      "items": {
+                        "type": "string"
+                      }
+                    },
+                    "nullAsAbsent": {
+                      "type": "boolean"
+                    }
+                  },
+                  "additionalProperties": false
+                },
+                "extract": {
+                  "type": "string",
+                  "minLength": 1
+                },
+                "paginate": {
+                  "type": "object",
+                  "properties": {
+                    "itemsPath": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "nextPath": {
+                      "type": "string"
+                    },
+                    "maxPages": {
+                      "type": "integer",
+                      "minimum": 1
+                    },
+                    "expectPage": {
+                      "type": "object"
+                    }
+                  },
+                  "required": ["itemsPath"],
+                  "additionalProperties": false
+                },
+                "rateLimit": {
+                  "type": "object",
+                  "properties": {
+                    "requests": {
+                      "type": "integer",
+                      "minimum": 2
+                    },
+                    "limitAfter": {
+                      "type": "integer",
+                      "minimum": 1
+                    },
+                    "retryAfter": {
+                      "type": "boolean"
+                    }
+                  },
+                  "required": ["requests"],
+                  "additionalProperties": false
+                },
+                "retry": {
+                  "type": "object",
+                  "properties": {
+                    "attempts": {
+                      "type": "integer",
+                      "minimum": 1
+                    },
+                    "delay": {
+                      "type": "string"
+                    }
+                  },
+                  "required": ["attempts"],
+                  "additionalProperties": false
+                },
+                "expectEnv": {
+                  "type": "object",
+                  "minProperties": 1,
+                  "additionalProperties": {
+                    "type": "object"
+                  }
+                },
+                "matchMode": {
+                  "type": "string",
+                  "enum": ["all", "any"]
+                },
+                "remember": {
+                  "type": "object",
+                  "minProperties": 1,
+                  "properties": {
+                    "bodyPath": {
+                      "type": "object",
+                      "minProperties": 1
+                    },
+                    "headers": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string"
+                      }
+                    },
+                    "snapshot": {
+                      "type": "string",
+                      "minLength": 1
+                    },
+                    "timing": {
+                      "type": "object",
+                      "minProperties": 1,
+                      "additionalProperties": {
+                        "type": "string",
+                        "enum": ["duration", "ttfb"]
+                      }
+                    }
+                  },
+                  "additionalProperties": false
+                }
+              },
+              "required": ["on", "expect"],
+              "additionalProperties": false
+            }
+          }
+        },
+        "additionalProperties": false,
+        "required": [
+          "name", 
+          "calls"
+        ]
+      }
+    },
+    "auth": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "type": {
+          "type": "string",
+          "enum": ["digest", "ntlm"]
+        },
+        "username": {
+          "type": "string"
+        },
+        "password": {
+          "type": "string"
+        }
+      },
+      "required": ["type", "username"]
+    }
+  }
+}
+`
@@ -1,6 +1,12 @@
-package main
+package runner
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -381,3 +387,135 @@ func Test_validateSuiteShape(t *testing.T) {
 		})
 	}
 }
+
+func TestSuiteFileObjectForm(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{
+		"users.suite.json": `{
+			"baseUrl": "/users-service",
+			"cases": [{"name": "one", "calls": [{"on": {"method": "GET", "url": "/users"}, "expect": {"statusCode": 200}}]}]
+		}`,
+	})
+	defer os.RemoveAll(dir)
+
+	if err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt); err != nil {
+		t.Fatal(err)
+	}
+
+	suite := SuiteFile{Path: filepath.Join(dir, "users.suite.json"), BaseDir: dir, Ext: SuiteExt}.ToSuite()
+	if suite == nil {
+		t.Fatal("Suite is not loaded")
+	}
+
+	if suite.BaseURL != "/users-service" || len(suite.Cases) != 1 || suite.Cases[0].Name != "one" {
+		t.Errorf("Unexpected suite: %+v", suite)
+	}
+}
+
+func TestSuiteFileEnvExpectations(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.Header.Get("X-Env") == "prod" {
+			w.Write([]byte(`{"items": [1, 2, 3, 4, 5]}`))
+			return
+		}
+		w.Write([]byte(`{"items": [1, 2]}`))
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"items.suite.json": `[{
+			"name": "list items",
+			"calls": [{
+				"on": {"method": "GET", "url": "/items", "headers": {"X-Env": "{stage}"}},
+				"expect": {"statusCode": 200, "bodyPath": {"items.size()": 2}},
+				"expectEnv": {"prod": {"bodyPath": {"items.size()": 5}}}
+			}]
+		}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	for _, env := range []string{"dev", "prod"} {
+		// when
+		results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, Env: env, Vars: map[string]interface{}{"stage": env}})
+
+		// then
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results) != 1 || results[0].HasError() {
+			t.Errorf("Expected case to pass under %s environment, got %v", env, results)
+		}
+	}
+}
+
+func TestEnvExpectationsUnknownField(t *testing.T) {
+	tc := &TestCase{Calls: []Call{{ExpectEnv: map[string]json.RawMessage{"prod": []byte(`{"statusCod": 200}`)}}}}
+
+	err := applyEnvExpectations(tc, "prod")
+	if err == nil || !strings.Contains(err.Error(), `'prod' expectations of call #1: json: unknown field "statusCod"`) {
+		t.Error("Expected unknown field error, got:", err)
+	}
+}
+
+func TestRunFailsOnUnknownEnvExpectation(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"items.suite.json": `[{
+			"name": "list items",
+			"calls": [{
+				"on": {"method": "GET", "url": "/items"},
+				"expect": {"statusCode": 200},
+				"expectEnv": {"prod": {"statusCod": 500}}
+			}]
+		}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: "http://api.test", Env: "prod"})
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), `Test case 'list items': 'prod' expectations of call #1: json: unknown field "statusCod"`) {
+		t.Errorf("Expected run to fail on unknown field, got %v %v", results, err)
+	}
+}
+
+func TestValidateSuitesDuplicateNames(t *testing.T) {
+	testCase := `{"name": "one", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"statusCode": 200}}]}`
+
+	dir := writeTestFiles(t, map[string]string{
+		"users.suite.json":  "[" + testCase + "," + testCase + "]",
+		"users.xsuite.json": "[" + testCase + "]",
+	})
+	defer os.RemoveAll(dir)
+
+	t.Run("rejected by default", func(t *testing.T) {
+		err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt)
+		if err == nil {
+			t.Fatal("Expected duplicate names error")
+		}
+
+		msg := err.Error()
+		if !strings.Contains(msg, filepath.Join(dir, "users.suite.json")+": duplicate test case names: [one]") {
+			t.Error("Expected duplicate case names error with file location, got:", msg)
+		}
+
+		if !strings.Contains(msg, "duplicate test suite name 'users'") {
+			t.Error("Expected duplicate suite name error, got:", msg)
+		}
+	})
+
+	t.Run("allowed with flag", func(t *testing.T) {
+		options.AllowDuplicateNames = true
+		defer func() { options.AllowDuplicateNames = false }()
+
+		if err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt); err != nil {
+			t.Error(err)
+		}
+	})
+}
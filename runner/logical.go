@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// logical operators combining nested expectations
+const (
+	logicalAnd = "and"
+	logicalOr  = "or"
+	logicalNot = "not"
+)
+
+// LogicalExpectation combines expectations with 'and', 'or' or 'not' (single node).
+// Evaluation is short-circuited: 'and' stops on the first failed node, 'or' on the first met one.
+type LogicalExpectation struct {
+	Op    string
+	Nodes []ResponseExpectation
+}
+
+func (e LogicalExpectation) check(resp *Response) error {
+	switch e.Op {
+	case logicalAnd:
+		for _, node := range e.Nodes {
+			if err := node.check(resp); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case logicalOr:
+		failures := make([]string, 0, len(e.Nodes))
+		for _, node := range e.Nodes {
+			err := node.check(resp)
+			if err == nil {
+				return nil
+			}
+
+			failures = append(failures, fmt.Sprintf("(%s): %s", node.desc(), err))
+		}
+		return fmt.Errorf("None of %d alternatives is met:\n%s", len(e.Nodes), strings.Join(failures, "\n"))
+
+	case logicalNot:
+		if err := e.Nodes[0].check(resp); err != nil {
+			return nil
+		}
+		return fmt.Errorf("Expected not to be met: %s", e.Nodes[0].desc())
+	}
+
+	return fmt.Errorf("Unknown logical operator '%s'", e.Op)
+}
+
+func (e LogicalExpectation) desc() string {
+	if e.Op == logicalNot {
+		return "NOT (" + e.Nodes[0].desc() + ")"
+	}
+
+	descs := make([]string, len(e.Nodes))
+	for i, node := range e.Nodes {
+		descs[i] = node.desc()
+		if len(e.Nodes) > 1 {
+			descs[i] = "(" + descs[i] + ")"
+		}
+	}
+
+	return strings.Join(descs, " "+strings.ToUpper(e.Op)+" ")
+}
+
+// logicalNodes returns a node for every nested expect section, all of its expectations have to be met
+func logicalNodes(op string, sections []Expect, suitePath string) ([]ResponseExpectation, error) {
+	nodes := make([]ResponseExpectation, 0, len(sections))
+	for i, section := range sections {
+		exps, err := expectations(section, suitePath)
+		if err != nil {
+			return nil, err
+		}
+
+		switch len(exps) {
+		case 0:
+			return nil, fmt.Errorf("Expectation #%d of '%s' has no assertions", i+1, op)
+		case 1:
+			nodes = append(nodes, exps[0])
+		default:
+			nodes = append(nodes, LogicalExpectation{Op: logicalAnd, Nodes: exps})
+		}
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func logicalExpect(t *testing.T, data string) Expect {
+	var expect Expect
+	if err := json.Unmarshal([]byte(data), &expect); err != nil {
+		t.Fatal(err)
+	}
+	return expect
+}
+
+func TestLogicalExpectation(t *testing.T) {
+	tests := []struct {
+		name    string
+		expect  string
+		desc    string
+		message string
+	}{
+		{
+			name:   "and",
+			expect: `{"and": [{"statusCode": 200}, {"bodyPath": {"role": "admin"}}]}`,
+			desc:   "(Status code is 200) AND (Expected body's structure / values (1 checks))",
+		},
+		{
+			name:    "and failed",
+			expect:  `{"and": [{"statusCode": 201}, {"bodyPath": {"role": "admin"}}]}`,
+			message: "Unexpected Status Code. Expected: 201, Actual: 200",
+		},
+		{
+			name:   "or",
+			expect: `{"or": [{"bodyPath": {"role": "owner"}}, {"bodyPath": {"role": "admin"}}]}`,
+		},
+		{
+			name:    "or failed",
+			expect:  `{"or": [{"bodyPath": {"role": "owner"}}, {"statusCode": 201}]}`,
+			message: "None of 2 alternatives is met:\n(Expected body's structure / values (1 checks)): Value \"\\\"owner\\\"\" not found on path \"role\"\n(Status code is 201): Unexpected Status Code. Expected: 201, Actual: 200",
+		},
+		{
+			name:   "not",
+			expect: `{"not": {"bodyPath": {"role": "guest"}}}`,
+			desc:   "NOT (Expected body's structure / values (1 checks))",
+		},
+		{
+			name:    "not failed",
+			expect:  `{"not": {"bodyPath": {"role": "admin"}}}`,
+			message: "Expected not to be met: Expected body's structure / values (1 checks)",
+		},
+		{
+			name:   "nested",
+			expect: `{"and": [{"statusCode": 200}, {"or": [{"bodyPath": {"role": "admin"}}, {"bodyPath": {"role": "owner"}}]}]}`,
+			desc:   "(Status code is 200) AND ((Expected body's structure / values (1 checks)) OR (Expected body's structure / values (1 checks)))",
+		},
+		{
+			name:    "nested failed",
+			expect:  `{"and": [{"statusCode": 200}, {"not": {"or": [{"bodyPath": {"role": "admin"}}, {"bodyPath": {"role": "owner"}}]}}]}`,
+			message: "Expected not to be met: (Expected body's structure / values (1 checks)) OR (Expected body's structure / values (1 checks))",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exps, err := expectations(logicalExpect(t, tt.expect), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(exps) != 1 {
+				t.Fatalf("Expected single expectation, got %d", len(exps))
+			}
+
+			if tt.desc != "" && exps[0].desc() != tt.desc {
+				t.Errorf("Unexpected description: %s", exps[0].desc())
+			}
+
+			err = exps[0].check(testResponse(http.Header{"Content-Type": {"application/json"}}, `{"role": "admin"}`))
+			if tt.message == "" && err != nil {
+				t.Errorf("Expected to be met, got %s", err)
+			}
+			if tt.message != "" && (err == nil || err.Error() != tt.message) {
+				t.Errorf("Expected error %q, got %v", tt.message, err)
+			}
+		})
+	}
+}
+
+func TestLogicalExpectationShortCircuit(t *testing.T) {
+	// given
+	checked := 0
+	errTest := errors.New("failed")
+	counting := func(err error) ResponseExpectation {
+		return funcExpectation{fn: func() error { checked++; return err }}
+	}
+
+	and := LogicalExpectation{Op: logicalAnd, Nodes: []ResponseExpectation{counting(errTest), counting(nil)}}
+	or := LogicalExpectation{Op: logicalOr, Nodes: []ResponseExpectation{counting(nil), counting(errTest)}}
+
+	// when
+	and.check(nil)
+	or.check(nil)
+
+	// then
+	if checked != 2 {
+		t.Errorf("Expected only first node of 'and' and 'or' to be checked, checked %d", checked)
+	}
+}
+
+func TestCallLogicalExpectationWithVars(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"role": "owner"}`))
+	}))
+	defer server.Close()
+
+	vars := NewVars("")
+	if err := vars.AddAll(map[string]interface{}{"role": "owner"}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL},
+		Expect: logicalExpect(t, `{"or": [{"bodyPath": {"role": "admin"}}, {"bodyPath": {"role": "{role}"}}]}`),
+	}
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	for desc := range trace.ExpDesc {
+		if !strings.Contains(desc, " OR ") {
+			t.Errorf("Expected combined description, got %s", desc)
+		}
+	}
+}
+
+type funcExpectation struct {
+	fn func() error
+}
+
+func (e funcExpectation) check(resp *Response) error { return e.fn() }
+
+func (e funcExpectation) desc() string { return "func" }
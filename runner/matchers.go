@@ -1,4 +1,4 @@
-package main
+package runner
 
 import (
 	"fmt"
@@ -217,7 +217,7 @@ func (e NewBodyMatcher) check(body interface{}) error {
 	r := new(bodyDiffReporter)
 	r.strict = e.Strict
 
-	opts := cmp.Options{r}
+	opts := cmp.Options{cmp.Reporter(r)}
 
 	_ = cmp.Equal(e.ExpectedBody, body, opts...)
 	diff := r.String()
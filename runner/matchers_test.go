@@ -1,4 +1,4 @@
-package main
+package runner
 
 import (
 	"encoding/json"
@@ -1087,3 +1087,14 @@ func TestBodyMatch(t *testing.T) {
 		})
 	}
 }
+
+func TestNewBodyMatcherDiffPath(t *testing.T) {
+	expectation := NewBodyMatcher{ExpectedBody: parsedJSON(t, `{"items": [{"id": 1}, {"id": 2}]}`)}
+
+	err := expectation.check(parsedJSON(t, `{"items": [{"id": 1}, {"id": 3}]}`))
+
+	expected := "The body does not match expectations: \n\troot[\"items\"][1][\"id\"]:\n\t\tExpected: 2\n\t\tActual: 3\n\t"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected diff of JSON path without types, got: %v", err)
+	}
+}
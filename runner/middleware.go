@@ -0,0 +1,205 @@
+package runner
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RequestMiddleware modifies outgoing request right before it is sent, e.g. signs it.
+type RequestMiddleware func(req *http.Request) error
+
+func applyMiddlewares(req *http.Request, middlewares []RequestMiddleware) error {
+	for _, mw := range middlewares {
+		if err := mw(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+)
+
+// SigV4Signer signs requests with AWS Signature Version 4.
+// https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html
+type SigV4Signer struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	Service      string
+
+	// returns signing time, time.Now is used if not set
+	Now func() time.Time
+}
+
+// Sign adds authentication headers to the request.
+// Signature covers host, content-type and all x-amz-* headers.
+func (s SigV4Signer) Sign(req *http.Request) error {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+
+	t := now().UTC()
+	amzDate := t.Format(sigV4TimeFormat)
+	scope := strings.Join([]string{t.Format(sigV4DateFormat), s.Region, s.Service, "aws4_request"}, "/")
+
+	payloadHash, err := hashPayload(req)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+	if s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	canonicalHeaders, signedHeaders := s.canonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm,
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretKey), t.Format(sigV4DateFormat))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.AccessKey, scope, signedHeaders, signature))
+
+	return nil
+}
+
+func (s SigV4Signer) canonicalURI(u *url.URL) string {
+	uri := u.EscapedPath()
+	if uri == "" {
+		return "/"
+	}
+
+	if s.Service == "s3" {
+		return uri
+	}
+
+	// all services except S3 require double encoding of the path
+	return strings.Replace(url.PathEscape(uri), "%2F", "/", -1)
+}
+
+func (s SigV4Signer) canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	values := map[string]string{"host": host}
+	for name, vals := range req.Header {
+		lname := strings.ToLower(name)
+		if lname != "content-type" && !strings.HasPrefix(lname, "x-amz-") {
+			continue
+		}
+
+		trimmed := make([]string, 0, len(vals))
+		for _, v := range vals {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+		values[lname] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := strings.Builder{}
+	for _, name := range names {
+		buf.WriteString(name + ":" + values[name] + "\n")
+	}
+
+	return buf.String(), strings.Join(names, ";")
+}
+
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vals := query[k]
+		sort.Strings(vals)
+		for _, v := range vals {
+			pairs = append(pairs, awsEscape(k)+"="+awsEscape(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+func awsEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func hashPayload(req *http.Request) (string, error) {
+	if req.GetBody == nil {
+		return hexSHA256([]byte{}), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	dat, err := ioutil.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	return hexSHA256(dat), nil
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
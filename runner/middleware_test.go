@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// aws-sig-v4-test-suite: get-vanilla
+func TestSigV4SignGetVanilla(t *testing.T) {
+	// given
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+
+	signer := SigV4Signer{
+		AccessKey: "AKIDEXAMPLE",
+		SecretKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:    "us-east-1",
+		Service:   "service",
+		Now: func() time.Time {
+			return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+		},
+	}
+
+	// when
+	err := signer.Sign(req)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, " +
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Unexpected Authorization header.\nExpected: %s\nActual:   %s", expected, got)
+	}
+
+	if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+		t.Errorf("Unexpected X-Amz-Date: %s", got)
+	}
+}
+
+func TestSigV4SignSignedHeaders(t *testing.T) {
+	req, _ := http.NewRequest("POST", "https://api.example.com/items?b=2&a=1", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Custom", "not signed")
+
+	signer := SigV4Signer{AccessKey: "AK", SecretKey: "SK", SessionToken: "TOKEN", Region: "eu-west-1", Service: "execute-api"}
+
+	if err := signer.Sign(req); err != nil {
+		t.Fatal(err)
+	}
+
+	auth := req.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 ") {
+		t.Errorf("Unexpected Authorization header: %s", auth)
+	}
+
+	if !strings.Contains(auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("Unexpected signed headers: %s", auth)
+	}
+}
+
+func TestApplyMiddlewaresInOrder(t *testing.T) {
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	appendHeader := func(value string) RequestMiddleware {
+		return func(req *http.Request) error {
+			req.Header.Add("X-Order", value)
+			return nil
+		}
+	}
+
+	failing := func(req *http.Request) error {
+		return errors.New("stop")
+	}
+
+	err := applyMiddlewares(req, []RequestMiddleware{appendHeader("1"), appendHeader("2"), failing, appendHeader("3")})
+
+	if err == nil || err.Error() != "stop" {
+		t.Errorf("Expected middleware error, got: %v", err)
+	}
+
+	if got := strings.Join(req.Header["X-Order"], ","); got != "1,2" {
+		t.Errorf("Unexpected middleware execution order: %s", got)
+	}
+}
@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// marshalMsgPack serializes JSON values into MessagePack, integral numbers are written as integers
+func marshalMsgPack(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetSortMapKeys(true)
+	encoder.UseCompactInts(true)
+	if err := encoder.Encode(wireValue(v)); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalMsgPack parses MessagePack into JSON values, binary data becomes string
+func unmarshalMsgPack(data []byte) (interface{}, error) {
+	reader := bytes.NewReader(data)
+	decoder := msgpack.NewDecoder(reader)
+	decoder.UseLooseInterfaceDecoding(true)
+
+	v, err := decoder.DecodeInterface()
+	if err != nil {
+		return nil, fmt.Errorf("invalid MessagePack: %s", err)
+	}
+
+	if reader.Len() > 0 {
+		return nil, fmt.Errorf("invalid MessagePack: %d bytes after the value", reader.Len())
+	}
+
+	value, err := jsonValue(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MessagePack: %s", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,184 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MatchersFile is file with named matchers, located in the root directory of test suites
+const MatchersFile = "matchers.json"
+
+// key of 'expect' section that refers named matchers
+const useMatchersKey = "use"
+
+// Matchers are named reusable 'expect' definitions. Expectation refers them by name using "use" key,
+// e.g. "expect": { "use": ["errorEnvelope"], "statusCode": 400 }.
+// Expectations declared in place take precedence over expectations of referred matchers.
+type Matchers map[string]map[string]interface{}
+
+// LoadMatchers reads named matchers from the root directory of test suites.
+// Empty set is returned if there is no matchers file.
+func LoadMatchers(rootDir string) (Matchers, error) {
+	if info, err := os.Stat(rootDir); err == nil && !info.IsDir() {
+		rootDir = filepath.Dir(rootDir)
+	}
+
+	path := filepath.Join(rootDir, MatchersFile)
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Matchers{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matchers := Matchers{}
+	if err := json.Unmarshal(content, &matchers); err != nil {
+		return nil, fmt.Errorf("%s: Cannot parse named matchers: %s", path, err)
+	}
+
+	// all references have to be resolvable without cycles
+	for name := range matchers {
+		if _, err := matchers.resolveNamed(name, nil); err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return matchers, nil
+}
+
+// resolveSuite replaces references to named matchers in all expectations of the suite content.
+// Content is returned as is if there are no references.
+func (m Matchers) resolveSuite(content []byte) ([]byte, error) {
+	if isSuiteObject(content) {
+		var def map[string]json.RawMessage
+		if err := json.Unmarshal(content, &def); err != nil {
+			return nil, err
+		}
+
+		cases, err := m.resolveSuite(def["cases"])
+		if err != nil || bytes.Equal(cases, def["cases"]) {
+			return content, err
+		}
+
+		def["cases"] = cases
+		return json.Marshal(def)
+	}
+
+	var cases []map[string]json.RawMessage
+	if err := json.Unmarshal(content, &cases); err != nil {
+		return nil, err
+	}
+
+	modified := false
+	for _, tc := range cases {
+		var calls []map[string]json.RawMessage
+		if err := json.Unmarshal(tc["calls"], &calls); err != nil {
+			continue
+		}
+
+		for _, call := range calls {
+			var expect map[string]interface{}
+			if err := json.Unmarshal(call["expect"], &expect); err != nil {
+				continue
+			}
+
+			if _, ok := expect[useMatchersKey]; !ok {
+				continue
+			}
+
+			resolved, err := m.resolve(expect, nil)
+			if err != nil {
+				return nil, fmt.Errorf("test case %s: %s", tc["name"], err)
+			}
+
+			if call["expect"], err = json.Marshal(resolved); err != nil {
+				return nil, err
+			}
+			modified = true
+		}
+
+		var err error
+		if tc["calls"], err = json.Marshal(calls); err != nil {
+			return nil, err
+		}
+	}
+
+	if !modified {
+		return content, nil
+	}
+
+	return json.Marshal(cases)
+}
+
+func (m Matchers) resolve(expect map[string]interface{}, chain []string) (map[string]interface{}, error) {
+	refs, _ := expect[useMatchersKey].([]interface{})
+
+	resolved := make(map[string]interface{})
+	for _, ref := range refs {
+		name, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("Named matcher reference is not a string: %v", ref)
+		}
+
+		named, err := m.resolveNamed(name, chain)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved = mergeExpect(resolved, named)
+	}
+
+	own := make(map[string]interface{}, len(expect))
+	for key, val := range expect {
+		if key != useMatchersKey {
+			own[key] = val
+		}
+	}
+
+	return mergeExpect(resolved, own), nil
+}
+
+func (m Matchers) resolveNamed(name string, chain []string) (map[string]interface{}, error) {
+	for _, used := range chain {
+		if used == name {
+			return nil, fmt.Errorf("Cyclic reference of named matchers: %s -> %s", strings.Join(chain, " -> "), name)
+		}
+	}
+
+	expect, ok := m[name]
+	if !ok {
+		return nil, fmt.Errorf("Named matcher '%s' is not defined in %s", name, MatchersFile)
+	}
+
+	return m.resolve(expect, append(chain, name))
+}
+
+// mergeExpect merges override into base. Nested objects (e.g. 'headers', 'bodyPath') are merged key by key,
+// other values of override replace values of base.
+func mergeExpect(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(override))
+	for key, val := range base {
+		result[key] = val
+	}
+
+	for key, val := range override {
+		baseObj, baseIsObj := result[key].(map[string]interface{})
+		overrideObj, overrideIsObj := val.(map[string]interface{})
+
+		if baseIsObj && overrideIsObj && key != "body" && key != "exactBody" && key != "bodySchema" {
+			result[key] = mergeExpect(baseObj, overrideObj)
+			continue
+		}
+
+		result[key] = val
+	}
+
+	return result
+}
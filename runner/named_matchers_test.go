@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "bozr-matchers")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestNamedMatchersSharedByCases(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error": {"code": "` + strings.TrimPrefix(req.URL.Path, "/") + `", "message": "invalid"}}`))
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		MatchersFile: `{
+			"errorEnvelope": {
+				"use": ["json"],
+				"statusCode": 400,
+				"bodyPath": {"error.message": "invalid"}
+			},
+			"json": {
+				"contentType": "application/json"
+			}
+		}`,
+		"errors.suite.json": `[
+			{
+				"name": "missing name",
+				"calls": [{
+					"on": {"method": "GET", "url": "` + server.URL + `/name"},
+					"expect": {"use": ["errorEnvelope"], "bodyPath": {"error.code": "name"}}
+				}]
+			},
+			{
+				"name": "missing email",
+				"calls": [{
+					"on": {"method": "GET", "url": "` + server.URL + `/email"},
+					"expect": {"use": ["errorEnvelope"], "bodyPath": {"error.code": "email"}}
+				}]
+			}
+		]`,
+	})
+	defer os.RemoveAll(dir)
+
+	if err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt); err != nil {
+		t.Fatal(err)
+	}
+
+	sf := SuiteFile{Path: filepath.Join(dir, "errors.suite.json"), BaseDir: dir, Ext: SuiteExt}
+
+	// when
+	suite := sf.ToSuite()
+
+	// then
+	if suite == nil {
+		t.Fatal("Suite is not loaded")
+	}
+
+	for _, tc := range suite.Cases {
+		expect := tc.Calls[0].Expect
+		if expect.StatusCode != 400 || expect.ContentType != "application/json" || len(expect.BodyPath()) != 2 {
+			t.Errorf("Named matcher is not resolved in '%s': %+v", tc.Name, expect)
+		}
+	}
+
+	for _, result := range runSuite(context.Background(), *suite) {
+		if result.HasError() {
+			t.Errorf("Case '%s' failed: %s", result.Case.Name, result.Error())
+		}
+	}
+}
+
+func TestNamedMatchersInvalidReferences(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{
+			MatchersFile:   `{"json": {"contentType": "application/json"}}`,
+			"a.suite.json": `[{"name": "a", "calls": [{"on": {"method": "GET", "url": "/"}, "expect": {"use": ["errorEnvelope"]}}]}]`,
+		})
+		defer os.RemoveAll(dir)
+
+		err := ValidateSuites(dir, SuiteExt, IgnoredSuiteExt)
+		if err == nil || !strings.Contains(err.Error(), "Named matcher 'errorEnvelope' is not defined") {
+			t.Error("Expected missing reference error, got:", err)
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{
+			MatchersFile: `{"a": {"use": ["b"]}, "b": {"use": ["c"]}, "c": {"use": ["a"]}}`,
+		})
+		defer os.RemoveAll(dir)
+
+		_, err := LoadMatchers(dir)
+		if err == nil || !strings.Contains(err.Error(), "Cyclic reference of named matchers") {
+			t.Error("Expected cyclic reference error, got:", err)
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// OpenAPIAssert refers operation of OpenAPI 3 (or Swagger 2) document in JSON, response status and body are validated against it
+type OpenAPIAssert struct {
+	// path of the document relative to the suite file
+	SpecFile    string `json:"specFile"`
+	OperationID string `json:"operationId"`
+	// path template of the operation as in the document, e.g. '/users/{id}', alternative to OperationID
+	Path string `json:"path"`
+	// method of the operation with Path, method of the request if not set
+	Method string `json:"method"`
+}
+
+// OpenAPIExpectation validates response against documented responses of the operation.
+type OpenAPIExpectation struct {
+	OpenAPIAssert
+	spec map[string]interface{}
+}
+
+var openAPICache sync.Map
+
+// loadOpenAPISpec reads and parses the document once per run
+func loadOpenAPISpec(suitePath string, file string) (map[string]interface{}, error) {
+	path, err := toAbsPath(suitePath, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := openAPICache.Load(path); ok {
+		return cached.(map[string]interface{}), nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read OpenAPI document: %s", err)
+	}
+
+	var spec map[string]interface{}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("Invalid OpenAPI document %s: %s", file, err)
+	}
+
+	openAPICache.Store(path, spec)
+	return spec, nil
+}
+
+func (e OpenAPIExpectation) check(resp *Response) error {
+	op, name, err := e.operation(resp.http.Request)
+	if err != nil {
+		return err
+	}
+
+	responses, _ := op["responses"].(map[string]interface{})
+	response, code := documentedResponse(responses, resp.http.StatusCode)
+	if response == nil {
+		documented := make([]string, 0, len(responses))
+		for code := range responses {
+			documented = append(documented, code)
+		}
+		sort.Strings(documented)
+
+		return fmt.Errorf("Status %d is not documented for operation %s, documented: %s", resp.http.StatusCode, name, strings.Join(documented, ", "))
+	}
+
+	response, err = e.resolve(response)
+	if err != nil {
+		return err
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.http.Header.Get("Content-Type"))
+	schema, documented := responseSchema(response, contentType)
+	if !documented {
+		if len(resp.body) > 0 && response["content"] != nil {
+			return fmt.Errorf("Content-Type '%s' is not documented for operation %s, status %s", contentType, name, code)
+		}
+		return nil
+	}
+
+	if !isJSONMediaType(contentType) {
+		return fmt.Errorf("Unsupported content type: %s", contentType)
+	}
+
+	// references to components of the document are resolved within the schema
+	doc := map[string]interface{}{}
+	for key, value := range schema {
+		doc[key] = value
+	}
+	for _, key := range []string{"components", "definitions"} {
+		if value, ok := e.spec[key]; ok {
+			doc[key] = value
+		}
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(doc), gojsonschema.NewBytesLoader(resp.body))
+	if err != nil {
+		return fmt.Errorf("Can't validate against OpenAPI schema of %s, status %s: %s", name, code, err)
+	}
+
+	if !result.Valid() {
+		msg := fmt.Sprintf("Response does not match OpenAPI schema of %s, status %s:", name, code)
+		for _, desc := range result.Errors() {
+			msg = fmt.Sprintf(msg+"\n\t%s", desc)
+		}
+		return errors.New(msg)
+	}
+
+	return nil
+}
+
+func (e OpenAPIExpectation) desc() string {
+	return fmt.Sprintf("Response matches OpenAPI operation %s (%s)", e.name(""), e.SpecFile)
+}
+
+func (e OpenAPIExpectation) name(method string) string {
+	if e.OperationID != "" {
+		return e.OperationID
+	}
+
+	if e.Method != "" {
+		method = e.Method
+	}
+	return strings.TrimSpace(strings.ToUpper(method) + " " + e.Path)
+}
+
+// operation finds operation object by id or path and method
+func (e OpenAPIExpectation) operation(req *http.Request) (map[string]interface{}, string, error) {
+	paths, _ := e.spec["paths"].(map[string]interface{})
+
+	if e.OperationID != "" {
+		for _, item := range paths {
+			ops, _ := item.(map[string]interface{})
+			for _, op := range ops {
+				if op, ok := op.(map[string]interface{}); ok && op["operationId"] == e.OperationID {
+					return op, e.OperationID, nil
+				}
+			}
+		}
+
+		return nil, "", fmt.Errorf("Operation '%s' is not found in %s", e.OperationID, e.SpecFile)
+	}
+
+	method := e.Method
+	if method == "" && req != nil {
+		method = req.Method
+	}
+	name := e.name(method)
+
+	item, _ := paths[e.Path].(map[string]interface{})
+	op, ok := item[strings.ToLower(method)].(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("Operation %s is not found in %s", name, e.SpecFile)
+	}
+
+	return op, name, nil
+}
+
+// resolve follows '$ref' of the object within the document, e.g. '#/components/responses/NotFound'
+func (e OpenAPIExpectation) resolve(obj map[string]interface{}) (map[string]interface{}, error) {
+	ref, ok := obj["$ref"].(string)
+	if !ok {
+		return obj, nil
+	}
+
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("Unsupported reference '%s', only references within the document are supported", ref)
+	}
+
+	var current interface{} = e.spec
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Reference '%s' is not found", ref)
+		}
+		current = m[token]
+	}
+
+	resolved, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Reference '%s' is not found", ref)
+	}
+
+	return e.resolve(resolved)
+}
+
+// documentedResponse finds response of the exact status, range of statuses (e.g. '2XX') or default one
+func documentedResponse(responses map[string]interface{}, status int) (map[string]interface{}, string) {
+	code := strconv.Itoa(status)
+	for _, key := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, ok := responses[key].(map[string]interface{}); ok {
+			return response, key
+		}
+	}
+
+	return nil, ""
+}
+
+// responseSchema returns schema of the response body of the media type (OpenAPI 3) or the only schema (Swagger 2)
+func responseSchema(response map[string]interface{}, contentType string) (map[string]interface{}, bool) {
+	if schema, ok := response["schema"].(map[string]interface{}); ok {
+		return schema, true
+	}
+
+	content, _ := response["content"].(map[string]interface{})
+	mainType := strings.SplitN(contentType, "/", 2)[0]
+	for _, key := range []string{contentType, mainType + "/*", "*/*"} {
+		if media, ok := content[key].(map[string]interface{}); ok {
+			schema, ok := media["schema"].(map[string]interface{})
+			return schema, ok
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,94 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const testOpenAPISpec = `{
+  "openapi": "3.0.3",
+  "info": {"title": "users", "version": "1.0"},
+  "paths": {
+    "/users/{id}": {
+      "get": {
+        "operationId": "getUser",
+        "responses": {
+          "200": {
+            "description": "User",
+            "content": {"application/json": {"schema": {"$ref": "#/components/schemas/User"}}}
+          },
+          "404": {"$ref": "#/components/responses/NotFound"}
+        }
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "User": {
+        "type": "object",
+        "required": ["id", "name"],
+        "properties": {"id": {"type": "integer"}, "name": {"type": "string"}}
+      }
+    },
+    "responses": {
+      "NotFound": {"description": "Not found"}
+    }
+  }
+}`
+
+func TestOpenAPIExpectation(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/users/1":
+			w.Write([]byte(`{"id": 1, "name": "John"}`))
+		case "/users/2":
+			w.Write([]byte(`{"id": "two"}`))
+		case "/users/3":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusTeapot)
+		}
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{"openapi.json": testOpenAPISpec})
+	defer os.RemoveAll(dir)
+	options.Path = dir
+
+	byID := &OpenAPIAssert{SpecFile: "openapi.json", OperationID: "getUser"}
+	byPath := &OpenAPIAssert{SpecFile: "openapi.json", Path: "/users/{id}"}
+
+	for _, tc := range []struct {
+		url     string
+		openapi *OpenAPIAssert
+		err     string
+	}{
+		{url: "/users/1", openapi: byID},
+		{url: "/users/1", openapi: byPath},
+		{url: "/users/3", openapi: byID},
+		{url: "/users/2", openapi: byID, err: "Response does not match OpenAPI schema of getUser, status 200:\n\t(root): name is required\n\tid: Invalid type. Expected: integer, given: string"},
+		{url: "/users/5", openapi: byPath, err: "Status 418 is not documented for operation GET /users/{id}, documented: 200, 404"},
+		{url: "/users/1", openapi: &OpenAPIAssert{SpecFile: "openapi.json", OperationID: "deleteUser"}, err: "Operation 'deleteUser' is not found in openapi.json"},
+	} {
+		c := Call{On: On{Method: "GET", URL: server.URL + tc.url}, Expect: Expect{OpenAPI: tc.openapi}}
+
+		// when
+		trace := call("", c, NewVars(""))
+
+		// then
+		if tc.err == "" && trace.HasError() {
+			t.Errorf("%s: unexpected error %s", tc.url, trace.ErrorCause)
+		}
+
+		if tc.err != "" && (!trace.HasError() || !strings.HasPrefix(trace.ErrorCause.Error(), tc.err)) {
+			t.Errorf("%s: expected error '%s', got %v", tc.url, tc.err, trace.ErrorCause)
+		}
+	}
+}
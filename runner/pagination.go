@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DefaultMaxPages limits number of pages followed if Pagination.MaxPages is not set
+const DefaultMaxPages = 10
+
+// Pagination follows links to next pages of the response and accumulates their items.
+// Expectations of the call are checked against JSON object with accumulated 'items' and number of 'pages'.
+type Pagination struct {
+	// path of the array of items in the page body
+	ItemsPath string `json:"itemsPath"`
+	// path of the next page URL in the page body, 'Link' header with rel="next" is used if empty
+	NextPath string `json:"nextPath"`
+	// max number of requested pages including the first one
+	MaxPages int `json:"maxPages"`
+	// expectations checked for every page
+	ExpectPage *Expect `json:"expectPage"`
+}
+
+func (p *Pagination) maxPages() int {
+	if p.MaxPages > 0 {
+		return p.MaxPages
+	}
+	return DefaultMaxPages
+}
+
+// follow requests next pages after the first one, result is aggregated response of all pages
+func (p *Pagination) follow(client *http.Client, req *http.Request, first Response, suitePath string, vars *Vars) (Response, error) {
+	if p.ItemsPath == "" {
+		return first, errors.New("Pagination 'itemsPath' is required")
+	}
+
+	var pageExps []ResponseExpectation
+	if p.ExpectPage != nil {
+		expect := *p.ExpectPage
+		if err := expect.populateWith(vars); err != nil {
+			return first, err
+		}
+
+		exps, err := expectations(expect, suitePath)
+		if err != nil {
+			return first, err
+		}
+		pageExps = exps
+	}
+
+	items := []interface{}{}
+	page := first
+	pages := 1
+	for {
+		for _, exp := range pageExps {
+			if err := exp.check(&page); err != nil {
+				return page, pageFailure{fmt.Errorf("Page #%d (%s): %s", pages, page.http.Request.URL, err)}
+			}
+		}
+
+		body, err := page.Body()
+		if err != nil {
+			return page, fmt.Errorf("Page #%d: can't parse body: %s", pages, err)
+		}
+
+		pageItems, err := GetByPath(body, p.ItemsPath)
+		if err != nil {
+			return page, fmt.Errorf("Page #%d: %s", pages, err)
+		}
+
+		arr, ok := pageItems.([]interface{})
+		if !ok {
+			return page, fmt.Errorf("Page #%d: expected array on path [%s], got %v", pages, p.ItemsPath, pageItems)
+		}
+		items = append(items, arr...)
+
+		next := p.nextURL(page, body)
+		if next == "" || pages >= p.maxPages() {
+			break
+		}
+
+		nextURL, err := page.http.Request.URL.Parse(next)
+		if err != nil {
+			return page, fmt.Errorf("Page #%d: invalid next page URL '%s': %s", pages, next, err)
+		}
+
+		nextReq, err := http.NewRequest(http.MethodGet, nextURL.String(), nil)
+		if err != nil {
+			return page, err
+		}
+		nextReq = nextReq.WithContext(req.Context())
+		nextReq.Header = req.Header.Clone()
+
+		if err = applyMiddlewares(nextReq, options.Middlewares); err != nil {
+			return page, err
+		}
+
+		resp, err := client.Do(nextReq)
+		if err != nil {
+			return page, classifyTransportError(err)
+		}
+
+		data, truncated, err := readBody(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return page, err
+		}
+		if truncated {
+			return page, responseTooLarge()
+		}
+
+		if page, err = newResponse(resp, data); err != nil {
+			return page, err
+		}
+		pages++
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"items": items, "pages": pages})
+	if err != nil {
+		return page, err
+	}
+
+	aggregated := *page.http
+	aggregated.Header = page.http.Header.Clone()
+	aggregated.Header.Set("Content-Type", "application/json")
+	aggregated.Header.Del("Content-Length")
+
+	return newResponse(&aggregated, data)
+}
+
+// pageFailure is unmet expectation of a page as opposed to errors of requesting it
+type pageFailure struct {
+	error
+}
+
+// nextURL finds link to the next page in the body or 'Link' header, empty if it is the last page
+func (p *Pagination) nextURL(page Response, body interface{}) string {
+	if p.NextPath == "" {
+		return linkNext(page.http.Header)
+	}
+
+	next, err := GetByPath(body, p.NextPath)
+	if err != nil || next == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", next)
+}
+
+var linkNextPattern = regexp.MustCompile(`^\s*<([^>]*)>(.*)$`)
+var relNextPattern = regexp.MustCompile(`(?i);\s*rel="?([^";]*\s)?next(\s[^";]*)?"?\s*(;|$)`)
+
+// linkNext returns URL of 'Link' header with rel="next", e.g. '</items?page=2>; rel="next"'
+func linkNext(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			match := linkNextPattern.FindStringSubmatch(link)
+			if match != nil && relNextPattern.MatchString(match[2]) {
+				return match[1]
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newPagedServer serves three pages of items, next page is linked in 'next' field and 'Link' header
+func newPagedServer() *httptest.Server {
+	pages := [][]int{{1, 2}, {3, 4}, {5}}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		page, _ := strconv.Atoi(req.URL.Query().Get("page"))
+		if page == 0 {
+			page = 1
+		}
+
+		next := "null"
+		if page < len(pages) {
+			next = fmt.Sprintf(`"/items?page=%d"`, page+1)
+			w.Header().Set("Link", fmt.Sprintf(`</items?page=1>; rel="first", </items?page=%d>; rel="next"`, page+1))
+		}
+
+		items := strings.Trim(strings.Join(strings.Fields(fmt.Sprint(pages[page-1])), ","), "[]")
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"page": %d, "items": [%s], "next": %s}`, page, items, next)
+	}))
+}
+
+func TestPaginationFollowsNextField(t *testing.T) {
+	// given
+	server := newPagedServer()
+	defer server.Close()
+
+	c := Call{
+		On:       On{Method: "GET", URL: server.URL + "/items"},
+		Paginate: &Pagination{ItemsPath: "items", NextPath: "next"},
+		Expect:   Expect{StatusCode: 200, BPath: map[string]interface{}{"items.size()": 5.0, "pages": 3.0}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+}
+
+func TestPaginationFollowsLinkHeader(t *testing.T) {
+	// given
+	server := newPagedServer()
+	defer server.Close()
+
+	c := Call{
+		On:       On{Method: "GET", URL: server.URL + "/items"},
+		Paginate: &Pagination{ItemsPath: "items", MaxPages: 2},
+		Expect:   Expect{BPath: map[string]interface{}{"items.size()": 4.0, "pages": 2.0}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+}
+
+func TestPaginationPageExpectationFails(t *testing.T) {
+	// given
+	server := newPagedServer()
+	defer server.Close()
+
+	c := Call{
+		On:       On{Method: "GET", URL: server.URL + "/items"},
+		Paginate: &Pagination{ItemsPath: "items", NextPath: "next", ExpectPage: &Expect{BPath: map[string]interface{}{"items.size()": 2.0}}},
+		Expect:   Expect{StatusCode: 200},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() || !strings.HasPrefix(trace.ErrorCause.Error(), "Page #3") {
+		t.Errorf("Expected failure of the third page, got %v", trace.ErrorCause)
+	}
+}
+
+func TestPaginationPageTooLarge(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Query().Get("page") == "" {
+			fmt.Fprint(w, `{"items": [1], "next": "/items?page=2"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"items": [%s], "next": null}`, strings.Repeat("2,", 50)+"2")
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+	options.MaxResponseSize = 64
+
+	c := Call{
+		On:       On{Method: "GET", URL: server.URL + "/items"},
+		Paginate: &Pagination{ItemsPath: "items", NextPath: "next"},
+		Expect:   Expect{StatusCode: 200},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.ErrorCause == nil || trace.ErrorCause.Error() != responseTooLarge().Error() {
+		t.Errorf("Expected max size error of the second page, got %v", trace.ErrorCause)
+	}
+}
+
+func TestLinkNext(t *testing.T) {
+	header := http.Header{"Link": {`<https://api.test/items?page=1>; rel="prev", <https://api.test/items?page=3>; rel="next last"`}}
+
+	if next := linkNext(header); next != "https://api.test/items?page=3" {
+		t.Errorf("Unexpected next link: %s", next)
+	}
+
+	if next := linkNext(http.Header{"Link": {`</items?page=1>; rel="prev"`}}); next != "" {
+		t.Errorf("Unexpected next link: %s", next)
+	}
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// RunSuiteFunc describes particular test suite execution. Passed here to deleniate parallelism from suite execution logic
+type RunSuiteFunc func(ctx context.Context, suite TestSuite) []TestResult
+
+// RunParallel starts parallel routines to execute test suites received from loader channel.
+// Once ctx is cancelled test cases that are not started yet are reported as skipped,
+// results collected so far are reported and flushed as usual.
+func RunParallel(ctx context.Context, loader <-chan TestSuite, reporter Reporter, runSuite RunSuiteFunc, numRoutines int) {
+
+	resultConsumer := make(chan []TestResult)
+
+	var wg sync.WaitGroup
+	wg.Add(numRoutines)
+
+	for i := 0; i < numRoutines; i++ {
+		go runSuites(ctx, loader, resultConsumer, &wg, runSuite)
+	}
+
+	// Start a goroutine to close out once all the output goroutines are
+	// done.  This must start after the wg.Add call.
+	go func() {
+		wg.Wait()
+		close(resultConsumer)
+	}()
+
+	for {
+		results, more := <-resultConsumer
+
+		reporter.Report(results)
+
+		if !more {
+			break
+		}
+	}
+
+	reporter.Flush()
+}
+
+func runSuites(ctx context.Context, loader <-chan TestSuite, resultConsumer chan<- []TestResult, wg *sync.WaitGroup, runSuite RunSuiteFunc) {
+
+	for suite := range loader {
+		resultConsumer <- runSuite(ctx, suite)
+	}
+
+	wg.Done()
+}
+
+type abortKey struct{}
+
+const (
+	abortInterrupted = "aborted: interrupted"
+	abortMaxFailures = "aborted: max failures reached"
+)
+
+// Abort stops the run and keeps the reason to report test cases that were not executed
+type Abort struct {
+	// run is stopped once number of failed test cases reaches the limit, 0 means no limit
+	MaxFailures int
+	// run is stopped on the first transport error, e.g. refused connection, failed expectations do not stop it
+	BailOnError bool
+
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	reason   string
+	failures int
+}
+
+// WithAbort returns context that is cancelled once Abort.Stop is called
+func WithAbort(parent context.Context) (context.Context, *Abort) {
+	ctx, cancel := context.WithCancel(parent)
+
+	abort := &Abort{cancel: cancel}
+	return context.WithValue(ctx, abortKey{}, abort), abort
+}
+
+// Stop cancels the run. Only the first reason is preserved.
+func (a *Abort) Stop(reason string) {
+	a.mu.Lock()
+	if a.reason == "" {
+		a.reason = reason
+	}
+	a.mu.Unlock()
+
+	a.cancel()
+}
+
+// Fail counts failed test case and stops the run once MaxFailures is reached
+func (a *Abort) Fail() {
+	a.mu.Lock()
+	a.failures++
+	reached := a.MaxFailures > 0 && a.failures >= a.MaxFailures
+	a.mu.Unlock()
+
+	if reached {
+		a.Stop(abortMaxFailures)
+	}
+}
+
+// Error stops the run on transport error if BailOnError is set
+func (a *Abort) Error(err *TransportError) {
+	if a.BailOnError {
+		a.Stop("aborted: " + err.Error())
+	}
+}
+
+// Failures returns number of failed test cases reported so far
+func (a *Abort) Failures() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.failures
+}
+
+// Reason describes why the run was stopped
+func (a *Abort) Reason() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.reason
+}
+
+// reportFailure notifies run abort (if any) about failed test case
+func reportFailure(ctx context.Context, result TestResult) {
+	abort, ok := ctx.Value(abortKey{}).(*Abort)
+	if !ok {
+		return
+	}
+
+	var transportErr *TransportError
+	if trace := result.failedTrace(); trace != nil && errors.As(trace.ErrorCause, &transportErr) {
+		abort.Error(transportErr)
+	}
+
+	abort.Fail()
+}
+
+// abortReason returns the reason of run cancellation to be used as skip message
+func abortReason(ctx context.Context) string {
+	if abort, ok := ctx.Value(abortKey{}).(*Abort); ok && abort.Reason() != "" {
+		return abort.Reason()
+	}
+
+	return "aborted: " + ctx.Err().Error()
+}
+
+// WithStopTimeout returns context for in-flight requests. It is cancelled once grace period passes after ctx is done,
+// so requests started before the run is stopped have a chance to complete.
+func WithStopTimeout(ctx context.Context, grace time.Duration) (context.Context, context.CancelFunc) {
+	requestCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-requestCtx.Done():
+			return
+		}
+
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+			cancel()
+		case <-requestCtx.Done():
+		}
+	}()
+
+	return requestCtx, cancel
+}
@@ -0,0 +1,246 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingReporter struct {
+	mu      sync.Mutex
+	results []TestResult
+	flushed bool
+}
+
+func (r *recordingReporter) Init() {}
+
+func (r *recordingReporter) Report(results []TestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, results...)
+}
+
+func (r *recordingReporter) Flush() {
+	r.flushed = true
+}
+
+func TestRunParallelAbortFlushesPartialResults(t *testing.T) {
+	// given
+	ctx, abort := WithAbort(context.Background())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		abort.Stop("aborted: interrupted")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	newCase := func(name string) TestCase {
+		return TestCase{Name: name, Calls: []Call{{On: On{Method: "GET", URL: server.URL}, Expect: Expect{StatusCode: 200}}}}
+	}
+
+	loader := make(chan TestSuite, 2)
+	loader <- TestSuite{Name: "first", Cases: []TestCase{newCase("one"), newCase("two")}}
+	loader <- TestSuite{Name: "second", Cases: []TestCase{newCase("three")}}
+	close(loader)
+
+	reporter := &recordingReporter{}
+
+	// when
+	RunParallel(ctx, loader, reporter, runSuite, 1)
+
+	// then
+	if !reporter.flushed {
+		t.Error("Reporter is not flushed after abort")
+	}
+
+	if len(reporter.results) != 3 {
+		t.Fatalf("Expected all cases to be reported, got %d", len(reporter.results))
+	}
+
+	executed := reporter.results[0]
+	if executed.Skipped || executed.HasError() {
+		t.Errorf("First case is expected to pass, got: %+v", executed)
+	}
+
+	for _, result := range reporter.results[1:] {
+		if !result.Skipped || result.SkippedMsg != "aborted: interrupted" {
+			t.Errorf("Case '%s' is expected to be skipped due to abort, got: %+v", result.Case.Name, result)
+		}
+	}
+}
+
+func TestAbortKeepsFirstReason(t *testing.T) {
+	ctx, abort := WithAbort(context.Background())
+
+	abort.Stop("first")
+	abort.Stop("second")
+
+	if ctx.Err() == nil {
+		t.Error("Context is not cancelled")
+	}
+
+	if got := abortReason(ctx); got != "first" {
+		t.Errorf("Unexpected abort reason: %s", got)
+	}
+}
+
+func TestRunParallelMaxFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	newSuite := func(name string, size int) TestSuite {
+		suite := TestSuite{Name: name}
+		for i := 0; i < size; i++ {
+			suite.Cases = append(suite.Cases, TestCase{
+				Name:  fmt.Sprintf("%s-%d", name, i),
+				Calls: []Call{{On: On{Method: "GET", URL: server.URL}, Expect: Expect{StatusCode: 200}}},
+			})
+		}
+		return suite
+	}
+
+	for _, workers := range []int{1, 3} {
+		t.Run(fmt.Sprintf("workers %d", workers), func(t *testing.T) {
+			// given
+			ctx, abort := WithAbort(context.Background())
+			abort.MaxFailures = 2
+
+			loader := make(chan TestSuite, 3)
+			for _, name := range []string{"first", "second", "third"} {
+				loader <- newSuite(name, 4)
+			}
+			close(loader)
+
+			reporter := &recordingReporter{}
+
+			// when
+			RunParallel(ctx, loader, reporter, runSuite, workers)
+
+			// then
+			if len(reporter.results) != 12 {
+				t.Fatalf("Expected all cases to be reported, got %d", len(reporter.results))
+			}
+
+			failed := 0
+			for _, result := range reporter.results {
+				if result.Skipped {
+					if result.SkippedMsg != abortMaxFailures {
+						t.Errorf("Unexpected skip reason of '%s': %s", result.Case.Name, result.SkippedMsg)
+					}
+					continue
+				}
+				failed++
+			}
+
+			// cases already running in other workers are finished
+			if failed < 2 || failed > 2+workers-1 {
+				t.Errorf("Expected run to stop after 2nd failure, failed: %d", failed)
+			}
+		})
+	}
+}
+
+func TestStopTimeoutGracePeriod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	defer func(timeout time.Duration) { options.StopTimeout = timeout }(options.StopTimeout)
+
+	suite := TestSuite{Cases: []TestCase{
+		{Name: "slow", Calls: []Call{{On: On{Method: "GET", URL: server.URL}, Expect: Expect{StatusCode: 200}}}},
+	}}
+
+	run := func(grace time.Duration) (TestResult, time.Duration) {
+		options.StopTimeout = grace
+
+		ctx, abort := WithAbort(context.Background())
+		time.AfterFunc(20*time.Millisecond, func() { abort.Stop(abortInterrupted) })
+
+		start := time.Now()
+		results := runSuite(ctx, suite)
+		return results[0], time.Since(start)
+	}
+
+	t.Run("in-flight request completes within grace period", func(t *testing.T) {
+		result, _ := run(time.Second)
+		if result.HasError() {
+			t.Error("Expected request to complete, got:", result.Error())
+		}
+	})
+
+	t.Run("in-flight request is cancelled after grace period", func(t *testing.T) {
+		result, elapsed := run(50 * time.Millisecond)
+		if !result.HasError() || !strings.Contains(result.Error(), "stop timeout exceeded") {
+			t.Error("Expected request to be cancelled, got:", result.Error())
+		}
+
+		if elapsed >= 200*time.Millisecond {
+			t.Errorf("Expected request to be cancelled before completion, took %s", elapsed)
+		}
+	})
+}
+
+func TestRunBailOnError(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	down.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"a.suite.json": `[
+			{"name": "failed expectation", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/users"}, "expect": {"statusCode": 200}}]},
+			{"name": "still executed", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/orders"}, "expect": {"statusCode": 200}}]}
+		]`,
+		"b.suite.json": `[
+			{"name": "environment is down", "calls": [{"on": {"method": "GET", "url": "` + down.URL + `/health"}, "expect": {"statusCode": 200}}]},
+			{"name": "not executed", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/users"}, "expect": {"statusCode": 200}}]}
+		]`,
+		"c.suite.json": `[{"name": "not executed either", "calls": [{"on": {"method": "GET", "url": "` + server.URL + `/users"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BailOnError: true})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outcomes := make(map[string]string)
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			outcomes[result.Case.Name] = result.SkippedMsg
+		case result.HasError():
+			outcomes[result.Case.Name] = "failed"
+		}
+	}
+
+	if outcomes["failed expectation"] != "failed" || outcomes["still executed"] != "failed" || outcomes["environment is down"] != "failed" {
+		t.Errorf("Expected executed cases to fail, got %v", outcomes)
+	}
+
+	for _, name := range []string{"not executed", "not executed either"} {
+		if !strings.HasPrefix(outcomes[name], "aborted: connection refused") {
+			t.Errorf("Expected '%s' to be skipped after connection error, got '%s'", name, outcomes[name])
+		}
+	}
+}
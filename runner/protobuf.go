@@ -0,0 +1,187 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// ProtobufOn describes protobuf request and response of the call, messages are found in descriptor set
+// produced by 'protoc --include_imports --descriptor_set_out=api.pb api.proto'.
+// Request body is written in JSON and serialized into the request message, response message is parsed into JSON
+// (protobuf JSON mapping with field names of .proto file), so it is asserted with 'bodyPath' as usual.
+type ProtobufOn struct {
+	// path to FileDescriptorSet relative to the suite file
+	DescriptorSet string `json:"descriptorSet"`
+	// full name of the request message, e.g. 'shop.v1.GetOrderRequest'
+	Request string `json:"request"`
+	// full name of the response message, e.g. 'shop.v1.Order'
+	Response string `json:"response"`
+	// request and response messages are framed as gRPC-Web, status of trailers is available to 'expect.trailers'
+	GRPCWeb bool `json:"grpcWeb"`
+}
+
+const (
+	protobufContentType = "application/x-protobuf"
+	grpcWebContentType  = "application/grpc-web+proto"
+)
+
+// bodyFormat returns format serializing request message and parsing response message of the call
+func (p *ProtobufOn) bodyFormat(suitePath string) (BodyFormat, error) {
+	descriptors, err := loadProtoDescriptors(suitePath, p.DescriptorSet)
+	if err != nil {
+		return BodyFormat{}, err
+	}
+
+	format := BodyFormat{ContentType: protobufContentType}
+	if p.GRPCWeb {
+		format.ContentType = grpcWebContentType
+	}
+
+	format.Marshal = func(v interface{}) ([]byte, error) {
+		m, err := protoMessage(descriptors, p.Request)
+		if err != nil {
+			return nil, err
+		}
+		return marshalProto(m, v)
+	}
+
+	format.Unmarshal = func(data []byte) (interface{}, error) {
+		if p.Response == "" {
+			return nil, errors.New("Cannot parse body. Response message of protobuf is not set")
+		}
+		m, err := protoMessage(descriptors, p.Response)
+		if err != nil {
+			return nil, err
+		}
+		return unmarshalProto(m, data)
+	}
+
+	return format, nil
+}
+
+// encode serializes JSON request body into the request message, empty body is sent as is unless the request message is set
+func (p *ProtobufOn) encode(suitePath string, body string) (string, BodyFormat, error) {
+	format, err := p.bodyFormat(suitePath)
+	if err != nil {
+		return "", format, err
+	}
+
+	if p.Request == "" && strings.TrimSpace(body) != "" {
+		return "", format, errors.New("Request message of protobuf is not set, see 'protobuf.request'")
+	}
+
+	if p.Request != "" {
+		if strings.TrimSpace(body) == "" {
+			body = "{}"
+		}
+		if body, err = marshalBody("protobuf", format, body); err != nil {
+			return "", format, err
+		}
+	}
+
+	if p.GRPCWeb {
+		body = string(frameGRPCWeb([]byte(body)))
+	}
+
+	return body, format, nil
+}
+
+var protoDescriptorCache sync.Map
+
+// loadProtoDescriptors reads FileDescriptorSet, e.g. produced by 'protoc --include_imports --descriptor_set_out', once per run
+func loadProtoDescriptors(suitePath string, file string) (*protoregistry.Files, error) {
+	path, err := toAbsPath(suitePath, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := protoDescriptorCache.Load(path); ok {
+		return cached.(*protoregistry.Files), nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Can't read protobuf descriptor set: %s", err)
+	}
+
+	descriptors, err := parseProtoDescriptors(data)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid protobuf descriptor set %s: %s", file, err)
+	}
+
+	protoDescriptorCache.Store(path, descriptors)
+	return descriptors, nil
+}
+
+// parseProtoDescriptors resolves files of serialized FileDescriptorSet, all imported files have to be included
+func parseProtoDescriptors(data []byte) (*protoregistry.Files, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	return protodesc.NewFiles(&set)
+}
+
+// protoMessage finds message by full name, e.g. 'shop.v1.Order'
+func protoMessage(descriptors *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	d, err := descriptors.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("Protobuf message '%s' is not found in descriptor set", name)
+	}
+
+	m, ok := d.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("Protobuf type '%s' is not a message", name)
+	}
+
+	return m, nil
+}
+
+// marshalProto serializes JSON value into the message, fields are accepted by .proto or JSON names.
+// Fields are written in the same order every time, so request bodies are reproducible.
+func marshalProto(m protoreflect.MessageDescriptor, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	msg := dynamicpb.NewMessage(m)
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// unmarshalProto parses the message into JSON with field names of .proto file, unset fields have default values
+func unmarshalProto(m protoreflect.MessageDescriptor, data []byte) (interface{}, error) {
+	msg := dynamicpb.NewMessage(m)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, fmt.Errorf("invalid protobuf message %s: %s", m.FullName(), err)
+	}
+
+	encoded, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(encoded, &v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
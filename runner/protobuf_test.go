@@ -0,0 +1,229 @@
+package runner
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func protoField(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, repeated bool, typeName string) *descriptorpb.FieldDescriptorProto {
+	label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+	if repeated {
+		label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+	}
+
+	field := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Label: label.Enum(), Type: typ.Enum()}
+	if typeName != "" {
+		field.TypeName = proto.String(typeName)
+	}
+
+	return field
+}
+
+// testProtoDescriptorSet is a descriptor set of shop.proto:
+//
+//	syntax = "proto3";
+//	package shop.v1;
+//	enum Status { UNKNOWN = 0; PAID = 1; }
+//	message Item { string sku = 1; }
+//	message Order {
+//	  int64 id = 1; Status status = 2; repeated string tags = 3; repeated int32 counts = 4;
+//	  map<string, int32> stock = 5; bytes payload = 6; sint32 delta = 7; Item item = 8; double price = 9;
+//	}
+//	message GetOrderRequest { int64 id = 1; string trace_id = 2; }
+func testProtoDescriptorSet(t *testing.T) []byte {
+	file := &descriptorpb.FileDescriptorProto{
+		Name:    proto.String("shop.proto"),
+		Package: proto.String("shop.v1"),
+		Syntax:  proto.String("proto3"),
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("PAID"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name:  proto.String("Item"),
+				Field: []*descriptorpb.FieldDescriptorProto{protoField("sku", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, false, "")},
+			},
+			{
+				Name: proto.String("Order"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					protoField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, false, ""),
+					protoField("status", 2, descriptorpb.FieldDescriptorProto_TYPE_ENUM, false, ".shop.v1.Status"),
+					protoField("tags", 3, descriptorpb.FieldDescriptorProto_TYPE_STRING, true, ""),
+					protoField("counts", 4, descriptorpb.FieldDescriptorProto_TYPE_INT32, true, ""),
+					protoField("stock", 5, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, true, ".shop.v1.Order.StockEntry"),
+					protoField("payload", 6, descriptorpb.FieldDescriptorProto_TYPE_BYTES, false, ""),
+					protoField("delta", 7, descriptorpb.FieldDescriptorProto_TYPE_SINT32, false, ""),
+					protoField("item", 8, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, false, ".shop.v1.Item"),
+					protoField("price", 9, descriptorpb.FieldDescriptorProto_TYPE_DOUBLE, false, ""),
+				},
+				NestedType: []*descriptorpb.DescriptorProto{{
+					Name: proto.String("StockEntry"),
+					Field: []*descriptorpb.FieldDescriptorProto{
+						protoField("key", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, false, ""),
+						protoField("value", 2, descriptorpb.FieldDescriptorProto_TYPE_INT32, false, ""),
+					},
+					Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+				}},
+			},
+			{
+				Name: proto.String("GetOrderRequest"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					protoField("id", 1, descriptorpb.FieldDescriptorProto_TYPE_INT64, false, ""),
+					protoField("trace_id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING, false, ""),
+				},
+			},
+		},
+	}
+
+	data, err := proto.Marshal(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return data
+}
+
+func TestProtobufGRPCWebCall(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	dir, err := ioutil.TempDir("", "bozr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	options.Path = dir
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "shop.pb"), testProtoDescriptorSet(t), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var message []byte
+	message = protowire.AppendTag(message, 1, protowire.VarintType)
+	message = protowire.AppendVarint(message, 42)
+	message = protowire.AppendTag(message, 2, protowire.BytesType)
+	message = protowire.AppendString(message, "t-1")
+	expectedRequest := frameGRPCWeb(message)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		if !bytes.Equal(body, expectedRequest) || req.Header.Get("Content-Type") != grpcWebContentType || req.Header.Get("X-Grpc-Web") != "1" {
+			t.Errorf("Unexpected request %v %v", req.Header, body)
+		}
+
+		var item, order []byte
+		item = protowire.AppendTag(item, 1, protowire.BytesType)
+		item = protowire.AppendString(item, "A-1")
+		order = protowire.AppendTag(order, 1, protowire.VarintType)
+		order = protowire.AppendVarint(order, 42)
+		order = protowire.AppendTag(order, 2, protowire.VarintType)
+		order = protowire.AppendVarint(order, 1)
+		order = protowire.AppendTag(order, 3, protowire.BytesType)
+		order = protowire.AppendString(order, "new")
+		order = protowire.AppendTag(order, 8, protowire.BytesType)
+		order = protowire.AppendBytes(order, item)
+		trailer := []byte("grpc-status: 0\r\ngrpc-message: OK\r\n")
+
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		w.Write(frameGRPCWeb(order))
+		w.Write(append([]byte{grpcWebTrailerFlag, 0, 0, 0, byte(len(trailer))}, trailer...))
+	}))
+	defer server.Close()
+
+	c := Call{
+		On: On{
+			Method: "POST",
+			URL:    server.URL + "/shop.v1.Orders/GetOrder",
+			Body:   []byte(`{"id": "42", "traceId": "t-1"}`),
+			Protobuf: &ProtobufOn{
+				DescriptorSet: "shop.pb",
+				Request:       "shop.v1.GetOrderRequest",
+				Response:      "shop.v1.Order",
+				GRPCWeb:       true,
+			},
+		},
+		Expect: Expect{
+			Trailers: map[string]string{"grpc-status": "0"},
+			BPath:    map[string]interface{}{"id": "42", "status": "PAID", "tags.0": "new", "item.sku": "A-1", "delta": 0.0, "price": 0.0},
+		},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if _, ok := trace.ExpDesc["Trailer 'grpc-status' matches expected value '0'"]; !ok {
+		t.Errorf("Expected trailer to be verified, got %v", trace.ExpDesc)
+	}
+}
+
+func TestProtobufRoundTrip(t *testing.T) {
+	descriptors, err := parseProtoDescriptors(testProtoDescriptorSet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := protoMessage(descriptors, "shop.v1.Order")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	format := BodyFormat{Marshal: func(v interface{}) ([]byte, error) { return marshalProto(order, v) }}
+	data, err := marshalBody("protobuf", format, `{"id": -7, "status": 1, "tags": ["a", "b"], "counts": [1, 300],
+		"stock": {"x": 2}, "payload": "AQI=", "delta": -3, "item": {"sku": "A-1"}, "price": 9.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// packed counts: 0x22 len 3, 1, 300 as varint
+	if !bytes.Contains([]byte(data), []byte{0x22, 3, 1, 0xac, 2}) {
+		t.Errorf("Expected packed repeated field, got %v", []byte(data))
+	}
+
+	actual, err := unmarshalProto(order, []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"id":      "-7",
+		"status":  "PAID",
+		"tags":    []interface{}{"a", "b"},
+		"counts":  []interface{}{1.0, 300.0},
+		"stock":   map[string]interface{}{"x": 2.0},
+		"payload": "AQI=",
+		"delta":   -3.0,
+		"item":    map[string]interface{}{"sku": "A-1"},
+		"price":   9.5,
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	if _, err := marshalProto(order, map[string]interface{}{"missing": 1.0}); err == nil {
+		t.Error("Expected error of unknown field")
+	}
+
+	if _, err := protoMessage(descriptors, "shop.v1.Status"); err == nil || err.Error() != "Protobuf type 'shop.v1.Status' is not a message" {
+		t.Error("Expected error of enum used as message, got:", err)
+	}
+}
@@ -0,0 +1,124 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// RateLimitProbe repeats the request in a row to verify the server starts limiting it with '429 Too Many Requests'.
+// Expectations of the call are checked against JSON object with 'statuses' of all requests and number of requests
+// passed before the limit ('limitedAfter'), status and headers are of the last response.
+type RateLimitProbe struct {
+	// number of requests sent in a row including the first one
+	Requests int `json:"requests"`
+	// number of requests expected to pass before the limit, any number if 0
+	LimitAfter int `json:"limitAfter"`
+	// limited responses have 'Retry-After' header
+	RetryAfter bool `json:"retryAfter"`
+}
+
+// rateLimitFailure is unmet expectation of the rate limit as opposed to errors of sending requests
+type rateLimitFailure struct {
+	error
+}
+
+// probe sends the rest of requests after the first one and asserts the transition to limited responses
+func (p *RateLimitProbe) probe(client *http.Client, req *http.Request, body string, first Response) (Response, int, error) {
+	statuses := []int{first.http.StatusCode}
+	last := first
+	limited := []Response{}
+	if first.http.StatusCode == http.StatusTooManyRequests {
+		limited = append(limited, first)
+	}
+
+	for len(statuses) < p.Requests {
+		nextReq, err := http.NewRequest(req.Method, req.URL.String(), bytes.NewBufferString(body))
+		if err != nil {
+			return last, 0, err
+		}
+		nextReq = nextReq.WithContext(req.Context())
+		nextReq.Header = req.Header.Clone()
+
+		if err = applyMiddlewares(nextReq, options.Middlewares); err != nil {
+			return last, 0, err
+		}
+
+		resp, err := client.Do(nextReq)
+		if err != nil {
+			return last, 0, classifyTransportError(err)
+		}
+
+		data, truncated, err := readBody(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return last, 0, err
+		}
+		if truncated {
+			return last, 0, responseTooLarge()
+		}
+
+		if last, err = newResponse(resp, data); err != nil {
+			return last, 0, err
+		}
+		statuses = append(statuses, resp.StatusCode)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			limited = append(limited, last)
+		}
+	}
+
+	limitedAfter, err := p.check(statuses, limited)
+	if err != nil {
+		return last, 0, err
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"statuses": statuses, "limitedAfter": limitedAfter})
+	if err != nil {
+		return last, 0, err
+	}
+
+	aggregated := *last.http
+	aggregated.Header = last.http.Header.Clone()
+	aggregated.Header.Set("Content-Type", "application/json")
+	aggregated.Header.Del("Content-Length")
+
+	result, err := newResponse(&aggregated, data)
+	return result, limitedAfter, err
+}
+
+// check returns number of requests passed before the limit. Once limited, all following requests have to be limited too.
+func (p *RateLimitProbe) check(statuses []int, limited []Response) (int, error) {
+	limitedAfter := -1
+	for i, status := range statuses {
+		if status == http.StatusTooManyRequests && limitedAfter < 0 {
+			limitedAfter = i
+		}
+
+		if status != http.StatusTooManyRequests && limitedAfter >= 0 {
+			return 0, rateLimitFailure{fmt.Errorf("Request #%d is not limited (status %d) after limit at request #%d, statuses: %v", i+1, status, limitedAfter+1, statuses)}
+		}
+	}
+
+	if limitedAfter < 0 {
+		return 0, rateLimitFailure{fmt.Errorf("None of %d requests is limited with status 429, statuses: %v", len(statuses), statuses)}
+	}
+
+	if p.LimitAfter > 0 && limitedAfter != p.LimitAfter {
+		return 0, rateLimitFailure{fmt.Errorf("Expected limit after %d requests, detected after %d, statuses: %v", p.LimitAfter, limitedAfter, statuses)}
+	}
+
+	if p.RetryAfter {
+		for i, resp := range limited {
+			if resp.http.Header.Get("Retry-After") == "" {
+				return 0, rateLimitFailure{fmt.Errorf("Limited response #%d has no 'Retry-After' header", limitedAfter+i+1)}
+			}
+		}
+	}
+
+	return limitedAfter, nil
+}
+
+func (p *RateLimitProbe) desc(limitedAfter int) string {
+	return fmt.Sprintf("Rate limited (429) after %d of %d requests", limitedAfter, p.Requests)
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// rateLimitedServer returns 429 with 'Retry-After' header once allowed number of requests is exceeded
+func rateLimitedServer(allowed int) *httptest.Server {
+	var mu sync.Mutex
+	received := 0
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		received++
+		limited := received > allowed
+		mu.Unlock()
+
+		if limited {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+}
+
+func TestCallRateLimitDetected(t *testing.T) {
+	// given
+	server := rateLimitedServer(3)
+	defer server.Close()
+
+	c := Call{
+		On:        On{Method: "POST", URL: server.URL + "/login", Body: []byte(`{"user": "admin"}`)},
+		RateLimit: &RateLimitProbe{Requests: 6, LimitAfter: 3, RetryAfter: true},
+		Expect:    Expect{StatusCode: 429, BPath: map[string]interface{}{"limitedAfter": 3.0, "statuses.size()": 6.0}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if _, ok := trace.ExpDesc["Rate limited (429) after 3 of 6 requests"]; !ok {
+		t.Errorf("Expected detected threshold to be reported, got %v", trace.ExpDesc)
+	}
+}
+
+func TestCallRateLimitFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed int
+		probe   RateLimitProbe
+		message string
+	}{
+		{name: "different threshold", allowed: 2, probe: RateLimitProbe{Requests: 5, LimitAfter: 3}, message: "Expected limit after 3 requests, detected after 2, statuses: [200 200 429 429 429]"},
+		{name: "not limited", allowed: 10, probe: RateLimitProbe{Requests: 3}, message: "None of 3 requests is limited with status 429, statuses: [200 200 200]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := rateLimitedServer(tt.allowed)
+			defer server.Close()
+
+			probe := tt.probe
+			c := Call{On: On{Method: "GET", URL: server.URL}, RateLimit: &probe, Expect: Expect{StatusCode: 429}}
+
+			trace := call("", c, NewVars(""))
+
+			if trace.Terminated() || trace.ErrorCause == nil || trace.ErrorCause.Error() != tt.message {
+				t.Errorf("Expected failure '%s', got %v", tt.message, trace.ErrorCause)
+			}
+		})
+	}
+}
+
+func TestCallRateLimitResponseTooLarge(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+	options.MaxResponseSize = 64
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, RateLimit: &RateLimitProbe{Requests: 3}, Expect: Expect{StatusCode: 429}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.ErrorCause == nil || trace.ErrorCause.Error() != responseTooLarge().Error() {
+		t.Errorf("Expected max size error of repeated request, got %v", trace.ErrorCause)
+	}
+}
+
+func TestRateLimitProbeCheck(t *testing.T) {
+	p := &RateLimitProbe{Requests: 4, RetryAfter: true}
+	limited := Response{http: &http.Response{StatusCode: 429, Header: http.Header{}}}
+
+	_, err := p.check([]int{200, 429, 200, 429}, []Response{limited, limited})
+	if err == nil || !strings.HasPrefix(err.Error(), "Request #3 is not limited (status 200) after limit at request #2") {
+		t.Errorf("Expected unlimited request after the limit to fail, got %v", err)
+	}
+
+	_, err = p.check([]int{200, 429}, []Response{limited})
+	if err == nil || err.Error() != "Limited response #2 has no 'Retry-After' header" {
+		t.Errorf("Expected missing 'Retry-After' to fail, got %v", err)
+	}
+}
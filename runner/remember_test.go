@@ -0,0 +1,179 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPopulateRequestBody(t *testing.T) {
+	//given
+	on := On{URL: "http://example.com"}
+	value := "abc"
+	vars := NewVars("")
+	vars.AddAll(map[string]interface{}{"var": value})
+
+	tmplCtx := NewTemplateContext(vars)
+	body := tmplCtx.ApplyTo("pre {var} post")
+
+	// when
+	req, _ := populateRequest(on, body, tmplCtx)
+
+	//then
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(req.Body)
+	got := buf.String()
+	if !strings.Contains(got, value) {
+		t.Error(
+			"body does not conatain value:", value,
+			"got:", got,
+		)
+	}
+}
+
+func TestConvertTypesToString(t *testing.T) {
+	makeTest := func(val interface{}, expected string) func(t *testing.T) {
+		return func(t *testing.T) {
+			got := toString(val)
+
+			if got != expected {
+				t.Error(
+					"expected[", expected, "]",
+					"got[", got, "]",
+				)
+			}
+		}
+	}
+
+	t.Run("int", makeTest(1, "1"))
+	t.Run("float", makeTest(1.00001, "1.00001"))
+	t.Run("boolean", makeTest(false, "false"))
+	t.Run("string", makeTest("example", "example"))
+	t.Run("object", makeTest(map[string]interface{}{"id": 1.0}, `{"id":1}`))
+	t.Run("array", makeTest([]interface{}{"a", 2.0}, `["a",2]`))
+}
+
+func TestRememberHeader(t *testing.T) {
+	responseHeaders := map[string][]string{"X-Test": {"PASS"}}
+	remember := map[string]string{"valueKey": "X-Test"}
+
+	vars := NewVars("")
+	initialLen := len(vars.items)
+
+	rememberHeaders(responseHeaders, remember, vars)
+
+	if len(vars.items)-initialLen != 1 {
+		t.Errorf("Unexpected map length: %d", len(remember))
+		return
+	}
+
+	if vars.items["valueKey"] != "PASS" {
+		t.Errorf("Unexpected remembered value: %s", vars.items["valueKey"])
+	}
+}
+
+func TestRememberedBodySentAsBody(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+
+	received := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": {"id": 7, "items": ["a", "b"]}, "meta": {"page": 1}}`))
+			return
+		}
+
+		body, _ := ioutil.ReadAll(req.Body)
+		received[req.URL.Path] = string(body)
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"orders.suite.json": `[{"name": "copy order", "calls": [
+			{"on": {"method": "GET", "url": "` + server.URL + `/orders/7"}, "expect": {"statusCode": 200}, "remember": {"bodyPath": {"order": "data", "response": ""}}},
+			{"on": {"method": "POST", "url": "` + server.URL + `/orders", "body": "{order}"}, "expect": {"statusCode": 200}},
+			{"on": {"method": "POST", "url": "` + server.URL + `/archive", "body": {"archived": "{order}", "source": "{response}"}}, "expect": {"statusCode": 200}}
+		]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].HasError() {
+		t.Fatal(results[0].Error())
+	}
+
+	if received["/orders"] != `{"id":7,"items":["a","b"]}` {
+		t.Errorf("Expected 'data' object as body, got %s", received["/orders"])
+	}
+
+	var archive map[string]interface{}
+	if err := json.Unmarshal([]byte(received["/archive"]), &archive); err != nil {
+		t.Fatalf("Expected JSON body, got %s: %s", received["/archive"], err)
+	}
+
+	expected := map[string]interface{}{
+		"archived": map[string]interface{}{"id": 7.0, "items": []interface{}{"a", "b"}},
+		"source":   map[string]interface{}{"data": map[string]interface{}{"id": 7.0, "items": []interface{}{"a", "b"}}, "meta": map[string]interface{}{"page": 1.0}},
+	}
+	if !reflect.DeepEqual(archive, expected) {
+		t.Errorf("Expected embedded objects without double encoding, got %s", received["/archive"])
+	}
+}
+
+func TestRememberTiming(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	vars := NewVars("")
+	slow := Call{
+		On:       On{Method: "GET", URL: server.URL + "/slow"},
+		Remember: Remember{Timing: map[string]string{"slowMs": "duration", "slowTtfbMs": "ttfb"}},
+	}
+
+	// when
+	trace := call("", slow, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if trace.FirstByte < 30*time.Millisecond || trace.FirstByte > trace.ExecFrame.Duration() {
+		t.Errorf("Unexpected time to first byte %s of call taking %s", trace.FirstByte, trace.ExecFrame.Duration())
+	}
+
+	fast := Call{
+		On:     On{Method: "GET", URL: server.URL + "/fast"},
+		Expect: Expect{CEL: CELExpressions{"{slowMs} >= 30 && {slowTtfbMs} >= 30 && {slowTtfbMs} <= {slowMs} && durationMs < {slowMs}"}},
+	}
+
+	trace = call("", fast, vars)
+	if trace.HasError() {
+		t.Error(trace.ErrorCause)
+	}
+
+	if unused := vars.Unused(); len(unused) > 0 {
+		t.Errorf("Expected remembered timings to be used, got unused %v", unused)
+	}
+}
@@ -0,0 +1,237 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// CaseRuns counts outcomes of test case over repeated runs
+type CaseRuns struct {
+	Suite  string
+	Case   string
+	Passed int
+	Failed int
+}
+
+// Flaky is true if test case passed in some runs and failed in others
+func (c CaseRuns) Flaky() bool {
+	return c.Passed > 0 && c.Failed > 0
+}
+
+// SummarizeRuns aggregates results of repeated runs by test case in order of first appearance. Skipped test cases are not counted.
+func SummarizeRuns(runs [][]TestResult) []CaseRuns {
+	index := make(map[string]int)
+	var summary []CaseRuns
+
+	for _, results := range runs {
+		for _, result := range results {
+			if result.Skipped {
+				continue
+			}
+
+			key := result.Suite.FullName() + "\x00" + result.Case.Name
+			i, ok := index[key]
+			if !ok {
+				i = len(summary)
+				index[key] = i
+				summary = append(summary, CaseRuns{Suite: result.Suite.FullName(), Case: result.Case.Name})
+			}
+
+			if result.HasError() {
+				summary[i].Failed++
+			} else {
+				summary[i].Passed++
+			}
+		}
+	}
+
+	return summary
+}
+
+// WriteRunsSummary writes pass rate of every run, aggregate pass rate and flaky test cases
+func WriteRunsSummary(w io.Writer, runs [][]TestResult) {
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Repeated Run Summary")
+	fmt.Fprintln(w, "-------------------------------")
+
+	tw := tabwriter.NewWriter(w, 4, 2, 1, ' ', tabwriter.AlignRight)
+
+	totalPassed, total := 0, 0
+	for i, results := range runs {
+		passed, count := passRate(results)
+		totalPassed, total = totalPassed+passed, total+count
+
+		fmt.Fprintf(tw, "Run %d:\t %d/%d passed (%s)\n", i+1, passed, count, percent(passed, count))
+	}
+	fmt.Fprintf(tw, "Overall:\t %d/%d passed (%s)\n", totalPassed, total, percent(totalPassed, total))
+
+	var flaky []CaseRuns
+	for _, c := range SummarizeRuns(runs) {
+		if c.Flaky() {
+			flaky = append(flaky, c)
+		}
+	}
+	fmt.Fprintf(tw, "Flaky:\t %d\n", len(flaky))
+	tw.Flush()
+
+	for _, c := range flaky {
+		fmt.Fprintf(w, "    %s / %s: passed %d of %d runs\n", c.Suite, c.Case, c.Passed, c.Passed+c.Failed)
+	}
+
+	fmt.Fprintln(w)
+}
+
+func passRate(results []TestResult) (int, int) {
+	passed, count := 0, 0
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+
+		count++
+		if !result.HasError() {
+			passed++
+		}
+	}
+
+	return passed, count
+}
+
+func percent(part, total int) string {
+	if total == 0 {
+		return "n/a"
+	}
+
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
+
+// metrics of latency variation across repeated runs
+const (
+	// coefficient of variation, standard deviation divided by mean
+	variationCV = "cv"
+	// max duration divided by min one
+	variationRatio = "ratio"
+)
+
+// LatencyStability limits variation of request durations across repeated runs, e.g. 'cv=0.3' or 'ratio=2'
+type LatencyStability struct {
+	Metric string
+	Limit  float64
+}
+
+// ParseLatencyStability parses limit in format '<cv|ratio>=<value>'
+func ParseLatencyStability(value string) (LatencyStability, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || (parts[0] != variationCV && parts[0] != variationRatio) {
+		return LatencyStability{}, fmt.Errorf("Invalid latency stability '%s'. Expected format: cv=0.3 or ratio=2", value)
+	}
+
+	limit, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || limit <= 0 {
+		return LatencyStability{}, fmt.Errorf("Invalid limit in latency stability '%s'", value)
+	}
+
+	return LatencyStability{Metric: parts[0], Limit: limit}, nil
+}
+
+func (s LatencyStability) String() string {
+	return s.Metric + "=" + strconv.FormatFloat(s.Limit, 'f', -1, 64)
+}
+
+// variation of durations according to the metric
+func (s LatencyStability) variation(durations []time.Duration) float64 {
+	min, max, sum := durations[0], durations[0], 0.0
+	for _, d := range durations {
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+		sum += float64(d)
+	}
+
+	if s.Metric == variationRatio {
+		if min <= 0 {
+			return math.Inf(1)
+		}
+		return float64(max) / float64(min)
+	}
+
+	mean := sum / float64(len(durations))
+	if mean == 0 {
+		return 0
+	}
+
+	squares := 0.0
+	for _, d := range durations {
+		squares += (float64(d) - mean) * (float64(d) - mean)
+	}
+
+	return math.Sqrt(squares/float64(len(durations))) / mean
+}
+
+// Check verifies variation of every request durations across runs does not exceed the limit.
+// Requests executed in less than two runs are not checked.
+func (s LatencyStability) Check(runs [][]TestResult) error {
+	index := make(map[string]int)
+	var names []string
+	var durations [][]time.Duration
+
+	for _, results := range runs {
+		for _, result := range results {
+			if result.Skipped {
+				continue
+			}
+
+			for _, trace := range result.Traces {
+				if trace.Terminated() {
+					continue
+				}
+
+				name := fmt.Sprintf("%s / %s, call #%d", result.Suite.FullName(), result.Case.Name, trace.Num+1)
+				i, ok := index[name]
+				if !ok {
+					i = len(names)
+					index[name] = i
+					names = append(names, name)
+					durations = append(durations, nil)
+				}
+
+				durations[i] = append(durations[i], trace.ExecFrame.Duration())
+			}
+		}
+	}
+
+	var unstable []string
+	for i, name := range names {
+		if len(durations[i]) < 2 {
+			continue
+		}
+
+		if actual := s.variation(durations[i]); actual > s.Limit {
+			unstable = append(unstable, fmt.Sprintf("    %s: %s %.2f, durations: %s", name, s.Metric, actual, formatDurations(durations[i])))
+		}
+	}
+
+	if len(unstable) > 0 {
+		return errors.New("Unstable latency across runs, expected " + s.String() + ":\n" + strings.Join(unstable, "\n"))
+	}
+
+	return nil
+}
+
+func formatDurations(durations []time.Duration) string {
+	values := make([]string, 0, len(durations))
+	for _, d := range durations {
+		values = append(values, d.Round(time.Millisecond).String())
+	}
+
+	return strings.Join(values, ", ")
+}
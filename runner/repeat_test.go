@@ -0,0 +1,149 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRepeatedRunsFlaky(t *testing.T) {
+	// given
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path == "/flaky" {
+			mu.Lock()
+			calls++
+			if calls%2 == 0 {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			mu.Unlock()
+		}
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir, _ := ioutil.TempDir("", "bozr-repeat")
+	defer os.RemoveAll(dir)
+
+	suite := `[
+		{"name": "stable", "calls": [{"on": {"method": "GET", "url": "/stable"}, "expect": {"statusCode": 200}}]},
+		{"name": "flaky", "calls": [{"on": {"method": "GET", "url": "/flaky"}, "expect": {"statusCode": 200}}]}
+	]`
+	ioutil.WriteFile(filepath.Join(dir, "repeat"+SuiteExt), []byte(suite), 0644)
+
+	// when
+	var runs [][]TestResult
+	for i := 0; i < 3; i++ {
+		results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, results)
+	}
+
+	// then
+	summary := SummarizeRuns(runs)
+	if len(summary) != 2 {
+		t.Fatalf("Expected 2 test cases, got %v", summary)
+	}
+
+	if summary[0].Flaky() || summary[0].Passed != 3 {
+		t.Errorf("Expected stable case to pass in all runs, got %+v", summary[0])
+	}
+
+	if !summary[1].Flaky() || summary[1].Passed != 2 || summary[1].Failed != 1 {
+		t.Errorf("Expected flaky case to be detected, got %+v", summary[1])
+	}
+
+	var out bytes.Buffer
+	WriteRunsSummary(&out, runs)
+
+	for _, expected := range []string{"Run 2: 1/2 passed (50.0%)", "Overall: 5/6 passed (83.3%)", "Flaky: 1", "repeat / flaky: passed 2 of 3 runs"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected summary to contain '%s', got:\n%s", expected, out.String())
+		}
+	}
+}
+
+// latencyRuns builds results of repeated runs with single request of the given durations
+func latencyRuns(durations ...time.Duration) [][]TestResult {
+	start := time.Now()
+	suite := TestSuite{Name: "users"}
+
+	var runs [][]TestResult
+	for _, d := range durations {
+		trace := &CallTrace{ExecFrame: TimeFrame{Start: start, End: start.Add(d)}}
+		runs = append(runs, []TestResult{{Suite: suite, Case: TestCase{Name: "get"}, Traces: []*CallTrace{trace}}})
+	}
+
+	return runs
+}
+
+func TestLatencyStabilityStable(t *testing.T) {
+	runs := latencyRuns(100*time.Millisecond, 110*time.Millisecond, 95*time.Millisecond, 105*time.Millisecond)
+
+	for _, value := range []string{"cv=0.1", "ratio=1.5"} {
+		stability, err := ParseLatencyStability(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := stability.Check(runs); err != nil {
+			t.Errorf("Expected stable latency for %s, got %s", value, err)
+		}
+	}
+}
+
+func TestLatencyStabilityJittery(t *testing.T) {
+	runs := latencyRuns(100*time.Millisecond, 900*time.Millisecond, 90*time.Millisecond, 400*time.Millisecond)
+
+	for _, value := range []string{"cv=0.3", "ratio=2"} {
+		stability, err := ParseLatencyStability(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = stability.Check(runs)
+		if err == nil || !strings.Contains(err.Error(), "users / get, call #1") {
+			t.Errorf("Expected unstable latency for %s, got %v", value, err)
+		}
+	}
+}
+
+func TestLatencyStabilityCallNumbers(t *testing.T) {
+	// given
+	start := time.Now()
+	var runs [][]TestResult
+	for _, d := range []time.Duration{100 * time.Millisecond, 900 * time.Millisecond} {
+		stable := &CallTrace{Num: 0, ExecFrame: TimeFrame{Start: start, End: start.Add(100 * time.Millisecond)}}
+		jittery := &CallTrace{Num: 1, ExecFrame: TimeFrame{Start: start, End: start.Add(d)}}
+		runs = append(runs, []TestResult{{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "get"}, Traces: []*CallTrace{stable, jittery}}})
+	}
+
+	stability, _ := ParseLatencyStability("ratio=2")
+
+	// when
+	err := stability.Check(runs)
+
+	// then
+	if err == nil || !strings.Contains(err.Error(), "users / get, call #2") || strings.Contains(err.Error(), "call #1") {
+		t.Errorf("Expected the second call to be reported with 1-based number, got %v", err)
+	}
+}
+
+func TestParseLatencyStabilityInvalid(t *testing.T) {
+	for _, value := range []string{"cv", "p99=0.3", "ratio=abc", "cv=-1"} {
+		if _, err := ParseLatencyStability(value); err == nil {
+			t.Errorf("Expected error for '%s'", value)
+		}
+	}
+}
@@ -0,0 +1,1063 @@
+package runner
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Reporter is used to write down test results using particular formats and outputs
+type Reporter interface {
+	Init()
+
+	Report(result []TestResult)
+
+	Flush()
+}
+
+// ConsoleReporter is a simple reporter that outputs everything to the StdOut.
+type ConsoleReporter struct {
+	ExitCode   int
+	LogHTTP    bool
+	Writer     io.Writer
+	IndentSize int
+	// print results grouped by package into a tree in the summary
+	TreeSummary bool
+	// prefix request lines with offset of request start from test case start
+	ShowOffsets bool
+	// one line per test case, details are printed only for failed ones
+	Compact bool
+	// ColorAuto, ColorAlways or ColorNever. If not set, color.NoColor decides
+	Color string
+	// time zone of start and end times in the summary, UTC if not set
+	Location *time.Location
+	// print start and end times in RFC 3339 instead of Go default format
+	RFC3339 bool
+
+	// file opened for the output by NewReporter, closed on Flush
+	output io.Closer
+
+	execFrame *TimeFrame
+
+	// to prevent collisions while working with StdOut
+	ioMutex *sync.Mutex
+
+	total    int
+	failed   int
+	skipped  int
+	warnings int
+
+	timings Timings
+
+	// suite outcomes by package name
+	suites map[string][]suiteCounts
+}
+
+func (r *ConsoleReporter) Init() {
+	r.execFrame = &TimeFrame{Start: time.Now()}
+}
+
+const (
+	defaultIndentSize = 4
+	caretIcon         = "\u2514" // ↳
+)
+
+type status struct {
+	Icon  string
+	Label string
+	Color color.Attribute
+}
+
+const (
+	outputLabel int = iota
+	outputIcon
+)
+
+var (
+	statusPassed  = status{Icon: "\u221A", Label: "PASSED", Color: color.FgGreen}   // ✔
+	statusFailed  = status{Icon: "\u00D7", Label: "FAILED", Color: color.FgRed}     // ✘
+	statusSkipped = status{Icon: "\u2212", Label: "SKIPPED", Color: color.FgYellow} // −
+	statusWarning = status{Icon: "!", Label: "WARNING", Color: color.FgYellow}
+)
+
+func (r *ConsoleReporter) verbose() bool {
+	return r.LogHTTP
+}
+
+func (r *ConsoleReporter) StartLine() {
+	r.Writer.Write([]byte("\n"))
+	r.Writer.Write([]byte(strings.Repeat(" ", r.IndentSize)))
+}
+
+func (r *ConsoleReporter) Indent() {
+	r.IndentSize = r.IndentSize + defaultIndentSize
+}
+
+func (r *ConsoleReporter) Unindent() {
+	r.IndentSize = r.IndentSize - defaultIndentSize
+}
+
+func (r *ConsoleReporter) Report(results []TestResult) {
+	r.ioMutex.Lock()
+
+	if len(results) == 0 {
+		r.ioMutex.Unlock()
+		return
+	}
+
+	r.timings.add(results)
+
+	// suite
+	suite := results[0].Suite
+
+	if r.suites == nil {
+		r.suites = make(map[string][]suiteCounts)
+	}
+	pkg := suite.PackageName()
+	r.suites[pkg] = append(r.suites[pkg], suiteCounts{Name: suite.Name, resultCounts: countResults(results)})
+
+	if r.Compact {
+		r.reportCompact(results)
+		r.ioMutex.Unlock()
+		return
+	}
+
+	r.StartLine()
+	r.Write(suite.FullName())
+
+	for _, result := range results {
+
+		r.total = r.total + 1
+
+		r.Indent()
+
+		r.StartLine()
+		r.Write(caretIcon).Write(" ")
+
+		if result.Skipped {
+			r.WriteStatus(statusSkipped, outputLabel).Write(" ").Write(result.Case.Name)
+			r.Write(" (").WriteSkipReason(result.SkippedMsg).Write(")")
+
+			r.skipped = r.skipped + 1
+			r.Unindent()
+
+			continue
+		}
+
+		if result.HasError() {
+			r.WriteStatus(statusFailed, outputLabel)
+			r.failed = r.failed + 1
+		} else {
+			r.WriteStatus(statusPassed, outputLabel)
+		}
+
+		r.Write(" ").Write(result.Case.Name)
+		r.Write(" [").Write(result.ExecFrame.Duration().Round(time.Millisecond)).Write("]")
+
+		warnings := result.warnings()
+		r.warnings = r.warnings + warnings
+
+		if result.HasError() || warnings > 0 || result.skippedExps() > 0 || r.LogHTTP {
+			for _, trace := range result.Traces {
+				r.Indent()
+
+				if trace.Terminated() {
+					r.Indent()
+					r.StartLine()
+
+					r.Write(trace.ErrorCause.Error())
+					r.Unindent()
+					r.Unindent()
+
+					continue
+				}
+
+				r.StartLine()
+				if r.ShowOffsets {
+					r.Write(formatOffset(trace.ExecFrame.Start.Sub(result.ExecFrame.Start))).Write(" ")
+				}
+				r.Write(trace.RequestMethod).Write(" ").Write(trace.RequestURL).Write(" [").Write(trace.ExecFrame.Duration().Round(time.Millisecond)).Write("]")
+
+				for exp, failed := range trace.ExpDesc {
+					r.Indent()
+					r.StartLine()
+
+					if failed {
+						r.WriteStatus(statusFailed, outputIcon)
+					} else {
+						r.WriteStatus(statusPassed, outputIcon)
+					}
+
+					r.Write(" ").WriteMultiline(exp, r.Write)
+
+					r.Unindent()
+				}
+
+				for exp, reason := range trace.ExpSkipped {
+					r.Indent()
+					r.StartLine()
+
+					r.WriteStatus(statusSkipped, outputIcon)
+					r.Write(" ").Write(exp).Write(" (skipped expectation: ").WriteSkipReason(reason).Write(")")
+
+					r.Unindent()
+				}
+
+				for exp, msg := range trace.ExpWarnings {
+					r.Indent()
+					r.StartLine()
+
+					r.WriteStatus(statusWarning, outputIcon)
+					r.Write(" ").Write(exp)
+
+					r.Indent()
+					r.StartLine()
+					r.WriteMultiline(msg, r.WriteWarning)
+					r.Unindent()
+
+					r.Unindent()
+				}
+
+				if r.LogHTTP {
+					r.Indent()
+
+					r.StartLine()
+					r.StartLine()
+					{
+						dump := trace.RequestDump
+						if len(dump) > 0 {
+							r.WriteMultiline(dump, r.WriteDimmed)
+							r.StartLine()
+						}
+
+						dump = trace.ResponseDump
+						if len(dump) > 0 {
+							r.WriteMultiline(trace.ResponseDump, r.WriteDimmed)
+							r.StartLine()
+						}
+					}
+					r.Unindent()
+				}
+
+				r.Unindent()
+			}
+		}
+
+		r.Unindent()
+	}
+
+	r.StartLine()
+
+	r.ioMutex.Unlock()
+}
+
+// compact labels of test case status
+var compactLabels = map[string]string{
+	statusPassed.Label:  "PASS",
+	statusFailed.Label:  "FAIL",
+	statusSkipped.Label: "SKIP",
+}
+
+// reportCompact writes one line per test case, e.g. 'PASS users/create user 120ms'.
+// Line of failed test case ends with the first failure, which is followed by the failed request details.
+func (r *ConsoleReporter) reportCompact(results []TestResult) {
+	for _, result := range results {
+		r.total = r.total + 1
+
+		name := result.Suite.FullName() + "/" + result.Case.Name
+
+		if result.Skipped {
+			r.skipped = r.skipped + 1
+			r.writeCompactStatus(statusSkipped)
+			fmt.Fprintf(r.Writer, " %s (%s)\n", name, result.SkippedMsg)
+			continue
+		}
+
+		r.warnings = r.warnings + result.warnings()
+		duration := result.ExecFrame.Duration().Round(time.Millisecond)
+
+		failed := result.failedTrace()
+		if failed == nil {
+			r.writeCompactStatus(statusPassed)
+			fmt.Fprintf(r.Writer, " %s %s\n", name, duration)
+			continue
+		}
+
+		r.failed = r.failed + 1
+		message := failed.ErrorCause.Error()
+
+		r.writeCompactStatus(statusFailed)
+		fmt.Fprintf(r.Writer, " %s %s %s", name, duration, strings.SplitN(message, "\n", 2)[0])
+
+		r.IndentSize = defaultIndentSize
+		if failed.RequestMethod != "" {
+			r.StartLine()
+			r.Write(failed.RequestMethod).Write(" ").Write(failed.RequestURL).Write(" [").Write(failed.ExecFrame.Duration().Round(time.Millisecond)).Write("]")
+		}
+		r.StartLine()
+		r.WriteMultiline(message, r.Write)
+
+		if r.LogHTTP {
+			for _, dump := range []string{failed.RequestDump, failed.ResponseDump} {
+				if len(dump) > 0 {
+					r.StartLine()
+					r.WriteMultiline(dump, r.WriteDimmed)
+				}
+			}
+		}
+		r.IndentSize = 0
+
+		fmt.Fprintln(r.Writer)
+	}
+}
+
+func (r ConsoleReporter) writeCompactStatus(status status) {
+	r.paint(status.Color, color.Bold).Fprint(r.Writer, compactLabels[status.Label])
+}
+
+// paint returns color of console output, escapes are written according to Color mode of the reporter
+func (r ConsoleReporter) paint(attrs ...color.Attribute) *color.Color {
+	c := color.New(attrs...)
+	if r.Color == "" {
+		return c
+	}
+
+	if ColorEnabled(r.Color, r.Writer) {
+		c.EnableColor()
+	} else {
+		c.DisableColor()
+	}
+
+	return c
+}
+
+func (r ConsoleReporter) WriteWarning(content interface{}) ConsoleReporter {
+	c := r.paint(color.FgYellow)
+	c.Fprint(r.Writer, content)
+	return r
+}
+
+// WriteSkipReason writes reason of skipped test case or expectation in dimmed color of skipped status
+func (r ConsoleReporter) WriteSkipReason(content interface{}) ConsoleReporter {
+	c := r.paint(statusSkipped.Color, color.Faint)
+	c.Fprint(r.Writer, content)
+	return r
+}
+
+func (r ConsoleReporter) WriteDimmed(content interface{}) ConsoleReporter {
+	c := r.paint(color.FgHiBlack)
+	c.Fprint(r.Writer, content)
+	return r
+}
+
+func (r ConsoleReporter) WriteMultiline(content string, writer func(content interface{}) ConsoleReporter) ConsoleReporter {
+	for i, line := range strings.Split(content, "\n") {
+		if i > 0 {
+			r.StartLine()
+		}
+		writer(line)
+	}
+	return r
+}
+
+func (r ConsoleReporter) Write(content interface{}) ConsoleReporter {
+	r.Writer.Write([]byte(fmt.Sprintf("%v", content)))
+	return r
+}
+
+func (r ConsoleReporter) WriteStatus(status status, output int) ConsoleReporter {
+	c := r.paint(status.Color, color.Bold)
+	var val string
+
+	if output == outputIcon {
+		val = status.Icon
+	}
+
+	if output == outputLabel {
+		val = status.Label
+	}
+
+	c.Fprint(r.Writer, val)
+	return r
+}
+
+func (r ConsoleReporter) Flush() {
+	r.ioMutex.Lock()
+	r.execFrame.End = time.Now()
+
+	overall := "PASSED"
+	if r.failed != 0 {
+		overall = "FAILED"
+	}
+
+	if r.TreeSummary && len(r.suites) > 0 {
+		fmt.Fprintln(r.Writer)
+		fmt.Fprintln(r.Writer, "Summary by Package")
+		fmt.Fprintln(r.Writer, "-------------------------------")
+		writePackageTree(r.Writer, buildPackageTree(r.suites), 0)
+	}
+
+	fmt.Fprintln(r.Writer)
+	fmt.Fprintln(r.Writer, "Test Run Summary")
+	fmt.Fprintln(r.Writer, "-------------------------------")
+
+	w := tabwriter.NewWriter(r.Writer, 4, 2, 1, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "Overall result:\t %s\n", overall)
+
+	fmt.Fprintf(w, "Test count:\t %d\n", r.total)
+
+	fmt.Fprintf(w, "Passed:\t %d \n", r.total-r.failed-r.skipped)
+	fmt.Fprintf(w, "Failed:\t %d \n", r.failed)
+	fmt.Fprintf(w, "Skipped:\t %d \n", r.skipped)
+	fmt.Fprintf(w, "Warnings:\t %d \n", r.warnings)
+
+	start := r.execFrame.Start
+	end := r.execFrame.End
+
+	fmt.Fprintf(w, "Start time:\t %s\n", r.formatTime(start))
+	fmt.Fprintf(w, "End time:\t %s\n", r.formatTime(end))
+	fmt.Fprintf(w, "Duration:\t %s\n", end.Sub(start).Round(time.Millisecond))
+
+	fmt.Fprintf(w, "Request p50/p90/p99:\t %s\n", formatPercentiles(r.timings.Requests))
+	fmt.Fprintf(w, "Case p50/p90/p99:\t %s\n", formatPercentiles(r.timings.Cases))
+
+	w.Flush()
+	fmt.Fprintln(r.Writer)
+
+	if r.output != nil {
+		r.output.Close()
+	}
+	r.ioMutex.Unlock()
+}
+
+// formatTime renders time of the summary in the zone of the reporter, so it doesn't depend on the host
+func (r *ConsoleReporter) formatTime(t time.Time) string {
+	t = t.In(reportLocation(r.Location)).Round(time.Millisecond)
+	if r.RFC3339 {
+		return t.Format(rfc3339Millis)
+	}
+
+	return t.String()
+}
+
+// RFC 3339 with milliseconds, UTC is written as 'Z'
+const rfc3339Millis = "2006-01-02T15:04:05.000Z07:00"
+
+// reportLocation returns time zone of reports, UTC if not set
+func reportLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// formatOffset formats offset from test case start, e.g. '+120ms'
+func formatOffset(offset time.Duration) string {
+	if offset < 0 {
+		offset = 0
+	}
+	return "+" + offset.Round(time.Millisecond).String()
+}
+
+// NewConsoleReporter returns new instance of console reporter
+func NewConsoleReporter(logHTTP bool) Reporter {
+	return &ConsoleReporter{ExitCode: 0, ioMutex: &sync.Mutex{}, Writer: os.Stdout, LogHTTP: logHTTP}
+}
+
+// JUnitXMLReporter produces separate xml file for each test sute
+type JUnitXMLReporter struct {
+	// output directory
+	OutPath string
+	// indent xml with two spaces
+	Pretty bool
+	// requests and responses of passed test cases are written into their system-out
+	PassedDetails bool
+	// returns timestamp of suites, time.Now is used if not set. Fixed time makes report reproducible
+	Now func() time.Time
+	// templates of suite 'name' and 'package' attributes, see JUnitSuiteNames. Names of the model are used if not set
+	NameTemplate    string
+	PackageTemplate string
+	// time zone of suite timestamps, UTC if not set
+	Location *time.Location
+}
+
+func (r *JUnitXMLReporter) Init() {
+	// nothing to do here
+}
+
+type suite struct {
+	XMLName     string  `xml:"testsuite"`
+	ID          int     `xml:"id,attr"`
+	Name        string  `xml:"name,attr"`
+	PackageName string  `xml:"package,attr"`
+	TimeStamp   string  `xml:"timestamp,attr"`
+	Time        float64 `xml:"time,attr"`
+	HostName    string  `xml:"hostname,attr"`
+
+	Tests    int `xml:"tests,attr"`
+	Failures int `xml:"failures,attr"`
+	Errors   int `xml:"errors,attr"`
+	Skipped  int `xml:"skipped,attr"`
+
+	Properties properties `xml:"properties"`
+	Cases      []tc       `xml:"testcase"`
+
+	SystemOut string `xml:"system-out"`
+	SystemErr string `xml:"system-err"`
+
+	// name of the report file
+	fullName string
+	// classname of test cases, package and name of the suite
+	className string
+}
+
+type properties struct {
+	Property []property `xml:"property"`
+}
+
+type property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type tc struct {
+	Name      string  `xml:"name,attr"`
+	ClassName string  `xml:"classname,attr"`
+	Time      float64 `xml:"time,attr"`
+	Retries   int     `xml:"retries,attr,omitempty"`
+	Flaky     bool    `xml:"flaky,attr,omitempty"`
+	// skipped expectations of the calls
+	Properties *properties `xml:"properties,omitempty"`
+	Failure    *failure    `xml:"failure,omitempty"`
+	Error      *failure    `xml:"error,omitempty"`
+	Skipped    *skipped    `xml:"skipped,omitempty"`
+	SystemOut  string      `xml:"system-out,omitempty"`
+}
+
+type failure struct {
+	// not clear what type is but it's required
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Details string `xml:",chardata"`
+}
+
+type skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitErrorType returns type of reported failure and whether request was not completed,
+// that is reported as error rather than failed expectation
+func junitErrorType(err error) (string, bool) {
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.Category, true
+	}
+
+	var buildErr *RequestBuildError
+	if errors.As(err, &buildErr) {
+		return "RequestBuildError", true
+	}
+
+	return "FailedExpectation", false
+}
+
+func (r *JUnitXMLReporter) Report(results []TestResult) {
+
+	var suiteResult *suite
+	var suiteTimeFrame TimeFrame
+	for _, result := range results {
+
+		if suiteResult == nil {
+			name, pkg := r.suiteNames(result.Suite)
+			suiteResult = &suite{
+				ID:          0,
+				Name:        name,
+				PackageName: pkg,
+				TimeStamp:   r.now().In(reportLocation(r.Location)).Format(rfc3339Millis),
+				fullName:    result.Suite.FullName(),
+				className:   name,
+				HostName:    "localhost",
+			}
+			if pkg != "" {
+				suiteResult.className = pkg + "." + name
+			}
+
+			suiteTimeFrame = result.ExecFrame
+		}
+
+		testCase := tc{
+			Name:      result.Case.Name,
+			ClassName: suiteResult.className,
+			Time:      result.ExecFrame.Duration().Seconds(),
+			Retries:   result.retries(),
+		}
+		testCase.Flaky = testCase.Retries > 0 && !result.HasError() && !result.Skipped
+		testCase.Properties = junitSkippedExps(result)
+
+		if result.HasError() {
+			errType := "FailedExpectation"
+			errMsg := result.Error()
+
+			errIndex := 0
+			errRespDump := ""
+			notCompleted := false
+			for index, trace := range result.Traces {
+				if trace.HasError() {
+					errIndex = index
+					errRespDump = string(trace.ResponseDump)
+					errType, notCompleted = junitErrorType(trace.ErrorCause)
+				}
+			}
+
+			errDetails := fmt.Sprintf("On Call #%d - %s\n\n%s", errIndex+1, errMsg, errRespDump)
+
+			// response dump could contain binary body
+			testCaseFailure := &failure{
+				Type:    errType,
+				Message: strings.ToValidUTF8(errMsg, "\uFFFD"),
+				Details: strings.ToValidUTF8(errDetails, "\uFFFD"),
+			}
+
+			// request was not completed, it is an error rather than failed expectation
+			if notCompleted {
+				testCase.Error = testCaseFailure
+				suiteResult.Errors = suiteResult.Errors + 1
+			} else {
+				testCase.Failure = testCaseFailure
+				suiteResult.Failures = suiteResult.Failures + 1
+			}
+		}
+
+		if result.Skipped {
+			suiteResult.Skipped = suiteResult.Skipped + 1
+			testCase.Skipped = &skipped{Message: result.SkippedMsg}
+		}
+
+		if r.PassedDetails && !result.HasError() && !result.Skipped {
+			testCase.SystemOut = strings.ToValidUTF8(junitTranscript(result), "\uFFFD")
+		}
+
+		suiteResult.Tests = suiteResult.Tests + 1
+		suiteResult.ID = suiteResult.ID + 1
+		suiteResult.Cases = append(suiteResult.Cases, testCase)
+
+		suiteTimeFrame.Extend(result.ExecFrame)
+		suiteResult.Time = suiteTimeFrame.Duration().Seconds()
+	}
+
+	r.flushSuite(suiteResult)
+}
+
+func (r *JUnitXMLReporter) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+
+	return time.Now()
+}
+
+// junitSkippedExps lists skipped expectations of the calls as properties, e.g. 'Call #1: Header 'ETag' (JIRA-2)'
+func junitSkippedExps(result TestResult) *properties {
+	if result.skippedExps() == 0 {
+		return nil
+	}
+
+	props := &properties{}
+	for i, trace := range result.Traces {
+		for _, exp := range trace.skippedExps() {
+			props.Property = append(props.Property, property{
+				Name:  "skippedExpectation",
+				Value: fmt.Sprintf("Call #%d: %s (%s)", i+1, exp, trace.ExpSkipped[exp]),
+			})
+		}
+	}
+
+	return props
+}
+
+// junitTranscript lists requests and responses of all calls of the test case
+func junitTranscript(result TestResult) string {
+	calls := make([]string, 0, len(result.Traces))
+	for i, trace := range result.Traces {
+		calls = append(calls, fmt.Sprintf("Call #%d\n%s\n\n%s", i+1, trace.RequestDump, trace.ResponseDump))
+	}
+
+	return strings.Join(calls, "\n\n")
+}
+
+func (r JUnitXMLReporter) flushSuite(suite *suite) {
+	if suite == nil {
+		return
+	}
+
+	fileName := suite.fullName + ".xml"
+	fp := filepath.Join(r.OutPath, fileName)
+	err := os.MkdirAll(r.OutPath, 0777)
+	if err != nil {
+		panic(err)
+	}
+
+	var data []byte
+	if r.Pretty {
+		data, err = xml.MarshalIndent(suite, "", "  ")
+	} else {
+		data, err = xml.Marshal(suite)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	data = append([]byte(xml.Header), data...)
+
+	err = writeFileAtomic(fp, data)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file first and then renames it,
+// so interrupted run never leaves half-written report behind.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0644)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+func (r JUnitXMLReporter) Flush() {
+
+}
+
+func NewJUnitReporter(outdir string) Reporter {
+	return &JUnitXMLReporter{OutPath: outdir}
+}
+
+// MultiReporter broadcasts events to another reporters.
+type MultiReporter struct {
+	Reporters []Reporter
+	// one of Reporters which is initialized first and flushed last, e.g. console one users watch
+	Primary Reporter
+}
+
+func (r MultiReporter) Report(results []TestResult) {
+	for _, reporter := range r.Reporters {
+		reporter.Report(results)
+	}
+}
+
+func (r MultiReporter) Init() {
+	if r.Primary != nil {
+		r.Primary.Init()
+	}
+
+	for _, reporter := range r.Reporters {
+		if !r.isPrimary(reporter) {
+			reporter.Init()
+		}
+	}
+}
+
+// Flush flushes reporters in order and the primary one last.
+// Panic of a reporter does not prevent others from flushing, the first one is repeated once all of them are flushed.
+func (r MultiReporter) Flush() {
+	var failure interface{}
+
+	for _, reporter := range r.Reporters {
+		if r.isPrimary(reporter) {
+			continue
+		}
+
+		if err := flushIsolated(reporter); err != nil && failure == nil {
+			failure = err
+		}
+	}
+
+	if r.Primary != nil {
+		if err := flushIsolated(r.Primary); err != nil && failure == nil {
+			failure = err
+		}
+	}
+
+	if failure != nil {
+		panic(failure)
+	}
+}
+
+func (r MultiReporter) isPrimary(reporter Reporter) bool {
+	return r.Primary != nil && reporter == r.Primary
+}
+
+// flushIsolated flushes reporter and returns its panic if any
+func flushIsolated(reporter Reporter) (failure interface{}) {
+	defer func() {
+		if failure = recover(); failure != nil {
+			fmt.Fprintf(os.Stderr, "Reporter %T failed to flush: %v\n", reporter, failure)
+		}
+	}()
+
+	reporter.Flush()
+	return nil
+}
+
+// NewMultiReporter creates new reporter that broadcasts events to another reporters.
+func NewMultiReporter(reporters ...Reporter) Reporter {
+	return &MultiReporter{Reporters: reporters}
+}
+
+// NewPrimaryMultiReporter creates new reporter that broadcasts events to primary and another reporters.
+// Primary reporter is initialized first and flushed last.
+func NewPrimaryMultiReporter(primary Reporter, reporters ...Reporter) Reporter {
+	return &MultiReporter{Reporters: append([]Reporter{primary}, reporters...), Primary: primary}
+}
+
+// ReporterOptions configure reporter created by name, see RegisterReporter
+type ReporterOptions struct {
+	// destination of the report, e.g. file or directory, 'file:' prefix is optional.
+	// 'stdout' and 'stderr' select streams for reporters that support them. Reporter default is used if empty.
+	Output string
+	// streams selected by 'stdout' and 'stderr' outputs, os.Stdout and os.Stderr if not set
+	Stdout io.Writer
+	Stderr io.Writer
+	// stream selected by output or file opened for console reporter, set by NewReporter
+	Writer io.Writer
+	// file output of console reporter is appended instead of replaced, e.g. by every run of '--repeat-run' after the first one
+	Append bool
+	// print request and response details
+	LogHTTP bool
+	// indent the report, if supported by reporter
+	Pretty bool
+	// group results by package in the summary, if supported by reporter
+	TreeSummary bool
+	// show request start offsets from test case start, if supported by reporter
+	ShowOffsets bool
+	// one line per test case, if supported by reporter
+	Compact bool
+	// ColorAuto, ColorAlways or ColorNever, if supported by reporter
+	Color string
+	// record requests and responses of passed test cases, if supported by reporter
+	PassedDetails bool
+	// templates of suite name and package, if supported by reporter, see JUnitSuiteNames
+	SuiteNameTemplate string
+	PackageTemplate   string
+	// returns time written into the report, if supported by reporter. time.Now is used if not set
+	Now func() time.Time
+	// time zone of timestamps, if supported by reporter. UTC is used if not set
+	Location *time.Location
+	// format timestamps as RFC 3339, if supported by reporter
+	RFC3339 bool
+
+	// file opened by NewReporter for the output, closed by the reporter on Flush
+	file *os.File
+}
+
+// ReporterFactory creates reporter configured with provided options
+type ReporterFactory func(opts ReporterOptions) Reporter
+
+var reporterFactories = make(map[string]ReporterFactory)
+
+// RegisterReporter makes reporter available by name for '--reporter' option.
+// Custom reporters are compiled in by adding a file that registers them in init() function.
+// It panics if the name is already registered.
+func RegisterReporter(name string, factory ReporterFactory) {
+	if _, ok := reporterFactories[name]; ok {
+		panic("reporter '" + name + "' is already registered")
+	}
+
+	reporterFactories[name] = factory
+}
+
+// NewReporter creates registered reporter by name
+func NewReporter(name string, opts ReporterOptions) (Reporter, error) {
+	factory, ok := reporterFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown reporter '%s'. Available reporters: %s", name, strings.Join(ReporterNames(), ", "))
+	}
+
+	switch opts.Output {
+	case outputStdout, outputStderr:
+		if fileReporters[name] {
+			return nil, fmt.Errorf("Reporter '%s' writes files, its output can't be %s", name, opts.Output)
+		}
+
+		opts.Writer = opts.stream()
+	default:
+		opts.Output = strings.TrimPrefix(opts.Output, outputFilePrefix)
+
+		// console output is written while test suites are running
+		if name == "console" && opts.Output != "" {
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if opts.Append {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			}
+
+			file, err := os.OpenFile(opts.Output, flags, 0666)
+			if err != nil {
+				return nil, fmt.Errorf("Can't create console output: %s", err)
+			}
+			opts.Writer = file
+			opts.file = file
+		}
+	}
+
+	return factory(opts), nil
+}
+
+// ValidateReporter checks reporter is registered and could be used in this build, e.g. SQLite driver is compiled in
+func ValidateReporter(name string) error {
+	if _, ok := reporterFactories[name]; !ok {
+		return fmt.Errorf("Unknown reporter '%s'. Available reporters: %s", name, strings.Join(ReporterNames(), ", "))
+	}
+
+	if name == "sqlite" && !sqliteDriverAvailable() {
+		return errSQLiteNotCompiled
+	}
+
+	return nil
+}
+
+// FlushError returns failure of the last Flush of the reporter or any reporter of MultiReporter,
+// if they report it instead of panicking, e.g. SQLiteReporter
+func FlushError(reporter Reporter) error {
+	var reporters []Reporter
+	switch r := reporter.(type) {
+	case *MultiReporter:
+		reporters = r.Reporters
+	case MultiReporter:
+		reporters = r.Reporters
+	case interface{ FlushError() error }:
+		return r.FlushError()
+	}
+
+	for _, r := range reporters {
+		if err := FlushError(r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	outputStdout     = "stdout"
+	outputStderr     = "stderr"
+	outputFilePrefix = "file:"
+)
+
+// reporters that write files or directories only
+var fileReporters = map[string]bool{"junit": true, "allure": true, "sqlite": true}
+
+func (opts ReporterOptions) stream() io.Writer {
+	if opts.Output == outputStderr {
+		if opts.Stderr != nil {
+			return opts.Stderr
+		}
+		return os.Stderr
+	}
+
+	if opts.Stdout != nil {
+		return opts.Stdout
+	}
+	return os.Stdout
+}
+
+// ReporterNames lists names of registered reporters in alphabetical order
+func ReporterNames() []string {
+	names := make([]string, 0, len(reporterFactories))
+	for name := range reporterFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func reporterOutput(opts ReporterOptions, defaultPath string) string {
+	path := opts.Output
+	if path == "" {
+		path = defaultPath
+	}
+
+	abs, _ := filepath.Abs(path)
+	return abs
+}
+
+func init() {
+	RegisterReporter("console", func(opts ReporterOptions) Reporter {
+		reporter := NewConsoleReporter(opts.LogHTTP).(*ConsoleReporter)
+		reporter.TreeSummary = opts.TreeSummary
+		reporter.ShowOffsets = opts.ShowOffsets
+		reporter.Compact = opts.Compact
+		reporter.Color = opts.Color
+		reporter.Location = opts.Location
+		reporter.RFC3339 = opts.RFC3339
+		if opts.Writer != nil {
+			reporter.Writer = opts.Writer
+		}
+		if opts.file != nil {
+			reporter.output = opts.file
+		}
+		return reporter
+	})
+
+	RegisterReporter("junit", func(opts ReporterOptions) Reporter {
+		return &JUnitXMLReporter{
+			OutPath:         reporterOutput(opts, "./report"),
+			Pretty:          opts.Pretty,
+			PassedDetails:   opts.PassedDetails,
+			Now:             opts.Now,
+			NameTemplate:    opts.SuiteNameTemplate,
+			PackageTemplate: opts.PackageTemplate,
+			Location:        opts.Location,
+		}
+	})
+
+	RegisterReporter("allure", func(opts ReporterOptions) Reporter {
+		return NewAllureReporter(reporterOutput(opts, "./allure-results"))
+	})
+
+	RegisterReporter("json", func(opts ReporterOptions) Reporter {
+		if opts.Writer != nil {
+			return &JSONReporter{Writer: opts.Writer}
+		}
+		return NewJSONReporter(reporterOutput(opts, "./report.json"))
+	})
+
+	RegisterReporter("sqlite", func(opts ReporterOptions) Reporter {
+		return NewSQLiteReporter(reporterOutput(opts, "./results.db"))
+	})
+
+	RegisterReporter("timings", func(opts ReporterOptions) Reporter {
+		if opts.Writer != nil {
+			return &TimingsReporter{Writer: opts.Writer}
+		}
+		return NewTimingsReporter(reporterOutput(opts, "./timings.json"))
+	})
+}
@@ -0,0 +1,817 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"github.com/fatih/color"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestConsoleReporterReport_ErrorAfterPassedExp_Reported(t *testing.T) {
+	// given
+	reportedError := "test err"
+	results := []TestResult{
+		{
+			Traces: []*CallTrace{
+				{
+					ExpDesc:    map[string]bool{"Status code is 200": false},
+					ErrorCause: errors.New(reportedError),
+				},
+			},
+		}, // error without expectation description
+	}
+
+	writer := MockWriter{
+		expectedWriting: reportedError,
+	}
+
+	reporter := &ConsoleReporter{ExitCode: 0, Writer: &writer, ioMutex: &sync.Mutex{}, LogHTTP: false}
+
+	color.Output = &writer // prevent stdout and invalid test result parsing in IDE (reacts on words 'FAILED')
+
+	// when
+	reporter.Report(results)
+
+	// then
+	if !writer.passed() {
+		t.Errorf("Expected writing %s was not met in %s", writer.expectedWriting, writer.actualWriting)
+	}
+}
+
+type MockWriter struct {
+	expectedWriting string
+	actualWriting   string
+}
+
+func (mw *MockWriter) Write(p []byte) (n int, err error) {
+
+	in := string(p)
+	mw.actualWriting += in
+
+	//os.Stdout.Write(p)
+
+	return len(p), nil
+}
+
+func (mw *MockWriter) passed() bool {
+	return strings.Contains(mw.actualWriting, mw.expectedWriting)
+}
+
+func TestJUnitReporterPretty(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	result := TestResult{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "create"}}
+
+	// when
+	(&JUnitXMLReporter{OutPath: dir, Pretty: true}).Report([]TestResult{result})
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(data)
+	if !strings.HasPrefix(content, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<testsuite") {
+		t.Error("Expected xml declaration, got:", content)
+	}
+
+	if !strings.Contains(content, "\n  <testcase name=\"create\"") {
+		t.Error("Expected two-space indentation, got:", content)
+	}
+}
+
+func TestJUnitReporterFixedTimestamp(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	fixed := time.Date(2021, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600))
+	result := TestResult{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "create"}}
+
+	// when
+	(&JUnitXMLReporter{OutPath: dir, Now: func() time.Time { return fixed }}).Report([]TestResult{result})
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `timestamp="2021-03-01T11:30:00.000Z"`) {
+		t.Error("Expected fixed timestamp in UTC, got:", string(data))
+	}
+}
+
+func TestReportTimezone(t *testing.T) {
+	// given
+	defer func(local *time.Location) { time.Local = local }(time.Local)
+	time.Local = time.FixedZone("HOST", 5*3600)
+
+	start := time.Date(2021, 3, 1, 17, 30, 0, 123456789, time.Local)
+	berlin := time.FixedZone("CET", 3600)
+
+	tests := []struct {
+		reporter *ConsoleReporter
+		expected string
+	}{
+		{&ConsoleReporter{}, "2021-03-01 12:30:00.123 +0000 UTC"},
+		{&ConsoleReporter{RFC3339: true}, "2021-03-01T12:30:00.123Z"},
+		{&ConsoleReporter{Location: berlin, RFC3339: true}, "2021-03-01T13:30:00.123+01:00"},
+	}
+
+	for _, tt := range tests {
+		// when
+		actual := tt.reporter.formatTime(start)
+
+		// then
+		if actual != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, actual)
+		}
+	}
+
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	result := TestResult{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "create"}}
+	(&JUnitXMLReporter{OutPath: dir, Location: berlin, Now: func() time.Time { return start }}).Report([]TestResult{result})
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `timestamp="2021-03-01T13:30:00.123+01:00"`) {
+		t.Error("Expected timestamp in the zone of the reporter, got:", string(data))
+	}
+}
+
+func TestJUnitReporterNameTemplates(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+	options.Env = "staging"
+
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	result := TestResult{Suite: TestSuite{Name: "create", Dir: "orders"}, Case: TestCase{Name: "created"}}
+	reporter := &JUnitXMLReporter{OutPath: dir, PackageTemplate: "{{.Env}}.{{.Package}}"}
+
+	// when
+	reporter.Report([]TestResult{result})
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "orders.create.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, `name="create" package="staging.orders"`) {
+		t.Error("Expected package from template, got:", content)
+	}
+
+	if !strings.Contains(content, `classname="staging.orders.create"`) {
+		t.Error("Expected classname of test case with templated package, got:", content)
+	}
+}
+
+func TestValidateJUnitTemplate(t *testing.T) {
+	for _, valid := range []string{"", "{{.Env}}-{{.FullName}}", `{{env "HOME"}}.{{.Package}}`} {
+		if err := ValidateJUnitTemplate(valid); err != nil {
+			t.Errorf("Expected %s to be valid, got %s", valid, err)
+		}
+	}
+
+	for _, invalid := range []string{"{{.Env", "{{.Suite}}", `{{unknown "A"}}`} {
+		if err := ValidateJUnitTemplate(invalid); err == nil {
+			t.Errorf("Expected %s to be invalid", invalid)
+		}
+	}
+}
+
+func TestJUnitReporterRetries(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	results := []TestResult{
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "retried"}, Traces: []*CallTrace{{Retries: 1}, {Retries: 2}}},
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "stable"}, Traces: []*CallTrace{{}}},
+	}
+
+	// when
+	(&JUnitXMLReporter{OutPath: dir}).Report(results)
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(data)
+	if !strings.Contains(content, `<testcase name="retried" classname="users" time="0" retries="3" flaky="true">`) {
+		t.Error("Expected retries and flaky marker, got:", content)
+	}
+
+	if !strings.Contains(content, `<testcase name="stable" classname="users" time="0">`) {
+		t.Error("Expected no retries attributes for stable case, got:", content)
+	}
+}
+
+func TestJUnitReporterSkippedExpectations(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	results := []TestResult{
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "get"}, Traces: []*CallTrace{
+			{ExpSkipped: map[string]string{"Header 'ETag'": "JIRA-2"}},
+			{ExpSkipped: map[string]string{"Status code is 200": "prod", "Body contains 'id'": "JIRA-3"}},
+		}},
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "list"}, Traces: []*CallTrace{{}}},
+	}
+
+	// when
+	(&JUnitXMLReporter{OutPath: dir}).Report(results)
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := string(data)
+	expected := `<testcase name="get" classname="users" time="0"><properties>` +
+		`<property name="skippedExpectation" value="Call #1: Header &#39;ETag&#39; (JIRA-2)"></property>` +
+		`<property name="skippedExpectation" value="Call #2: Body contains &#39;id&#39; (JIRA-3)"></property>` +
+		`<property name="skippedExpectation" value="Call #2: Status code is 200 (prod)"></property>` +
+		`</properties></testcase>`
+	if !strings.Contains(content, expected) {
+		t.Error("Expected skipped expectations in properties of the test case, got:", content)
+	}
+
+	if !strings.Contains(content, `<testcase name="list" classname="users" time="0"></testcase>`) {
+		t.Error("Expected no properties of the test case without skipped expectations, got:", content)
+	}
+}
+
+func TestJUnitReporterPassedDetails(t *testing.T) {
+	results := []TestResult{
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "passed"}, Traces: []*CallTrace{{RequestDump: "GET /users/1", ResponseDump: "HTTP/1.1 200 OK"}}},
+		{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "failed"}, Traces: []*CallTrace{{RequestDump: "GET /users/2", ResponseDump: "HTTP/1.1 404 Not Found", ErrorCause: errors.New("Unexpected Status Code")}}},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		dir, _ := ioutil.TempDir("", "bozr-junit")
+		defer os.RemoveAll(dir)
+
+		// when
+		(&JUnitXMLReporter{OutPath: dir, PassedDetails: enabled}).Report(results)
+
+		// then
+		data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content := string(data)
+		transcript := "<system-out>Call #1&#xA;GET /users/1&#xA;&#xA;HTTP/1.1 200 OK</system-out></testcase>"
+		if strings.Contains(content, transcript) != enabled {
+			t.Errorf("Expected passed case transcript %v, got: %s", enabled, content)
+		}
+
+		if strings.Contains(content, "GET /users/2") {
+			t.Error("Expected no transcript of failed case in system-out, got:", content)
+		}
+	}
+}
+
+func TestJUnitReporterBinaryDetails(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(dir)
+
+	result := TestResult{
+		Suite: TestSuite{Name: "files"},
+		Case:  TestCase{Name: "download"},
+		Traces: []*CallTrace{{
+			ErrorCause:   errors.New("unexpected body \xff\xfe"),
+			ResponseDump: "200 OK\n\n\x89PNG\x00\x1a\xff\xd8",
+		}},
+	}
+
+	// when
+	NewJUnitReporter(dir).Report([]TestResult{result})
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "files.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(data), `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Error("Expected xml declaration, got:", string(data))
+	}
+
+	if !utf8.Valid(data) {
+		t.Error("Report is not valid UTF-8")
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+	for {
+		_, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal("Report is not valid XML:", err)
+		}
+	}
+}
+
+func TestJUnitReporterEmptyResults(t *testing.T) {
+	// given
+
+	// when
+	NewJUnitReporter("").Report([]TestResult{})
+
+	// then
+	// no nil pointer panic
+}
+
+func TestNewReporterUnknownName(t *testing.T) {
+	_, err := NewReporter("dashboard", ReporterOptions{})
+
+	if err == nil || !strings.Contains(err.Error(), "Unknown reporter 'dashboard'. Available reporters: allure, console, json, junit") {
+		t.Error("Expected unknown reporter error, got:", err)
+	}
+}
+
+func TestNewReporterOutputStreams(t *testing.T) {
+	// given
+	var stdout, stderr bytes.Buffer
+	color.Output = ioutil.Discard
+
+	console, err := NewReporter("console", ReporterOptions{Output: "stderr", Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := NewReporter("json", ReporterOptions{Output: "stdout", Stdout: &stdout, Stderr: &stderr})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reporter := NewMultiReporter(console, report)
+	results := []TestResult{{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "get user"}}}
+
+	// when
+	reporter.Init()
+	reporter.Report(results)
+	reporter.Flush()
+
+	// then
+	if !strings.Contains(stderr.String(), "get user") || !strings.Contains(stderr.String(), "Test Run Summary") {
+		t.Errorf("Expected console output in stderr, got '%s'", stderr.String())
+	}
+
+	var parsed jsonReport
+	if err := json.Unmarshal(stdout.Bytes(), &parsed); err != nil {
+		t.Fatalf("Expected only JSON report in stdout, got '%s': %s", stdout.String(), err)
+	}
+	if parsed.Summary.Total != 1 {
+		t.Errorf("Expected 1 test case in JSON report, got %d", parsed.Summary.Total)
+	}
+}
+
+func TestNewReporterConsoleFileAppended(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-console")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "console.log")
+	color.Output = ioutil.Discard
+
+	report := func(name string, appended bool) string {
+		reporter, err := NewReporter("console", ReporterOptions{Output: path, Append: appended})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reporter.Init()
+		reporter.Report([]TestResult{{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: name}}})
+		reporter.Flush()
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	// when
+	report("first run", false)
+	repeated := report("second run", true)
+	next := report("next process", false)
+
+	// then
+	if !strings.Contains(repeated, "first run") || !strings.Contains(repeated, "second run") || strings.Count(repeated, "Test Run Summary") != 2 {
+		t.Errorf("Expected output of both runs, got '%s'", repeated)
+	}
+
+	if strings.Contains(next, "first run") || !strings.Contains(next, "next process") {
+		t.Errorf("Expected output to be replaced, got '%s'", next)
+	}
+}
+
+func TestNewReporterFileOutputToStream(t *testing.T) {
+	_, err := NewReporter("junit", ReporterOptions{Output: "stdout"})
+
+	if err == nil || err.Error() != "Reporter 'junit' writes files, its output can't be stdout" {
+		t.Error("Expected stream output error, got:", err)
+	}
+}
+
+func TestConsoleReporterWarningsSummary(t *testing.T) {
+	// given
+	results := []TestResult{
+		{
+			Case: TestCase{Name: "deprecated api"},
+			Traces: []*CallTrace{
+				{
+					ExpDesc:     map[string]bool{"Status code is 200": false},
+					ExpWarnings: map[string]string{"Header 'Deprecation'": "Header 'Deprecation' not found"},
+				},
+			},
+		},
+	}
+
+	writer := MockWriter{}
+	color.Output = &writer
+
+	reporter := &ConsoleReporter{Writer: &writer, ioMutex: &sync.Mutex{}}
+	reporter.Init()
+
+	// when
+	reporter.Report(results)
+	reporter.Flush()
+
+	// then
+	if reporter.failed != 0 || reporter.warnings != 1 {
+		t.Errorf("Expected 0 failed and 1 warning, got %d failed and %d warnings", reporter.failed, reporter.warnings)
+	}
+
+	for _, expected := range []string{"Header 'Deprecation' not found", "Overall result: PASSED", "Warnings: 1"} {
+		writer.expectedWriting = expected
+		if !writer.passed() {
+			t.Errorf("Expected writing %s was not met in %s", expected, writer.actualWriting)
+		}
+	}
+}
+
+func TestConsoleReporterSkippedStyle(t *testing.T) {
+	// given
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+	color.NoColor = false
+
+	results := []TestResult{
+		{Case: TestCase{Name: "legacy"}, Skipped: true, SkippedMsg: "JIRA-1"},
+		{
+			Case: TestCase{Name: "users"},
+			Traces: []*CallTrace{
+				{
+					ExpDesc:    map[string]bool{"Status code is 200": false},
+					ExpSkipped: map[string]string{"Header 'ETag'": "JIRA-2"},
+				},
+			},
+		},
+	}
+
+	writer := MockWriter{}
+	color.Output = &writer
+
+	reporter := &ConsoleReporter{Writer: &writer, ioMutex: &sync.Mutex{}}
+
+	// when
+	reporter.Report(results)
+
+	// then
+	dimmedYellow := "\x1b[33;2m"
+	for _, expected := range []string{
+		"legacy (" + dimmedYellow + "JIRA-1",
+		"−\x1b[0m Header 'ETag' (skipped expectation: " + dimmedYellow + "JIRA-2",
+	} {
+		writer.expectedWriting = expected
+		if !writer.passed() {
+			t.Errorf("Expected writing %q was not met in %q", expected, writer.actualWriting)
+		}
+	}
+}
+
+func TestConsoleReporterColorModes(t *testing.T) {
+	defer func(noColor bool) { color.NoColor = noColor }(color.NoColor)
+
+	tests := []struct {
+		mode    string
+		noColor bool
+		colored bool
+	}{
+		{mode: ColorAlways, noColor: true, colored: true},
+		{mode: ColorNever, noColor: false, colored: false},
+		{mode: ColorAuto, noColor: false, colored: false},
+		{mode: "", noColor: false, colored: true},
+		{mode: "", noColor: true, colored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			// given
+			color.NoColor = tt.noColor
+
+			// non-terminal output
+			writer := MockWriter{}
+			color.Output = ioutil.Discard
+			reporter := &ConsoleReporter{Writer: &writer, ioMutex: &sync.Mutex{}, Color: tt.mode}
+
+			// when
+			reporter.Report([]TestResult{
+				{Case: TestCase{Name: "passed"}, Traces: []*CallTrace{{ExpDesc: map[string]bool{"Status code is 200": true}}}},
+				{Case: TestCase{Name: "skipped"}, Skipped: true, SkippedMsg: "JIRA-1"},
+			})
+
+			// then
+			if colored := strings.Contains(writer.actualWriting, "\x1b["); colored != tt.colored {
+				t.Errorf("Expected colored output: %v, got %q", tt.colored, writer.actualWriting)
+			}
+			if !strings.Contains(writer.actualWriting, "passed") || !strings.Contains(writer.actualWriting, "JIRA-1") {
+				t.Errorf("Expected test cases in output, got %q", writer.actualWriting)
+			}
+		})
+	}
+}
+
+func TestColorEnabledFile(t *testing.T) {
+	f, err := ioutil.TempFile("", "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if ColorEnabled(ColorAuto, f) {
+		t.Error("Expected file not to be colored in auto mode")
+	}
+
+	if !ColorEnabled(ColorAlways, f) {
+		t.Error("Expected file to be colored in always mode")
+	}
+
+	if err := ValidateColorMode("sometimes"); err == nil || err.Error() != "Invalid color mode 'sometimes', expected auto, always or never" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestConsoleReporterTreeSummary(t *testing.T) {
+	// given
+	failed := []*CallTrace{{ErrorCause: errors.New("Status code is 500")}}
+
+	suites := [][]TestResult{
+		{
+			{Suite: TestSuite{Name: "Create order", Dir: "api/orders"}, Case: TestCase{Name: "created"}},
+			{Suite: TestSuite{Name: "Create order", Dir: "api/orders"}, Case: TestCase{Name: "invalid"}, Traces: failed},
+		},
+		{
+			{Suite: TestSuite{Name: "Get user", Dir: "api/users"}, Case: TestCase{Name: "found"}},
+			{Suite: TestSuite{Name: "Get user", Dir: "api/users"}, Case: TestCase{Name: "legacy"}, Skipped: true},
+		},
+		{
+			{Suite: TestSuite{Name: "Delete user", Dir: "api/users"}, Case: TestCase{Name: "deleted"}},
+		},
+		{
+			{Suite: TestSuite{Name: "Health", Dir: "."}, Case: TestCase{Name: "up"}},
+		},
+	}
+
+	var out bytes.Buffer
+	color.Output = ioutil.Discard
+
+	reporter := &ConsoleReporter{Writer: &out, ioMutex: &sync.Mutex{}, TreeSummary: true}
+	reporter.Init()
+
+	// when
+	for _, results := range suites {
+		reporter.Report(results)
+	}
+	reporter.Flush()
+
+	// then
+	tree := buildPackageTree(reporter.suites)
+
+	api := tree.Packages["api"]
+	if api == nil || api.Counts != (resultCounts{Passed: 3, Failed: 1, Skipped: 1}) {
+		t.Fatalf("Unexpected counts of package 'api': %+v", api)
+	}
+	if users := api.Packages["users"]; users.Counts != (resultCounts{Passed: 2, Skipped: 1}) || len(users.Suites) != 2 {
+		t.Errorf("Unexpected package 'api.users': %+v", users)
+	}
+	if tree.Counts != (resultCounts{Passed: 4, Failed: 1, Skipped: 1}) {
+		t.Errorf("Unexpected total counts: %+v", tree.Counts)
+	}
+
+	expected := strings.Join([]string{
+		"api (3 passed, 1 failed, 1 skipped)",
+		"    orders (1 passed, 1 failed, 0 skipped)",
+		"        └ Create order (1 passed, 1 failed, 0 skipped)",
+		"    users (2 passed, 0 failed, 1 skipped)",
+		"        └ Delete user (1 passed, 0 failed, 0 skipped)",
+		"        └ Get user (1 passed, 0 failed, 1 skipped)",
+		"└ Health (1 passed, 0 failed, 0 skipped)",
+	}, "\n")
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("Expected tree\n%s\nin\n%s", expected, out.String())
+	}
+}
+
+func TestConsoleReporterShowOffsets(t *testing.T) {
+	// given
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	trace := func(url string, startOffset, duration time.Duration) *CallTrace {
+		return &CallTrace{
+			RequestMethod: "GET",
+			RequestURL:    url,
+			ExecFrame:     TimeFrame{Start: start.Add(startOffset), End: start.Add(startOffset + duration)},
+		}
+	}
+
+	results := []TestResult{
+		{
+			Case:      TestCase{Name: "checkout"},
+			ExecFrame: TimeFrame{Start: start, End: start.Add(time.Second)},
+			Traces: []*CallTrace{
+				trace("/cart", 3*time.Millisecond, 117*time.Millisecond),
+				trace("/orders", 120*time.Millisecond, 380*time.Millisecond),
+				trace("/orders/1", 500*time.Millisecond, 20*time.Millisecond),
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	color.Output = ioutil.Discard
+
+	reporter := &ConsoleReporter{Writer: &out, ioMutex: &sync.Mutex{}, LogHTTP: true, ShowOffsets: true}
+
+	// when
+	reporter.Report(results)
+
+	// then
+	matches := regexp.MustCompile(`\+(\S+) GET `).FindAllStringSubmatch(out.String(), -1)
+	if len(matches) != 3 {
+		t.Fatalf("Expected 3 request lines with offsets, got %d in %s", len(matches), out.String())
+	}
+
+	var previous time.Duration
+	for i, match := range matches {
+		offset, err := time.ParseDuration(match[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && offset <= previous {
+			t.Errorf("Offsets are not monotonic: %s after %s", offset, previous)
+		}
+		previous = offset
+	}
+
+	if matches[1][1] != "120ms" {
+		t.Errorf("Expected offset of the second request +120ms, got +%s", matches[1][1])
+	}
+}
+
+// eventsReporter appends its events to the shared log, optionally panics on Flush
+type eventsReporter struct {
+	name       string
+	log        *[]string
+	flushPanic bool
+}
+
+func (r *eventsReporter) Init() { *r.log = append(*r.log, r.name+".Init") }
+
+func (r *eventsReporter) Report(results []TestResult) { *r.log = append(*r.log, r.name+".Report") }
+
+func (r *eventsReporter) Flush() {
+	*r.log = append(*r.log, r.name+".Flush")
+	if r.flushPanic {
+		panic("disk is full")
+	}
+}
+
+func TestMultiReporterPrimaryFlushedLast(t *testing.T) {
+	// given
+	var log []string
+	junit := &eventsReporter{name: "junit", log: &log, flushPanic: true}
+	console := &eventsReporter{name: "console", log: &log}
+	json := &eventsReporter{name: "json", log: &log}
+
+	reporter := &MultiReporter{Reporters: []Reporter{junit, console, json}, Primary: console}
+
+	// when
+	reporter.Init()
+	reporter.Report(nil)
+
+	var failure interface{}
+	func() {
+		defer func() { failure = recover() }()
+		reporter.Flush()
+	}()
+
+	// then
+	expected := "console.Init junit.Init json.Init junit.Report console.Report json.Report junit.Flush json.Flush console.Flush"
+	if strings.Join(log, " ") != expected {
+		t.Errorf("Unexpected order of events:\n%s\nexpected:\n%s", strings.Join(log, " "), expected)
+	}
+
+	if failure != "disk is full" {
+		t.Errorf("Expected flush panic to be repeated after all reporters flushed, got %v", failure)
+	}
+}
+
+func TestConsoleReporterCompact(t *testing.T) {
+	// given
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	frame := TimeFrame{Start: start, End: start.Add(120 * time.Millisecond)}
+	suite := TestSuite{Name: "Users"}
+
+	failedTrace := &CallTrace{RequestMethod: "POST", RequestURL: "/users", ExecFrame: frame}
+	failedTrace.addExp("Status code is 201")
+	failedTrace.addFail(errors.New("Unexpected Status Code. Expected: 201, Actual: 500\nResponse body is empty"))
+
+	results := []TestResult{
+		{Suite: suite, Case: TestCase{Name: "GetUser"}, ExecFrame: frame, Traces: []*CallTrace{{RequestMethod: "GET", RequestURL: "/users/1", ExecFrame: frame}}},
+		{Suite: suite, Case: TestCase{Name: "CreateUser"}, ExecFrame: frame, Traces: []*CallTrace{failedTrace}},
+		{Suite: suite, Case: TestCase{Name: "DeleteUser"}, Skipped: true, SkippedMsg: "not implemented"},
+	}
+
+	var out bytes.Buffer
+	reporter := &ConsoleReporter{Writer: &out, ioMutex: &sync.Mutex{}, Compact: true}
+
+	// when
+	reporter.Report(results)
+
+	// then
+	lines := strings.Split(out.String(), "\n")
+	expected := []string{
+		"PASS Users/GetUser 120ms",
+		"FAIL Users/CreateUser 120ms Unexpected Status Code. Expected: 201, Actual: 500",
+		"    POST /users [120ms]",
+		"    Unexpected Status Code. Expected: 201, Actual: 500",
+		"    Response body is empty",
+		"SKIP Users/DeleteUser (not implemented)",
+	}
+	if len(lines) < len(expected) {
+		t.Fatalf("Expected %d lines, got:\n%s", len(expected), out.String())
+	}
+	for i, line := range expected {
+		if lines[i] != line {
+			t.Errorf("Line %d expected:\n%s\ngot:\n%s", i+1, line, lines[i])
+		}
+	}
+
+	if reporter.total != 3 || reporter.failed != 1 || reporter.skipped != 1 {
+		t.Errorf("Unexpected counts: total %d, failed %d, skipped %d", reporter.total, reporter.failed, reporter.skipped)
+	}
+}
+
+func TestValidateReporterWithoutSQLite(t *testing.T) {
+	if sqliteDriverAvailable() {
+		t.Skip("SQLite driver is compiled in")
+	}
+
+	if err := ValidateReporter("sqlite"); err != errSQLiteNotCompiled {
+		t.Errorf("Expected error of missing SQLite driver, got %v", err)
+	}
+
+	if err := ValidateReporter("console"); err != nil {
+		t.Error(err)
+	}
+
+	// failure is reported instead of panic
+	reporter := NewPrimaryMultiReporter(NewConsoleReporter(false), NewSQLiteReporter("./results.db"))
+	reporter.(*MultiReporter).Reporters[1].Flush()
+
+	if err := FlushError(reporter); err == nil || !strings.Contains(err.Error(), errSQLiteNotCompiled.Error()) {
+		t.Errorf("Expected flush error, got %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// DefaultMaxResponseSize is max number of bytes of response body read into memory
+const DefaultMaxResponseSize = 10 * 1024 * 1024
+
+// keys of 'expect' section that assert content of the body, they fail if the body is not read completely
+var bodyContentKeys = map[string]bool{
+	"bodySchemaURI":  true,
+	"bodySchemaFile": true,
+	"bodySchema":     true,
+	"openapi":        true,
+	"bodyPath":       true,
+	"anyMatch":       true,
+	"allMatch":       true,
+	"noneMatch":      true,
+	"body":           true,
+	"exactBody":      true,
+	"oneOf":          true,
+	"consistentWith": true,
+	"cel":            true,
+	"compare":        true,
+	"absent":         true,
+	"sorted":         true,
+	"unique":         true,
+	"types":          true,
+	"isJSON":         true,
+	"isXML":          true,
+	"bodyContains":   true,
+	"bodyMatches":    true,
+	"bodySize":       true,
+}
+
+// maxResponseSize returns limit of response body size, zero means no limit
+func maxResponseSize() int64 {
+	if options.MaxResponseSize < 0 {
+		return 0
+	}
+
+	if options.MaxResponseSize == 0 {
+		return DefaultMaxResponseSize
+	}
+
+	return options.MaxResponseSize
+}
+
+// readBody reads response body up to max size, the body is truncated if it exceeds the size
+func readBody(r io.Reader) ([]byte, bool, error) {
+	limit := maxResponseSize()
+	if limit == 0 {
+		data, err := ioutil.ReadAll(r)
+		return data, false, err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if int64(len(data)) > limit {
+		return data[:limit], true, err
+	}
+
+	return data, false, err
+}
+
+func responseTooLarge() error {
+	return fmt.Errorf("response exceeded max size of %d bytes (--max-response-size), body is truncated", maxResponseSize())
+}
+
+// ResponseSizeGuard fails body expectation if the response body is truncated, instead of checking partial content
+type ResponseSizeGuard struct {
+	exp ResponseExpectation
+}
+
+func (e ResponseSizeGuard) check(resp *Response) error {
+	if resp.truncated {
+		return responseTooLarge()
+	}
+
+	return e.exp.check(resp)
+}
+
+func (e ResponseSizeGuard) desc() string {
+	return e.exp.desc()
+}
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCallResponseExceedsMaxSize(t *testing.T) {
+	// given
+	defer func(opts Options) { options = opts }(options)
+	options.MaxResponseSize = 1024
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"items": ["` + strings.Repeat("a", 4096) + `"]}`))
+	}))
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: server.URL},
+		Expect: Expect{StatusCode: 200, BPath: map[string]interface{}{"items.size()": 1.0}},
+	}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if trace.Terminated() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	expected := "response exceeded max size of 1024 bytes (--max-response-size), body is truncated"
+	if trace.ErrorCause == nil || trace.ErrorCause.Error() != expected {
+		t.Errorf("Expected failure '%s', got %v", expected, trace.ErrorCause)
+	}
+
+	if _, ok := trace.ExpDesc["Status code is 200"]; !ok {
+		t.Errorf("Expected status code to be checked, got %v", trace.ExpDesc)
+	}
+
+	if !strings.Contains(trace.ResponseDump, "<truncated, exceeded max size of 1024 bytes>") {
+		t.Errorf("Expected truncated response in dump, got %s", trace.ResponseDump)
+	}
+}
+
+func TestReadBody(t *testing.T) {
+	defer func(opts Options) { options = opts }(options)
+
+	options.MaxResponseSize = 4
+	data, truncated, err := readBody(strings.NewReader("abcd"))
+	if err != nil || truncated || string(data) != "abcd" {
+		t.Errorf("Expected body of max size to be read, got %q, truncated: %v, err: %v", data, truncated, err)
+	}
+
+	data, truncated, err = readBody(strings.NewReader("abcde"))
+	if err != nil || !truncated || string(data) != "abcd" {
+		t.Errorf("Expected body to be truncated, got %q, truncated: %v, err: %v", data, truncated, err)
+	}
+
+	options.MaxResponseSize = -1
+	data, truncated, _ = readBody(strings.NewReader(strings.Repeat("a", 100)))
+	if truncated || len(data) != 100 {
+		t.Errorf("Expected body not to be truncated, got %d bytes", len(data))
+	}
+}
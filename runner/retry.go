@@ -0,0 +1,63 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// CallRetry repeats failed call, e.g. while eventually consistent resource is not ready yet.
+// Number of repeated attempts is reported as 'retries' of JUnit test case.
+type CallRetry struct {
+	// max number of attempts after the first failed one
+	Attempts int `json:"attempts"`
+	// pause before every attempt, e.g. '500ms'
+	Delay string `json:"delay"`
+}
+
+func (r CallRetry) delay() (time.Duration, error) {
+	if r.Delay == "" {
+		return 0, nil
+	}
+
+	delay, err := time.ParseDuration(r.Delay)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid retry delay '%s': %s", r.Delay, err)
+	}
+
+	return delay, nil
+}
+
+// retryable is true if repeating the call could change its outcome, requests that could not be built fail the same way
+func retryable(trace *CallTrace) bool {
+	var buildErr *RequestBuildError
+	return trace.HasError() && !errors.As(trace.ErrorCause, &buildErr)
+}
+
+// callWithRetry executes the call and repeats it while it fails, trace of the last attempt is returned
+func callWithRetry(ctx context.Context, suitePath string, c Call, vars *Vars) *CallTrace {
+	trace := callContext(ctx, suitePath, c, vars)
+	if c.Retry == nil || !retryable(trace) {
+		return trace
+	}
+
+	delay, err := c.Retry.delay()
+	if err != nil {
+		trace.ErrorCause = &RequestBuildError{Err: err}
+		return trace
+	}
+
+	for attempt := 1; attempt <= c.Retry.Attempts && retryable(trace); attempt++ {
+		select {
+		case <-ctx.Done():
+			return trace
+		case <-time.After(delay):
+		}
+
+		trace = callContext(ctx, suitePath, c, vars)
+		trace.Retries = attempt
+	}
+
+	return trace
+}
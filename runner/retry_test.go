@@ -0,0 +1,65 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCallRetry(t *testing.T) {
+	// given
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls[req.URL.Path]++
+		if req.URL.Path == "/broken" || calls[req.URL.Path] < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"retry.suite.json": `[
+			{"name": "eventually", "calls": [{"on": {"method": "GET", "url": "/eventually"}, "retry": {"attempts": 3, "delay": "1ms"}, "expect": {"statusCode": 200}}]},
+			{"name": "broken", "calls": [{"on": {"method": "GET", "url": "/broken"}, "retry": {"attempts": 2}, "expect": {"statusCode": 200}}]}
+		]`,
+	})
+	defer os.RemoveAll(dir)
+
+	out, _ := ioutil.TempDir("", "bozr-junit")
+	defer os.RemoveAll(out)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, Reporter: &JUnitXMLReporter{OutPath: out}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// then
+	if len(results) != 2 || results[0].HasError() || !results[1].HasError() {
+		t.Fatalf("Expected eventually passed and broken failed cases, got %v", results)
+	}
+
+	if results[0].Traces[0].Retries != 2 || results[1].Traces[0].Retries != 2 || calls["/broken"] != 3 {
+		t.Errorf("Expected 2 retries of every call, got %d and %d", results[0].Traces[0].Retries, results[1].Traces[0].Retries)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(out, "retry.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(data), `<testcase name="eventually" classname="retry" time="`) || !strings.Contains(string(data), `retries="2" flaky="true">`) {
+		t.Error("Expected retries and flaky marker of eventually passed case, got:", string(data))
+	}
+}
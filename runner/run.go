@@ -0,0 +1,213 @@
+package runner
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// SuiteExt is extension of test suite files
+	SuiteExt = ".suite.json"
+	// IgnoredSuiteExt is extension of test suite files excluded from the run
+	IgnoredSuiteExt = ".xsuite.json"
+
+	// DefaultStopTimeout is grace period for in-flight requests once the run is stopped
+	DefaultStopTimeout = 10 * time.Second
+)
+
+// Options configure the run
+type Options struct {
+	// directory or file with test suites
+	Path string
+	// prefix of relative request URLs
+	BaseURL string
+	// number of test suites executed in parallel, 1 if not set
+	Workers int
+	// max number of requests per second (in suite), 0 means no limit
+	Throttle int
+	// run is stopped once number of failed test cases reaches the limit, 0 means no limit
+	MaxFailures int
+	// run is stopped on the first transport error (e.g. unresolved host, refused connection), but not on failed expectations
+	BailOnError bool
+	// grace period for in-flight requests once the run is stopped, DefaultStopTimeout if not set
+	StopTimeout time.Duration
+	// headers added to every request as 'Name: value', values could contain variables
+	RequestHeaders []string
+	// 'User-Agent' header of requests unless suite or request sets it, could contain variables. Go default if not set
+	UserAgent string
+	// variables available in every test case, test case args take precedence over them
+	Vars map[string]interface{}
+	// variables exported after the run, see VarsExportReporter. They are not reported as unused
+	ExportVars []string
+	// do not fail on duplicate test case names within a suite and duplicate suite names
+	AllowDuplicateNames bool
+	// suite file executed once before all suites, its failure aborts the run
+	BeforeAll string
+	// suite file executed once after all suites, even if they failed or the run was aborted on failures
+	AfterAll string
+	// environment name to apply expectations declared for it in 'expectEnv' of calls
+	Env string
+	// run only suites affected by the changed files, see ChangedSuites. All suites are run if nil
+	ChangedFiles []string
+	// server certificates are not verified
+	Insecure bool
+	// limit of every request including reading of the response body, 0 means no limit
+	RequestTimeout time.Duration
+	// sends test requests instead of http.DefaultTransport, e.g. to inject faults
+	Transport http.RoundTripper
+	// requests are dumped as curl commands
+	CurlDump bool
+	// max size in bytes of request and response body written into call trace, DefaultMaxBodyLog if not set, negative means no limit
+	MaxBodyLog int
+	// max size in bytes of response body read into memory, DefaultMaxResponseSize if not set, negative means no limit
+	MaxResponseSize int64
+	// source of request body for 'bodyFile': '-', os.Stdin if not set
+	Stdin io.Reader
+	// applied in order to every request, e.g. to sign it
+	Middlewares []RequestMiddleware
+	// receives results of every test suite, nothing is reported if not set
+	Reporter Reporter
+	// destination of debug log, discarded if not set
+	DebugOutput io.Writer
+	// destination of warnings about deprecated syntax of suite files, os.Stderr if not set
+	WarningOutput io.Writer
+	// interval of progress lines written during the run, e.g. for CI watchdogs. 0 means no progress lines
+	Heartbeat time.Duration
+	// destination of progress lines, os.Stderr if not set
+	HeartbeatOutput io.Writer
+}
+
+// options of the current run
+var options = Options{StopTimeout: DefaultStopTimeout}
+
+// runID identifies the run, available as 'ctx:run_id' variable
+var runID = newUUID()
+
+var debug = log.New(ioutil.Discard, "DEBUG: ", log.Ltime|log.Lshortfile)
+
+// Run validates and executes test suites found in opts.Path. Results are reported to opts.Reporter and returned.
+// Once ctx is cancelled test cases that are not started yet are reported as skipped.
+// Options are shared by the package, so Run is not safe for concurrent use.
+func Run(ctx context.Context, opts Options) ([]TestResult, error) {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.StopTimeout == 0 {
+		opts.StopTimeout = DefaultStopTimeout
+	}
+
+	options = opts
+	runID = newUUID()
+	stdinData = &stdinBody{}
+
+	debugOutput := opts.DebugOutput
+	if debugOutput == nil {
+		debugOutput = ioutil.Discard
+	}
+	debug = log.New(debugOutput, "DEBUG: ", log.Ltime|log.Lshortfile)
+
+	if _, err := os.Lstat(opts.Path); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateSuites(opts.Path, SuiteExt, IgnoredSuiteExt); err != nil {
+		return nil, err
+	}
+
+	for _, hook := range []string{opts.BeforeAll, opts.AfterAll} {
+		if err := validateHook(hook); err != nil {
+			return nil, err
+		}
+	}
+
+	warningOutput := opts.WarningOutput
+	if warningOutput == nil {
+		warningOutput = os.Stderr
+	}
+	writeDeprecations(warningOutput, FindDeprecations(opts.Path, SuiteExt, IgnoredSuiteExt))
+
+	source := &DirSuiteFileIterator{RootDir: opts.Path, SuiteExt: SuiteExt, XSuiteExt: IgnoredSuiteExt, Exclude: hookFiles(opts.BeforeAll, opts.AfterAll)}
+	if opts.ChangedFiles != nil {
+		only, err := ChangedSuites(opts.Path, opts.ChangedFiles)
+		if err != nil {
+			return nil, err
+		}
+		source.Only = only
+	}
+
+	runCtx, abort := WithAbort(ctx)
+	abort.MaxFailures = opts.MaxFailures
+	abort.BailOnError = opts.BailOnError
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			abort.Stop(abortInterrupted)
+		case <-done:
+		}
+	}()
+
+	collector := &resultCollector{}
+	reporters := []Reporter{collector}
+	if opts.Reporter != nil {
+		reporters = append([]Reporter{opts.Reporter}, reporters...)
+	}
+
+	reporter := NewMultiReporter(reporters...)
+	reporter.Init()
+
+	for _, result := range runHook(runCtx, opts.BeforeAll, reporter) {
+		if result.HasError() {
+			abort.Stop(abortBeforeAll)
+			break
+		}
+	}
+
+	suitesCtx := runCtx
+	if opts.Heartbeat > 0 {
+		heartbeatOutput := opts.HeartbeatOutput
+		if heartbeatOutput == nil {
+			heartbeatOutput = os.Stderr
+		}
+
+		hb := &heartbeat{total: countCases(*source)}
+		suitesCtx = withHeartbeat(runCtx, hb)
+
+		stopHeartbeat := hb.start(heartbeatOutput, opts.Heartbeat)
+		defer stopHeartbeat()
+	}
+
+	loader := newSuiteLoader(source)
+	RunParallel(suitesCtx, loader, flushLater{reporter}, runSuite, opts.Workers)
+
+	// suites could be aborted on failures, but interrupted run is not continued
+	runHook(ctx, opts.AfterAll, reporter)
+	reporter.Flush()
+
+	return collector.results, nil
+}
+
+// resultCollector keeps results of all test suites of the run
+type resultCollector struct {
+	mu      sync.Mutex
+	results []TestResult
+}
+
+func (c *resultCollector) Init() {}
+
+func (c *resultCollector) Report(results []TestResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.results = append(c.results, results...)
+}
+
+func (c *resultCollector) Flush() {}
@@ -0,0 +1,1062 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"moul.io/http2curl"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+func runSuite(ctx context.Context, suite TestSuite) []TestResult {
+	results := []TestResult{}
+
+	throttle := NewThrottle(options.Throttle, time.Second)
+
+	requestCtx, cancel := WithStopTimeout(ctx, options.StopTimeout)
+	defer cancel()
+
+	baseURL := options.BaseURL
+	if suite.BaseURL != "" {
+		baseURL = joinURL(options.BaseURL, suite.BaseURL)
+	}
+
+	skipMsg := suite.skipReason(options.Env)
+
+	// snapshots of response bodies are shared by test cases of the suite
+	snapshots := make(map[string]interface{})
+
+	for _, testCase := range suite.Cases {
+
+		result := TestResult{
+			Suite:     suite,
+			Case:      testCase,
+			ExecFrame: TimeFrame{Start: time.Now(), End: time.Now()},
+		}
+
+		if ctx.Err() != nil {
+			result.Skipped = true
+			result.SkippedMsg = abortReason(ctx)
+
+			results = append(results, result)
+			reportCaseDone(ctx)
+			continue
+		}
+
+		if skipMsg != "" {
+			result.Skipped = true
+			result.SkippedMsg = skipMsg
+
+			results = append(results, result)
+			reportCaseDone(ctx)
+			continue
+		}
+
+		if testCase.Ignore != nil {
+			result.Skipped = true
+			result.SkippedMsg = *testCase.Ignore
+
+			results = append(results, result)
+			reportCaseDone(ctx)
+			continue
+		}
+
+		vars := NewVars(baseURL)
+		vars.snapshots = snapshots
+		vars.setContext("run_id", runID)
+		vars.setContext("suite", suite.Name)
+		vars.setContext("case", testCase.Name)
+		callArgsErr := vars.AddDefaults(options.Vars)
+		if callArgsErr == nil {
+			callArgsErr = vars.AddAll(testCase.Args)
+		}
+		for i, c := range testCase.Calls {
+
+			throttle.RunOrPause()
+
+			if callArgsErr != nil {
+				result.Traces = append(result.Traces, &CallTrace{ErrorCause: &RequestBuildError{Err: callArgsErr}, Num: i})
+				break
+			}
+
+			err := vars.AddAll(c.Args)
+			if err != nil {
+				result.Traces = append(result.Traces, &CallTrace{ErrorCause: &RequestBuildError{Err: err}, Num: i})
+				break
+			}
+
+			if c.On.Auth == nil {
+				c.On.Auth = suite.Auth
+			}
+
+			c.On.Client = suite.Client
+
+			if suite.UserAgent != "" && !hasHeader(c.On.HeaderList, "User-Agent") {
+				c.On.Headers = withDefaultHeader(c.On.Headers, "User-Agent", suite.UserAgent)
+			}
+
+			trace := callWithRetry(requestCtx, suite.Dir, c, vars)
+			trace.Num = i
+
+			result.Traces = append(result.Traces, trace)
+
+			if trace.HasError() {
+				break
+			}
+		}
+
+		vars.markUsed(options.ExportVars)
+		result.Vars = vars.UserDefined()
+
+		unused := vars.Unused()
+		if len(unused) != 0 {
+			traces := result.Traces
+			lastTrace := traces[len(traces)-1]
+			if lastTrace.ErrorCause == nil {
+				lastTrace.ErrorCause = fmt.Errorf("Declared/remembered arguments are not used: %s", unused)
+			}
+		}
+
+		result.ExecFrame.End = time.Now()
+
+		if testCase.Timing != nil && !result.HasError() && len(result.Traces) > 0 {
+			result.Traces[len(result.Traces)-1].ErrorCause = testCase.Timing.check(result)
+		}
+
+		if result.HasError() {
+			reportFailure(ctx, result)
+		}
+
+		results = append(results, result)
+		reportCaseDone(ctx)
+	}
+
+	return results
+}
+
+// addRequestHeaders sets headers provided with '--request-header' option unless request has them already
+func addRequestHeaders(req *http.Request, headers []string, vars *Vars) {
+	for _, header := range headers {
+		parts := strings.SplitN(header, ":", 2)
+		name := strings.TrimSpace(parts[0])
+
+		if req.Header.Get(name) != "" {
+			continue
+		}
+
+		req.Header.Set(name, vars.ApplyTo(strings.TrimSpace(parts[1])))
+	}
+}
+
+// withDefaultHeader returns copy of headers with the header added unless it is set already
+func withDefaultHeader(headers map[string]string, name, value string) map[string]string {
+	result := map[string]string{name: value}
+	for k, v := range headers {
+		if http.CanonicalHeaderKey(k) == http.CanonicalHeaderKey(name) {
+			delete(result, name)
+		}
+		result[k] = v
+	}
+
+	return result
+}
+
+// hasHeader is true if the list contains the header, names are case insensitive
+func hasHeader(headers []Header, name string) bool {
+	for _, h := range headers {
+		if http.CanonicalHeaderKey(h.Name) == http.CanonicalHeaderKey(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func call(suitePath string, call Call, vars *Vars) *CallTrace {
+	return callContext(context.Background(), suitePath, call, vars)
+}
+
+// callContext executes the call, request is cancelled once ctx is done
+func callContext(ctx context.Context, suitePath string, call Call, vars *Vars) *CallTrace {
+
+	trace := &CallTrace{}
+	execStart := time.Now()
+
+	on := call.On
+
+	bodyTmpl, err := on.BodyContent(suitePath)
+	if err != nil {
+		trace.ErrorCause = &RequestBuildError{Err: err}
+		return trace
+	}
+
+	tmplCtx := NewTemplateContext(vars)
+
+	bodyToSend := tmplCtx.ApplyTo(vars.embedJSON(bodyTmpl))
+	if tmplCtx.HasErrors() {
+		trace.ErrorCause = &RequestBuildError{Err: tmplCtx.Error()}
+		return trace
+	}
+
+	var format BodyFormat
+	if on.Format != "" {
+		if bodyToSend, format, err = encodeBody(on.Format, bodyToSend); err != nil {
+			trace.ErrorCause = &RequestBuildError{Err: err}
+			return trace
+		}
+	}
+
+	if on.Protobuf != nil {
+		if on.Format != "" {
+			trace.ErrorCause = &RequestBuildError{Err: errors.New("Body format and protobuf can't be used together")}
+			return trace
+		}
+		if bodyToSend, format, err = on.Protobuf.encode(suitePath, bodyToSend); err != nil {
+			trace.ErrorCause = &RequestBuildError{Err: err}
+			return trace
+		}
+	}
+
+	req, err := populateRequest(on, bodyToSend, tmplCtx)
+	if err != nil {
+		trace.ErrorCause = &RequestBuildError{Err: err}
+		return trace
+	}
+
+	if (on.Format != "" || on.Protobuf != nil) && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", format.ContentType)
+	}
+	if on.Protobuf != nil && on.Protobuf.GRPCWeb && req.Header.Get("X-Grpc-Web") == "" {
+		req.Header.Set("X-Grpc-Web", "1")
+	}
+	req = req.WithContext(ctx)
+
+	if err = validateGeneratedBody(bodyTmpl, bodyToSend, req.Header.Get("Content-Type")); err != nil {
+		trace.ErrorCause = &RequestBuildError{Err: err}
+		return trace
+	}
+
+	addRequestHeaders(req, options.RequestHeaders, vars)
+	if options.UserAgent != "" {
+		addRequestHeaders(req, []string{"User-Agent: " + options.UserAgent}, vars)
+	}
+
+	if on.Conditional {
+		if err = addConditionalHeaders(req, vars); err != nil {
+			trace.ErrorCause = &RequestBuildError{Err: err}
+			return trace
+		}
+	}
+
+	err = applyMiddlewares(req, options.Middlewares)
+	if err != nil {
+		trace.ErrorCause = &RequestBuildError{Err: err}
+		return trace
+	}
+
+	trace.RequestDump = dumpRequest(req, bodyToSend, options.CurlDump)
+	trace.RequestMethod = req.Method
+	trace.RequestURL = req.URL.String()
+
+	var wsKey string
+	if on.WebSocket != nil {
+		if wsKey, err = prepareWebSocket(req); err != nil {
+			trace.ErrorCause = &RequestBuildError{Err: err}
+			return trace
+		}
+	}
+
+	if on.EventStream != nil && req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	client := httpClient(newClientConfig(on))
+
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotFirstResponseByte: func() { trace.FirstByte = time.Since(execStart) },
+	}))
+
+	resp, err := doWithAuth(client, req, on.Auth.populateWith(tmplCtx))
+
+	if err != nil {
+		debug.Print("Error when sending request", err)
+		trace.ErrorCause = classifyTransportError(err)
+		if ctx.Err() != nil {
+			trace.ErrorCause = fmt.Errorf("Request is cancelled, stop timeout exceeded: %s", err)
+		}
+		return trace
+	}
+
+	defer resp.Body.Close()
+
+	if resp.TLS != nil {
+		trace.PeerCertificates = resp.TLS.PeerCertificates
+	}
+
+	var body []byte
+	var truncated bool
+	if on.WebSocket != nil {
+		body, err = exchange(on.WebSocket, resp, wsKey, tmplCtx, trace)
+		trace.ExecFrame = TimeFrame{Start: execStart, End: time.Now()}
+	} else if on.EventStream != nil {
+		body, err = readEventStream(on.EventStream, resp, trace)
+		trace.ExecFrame = TimeFrame{Start: execStart, End: time.Now()}
+	} else {
+		trace.ExecFrame = TimeFrame{Start: execStart, End: time.Now()}
+		body, truncated, err = readBody(resp.Body)
+	}
+	if err != nil {
+		debug.Print("Error reading response")
+		trace.ErrorCause = err
+		return trace
+	}
+
+	if len(resp.Trailer) > 0 {
+		// trailers are populated once the body is read
+		trace.Trailers = resp.Trailer
+	}
+
+	// truncated body can't be decompressed
+	encoding := ""
+	if !truncated {
+		body, encoding, err = decodeContentEncoding(resp, body)
+		if err != nil {
+			trace.ErrorCause = err
+			return trace
+		}
+	}
+
+	if on.Protobuf != nil && on.Protobuf.GRPCWeb && isGRPCWebResponse(resp) && !truncated {
+		if body, err = unframeGRPCWeb(resp, body); err != nil {
+			trace.ErrorCause = err
+			return trace
+		}
+		trace.Trailers = resp.Trailer
+	}
+
+	testResp, err := newResponse(resp, body)
+	if err != nil {
+		trace.ErrorCause = err
+		return trace
+	}
+	testResp.contentEncoding = encoding
+	testResp.truncated = truncated
+	if on.Protobuf != nil {
+		testResp.format = &format
+	}
+
+	if call.Paginate != nil {
+		testResp, err = call.Paginate.follow(client, req, testResp, suitePath, vars)
+		trace.ExecFrame.End = time.Now()
+		if _, ok := err.(pageFailure); ok {
+			trace.addFail(err)
+			return trace
+		}
+		if err != nil {
+			trace.ErrorCause = err
+			return trace
+		}
+	}
+
+	if call.RateLimit != nil {
+		var limitedAfter int
+		testResp, limitedAfter, err = call.RateLimit.probe(client, req, bodyToSend, testResp)
+		trace.ExecFrame.End = time.Now()
+		if _, ok := err.(rateLimitFailure); ok {
+			trace.addFail(err)
+			return trace
+		}
+		if err != nil {
+			trace.ErrorCause = err
+			return trace
+		}
+		trace.addExp(call.RateLimit.desc(limitedAfter))
+	}
+
+	trace.ResponseDump = testResp.ToString()
+	testResp.duration = trace.ExecFrame.Duration()
+
+	if err = call.Expect.populateWith(vars); err != nil {
+		trace.ErrorCause = err
+		return trace
+	}
+
+	exps, err := expectations(call.Expect, suitePath)
+	if err != nil {
+		trace.ErrorCause = err
+		return trace
+	}
+
+	// values are remembered from the whole response
+	fullResp := testResp
+	if call.Extract != "" {
+		if testResp, err = extractBody(testResp, call.Extract); err != nil {
+			trace.addFail(err)
+			return trace
+		}
+	}
+
+	var anyOf []ResponseExpectation
+	for _, exp := range exps {
+		if skipped, ok := exp.(SkippedExpectation); ok {
+			trace.addSkippedExp(skipped.desc(), skipped.reason)
+			continue
+		}
+
+		if warning, ok := exp.(WarningExpectation); ok {
+			if checkErr := warning.check(&testResp); checkErr != nil {
+				trace.addWarning(warning.desc(), checkErr)
+			} else {
+				trace.addExp(warning.desc())
+			}
+			continue
+		}
+
+		if call.MatchMode == matchModeAny {
+			anyOf = append(anyOf, exp)
+			continue
+		}
+
+		checkErr := exp.check(&testResp)
+
+		if checkErr != nil {
+			trace.addFail(checkErr)
+			return trace
+		}
+
+		trace.addExp(exp.desc())
+	}
+
+	if len(anyOf) > 0 && !checkAnyOf(anyOf, &testResp, trace) {
+		return trace
+	}
+
+	if len(call.Expect.Increasing) > 0 {
+		if err = recordSequence(&testResp, call.Expect.Increasing, trace); err != nil {
+			trace.addFail(err)
+			return trace
+		}
+		trace.addExp(fmt.Sprintf("Values increasing across runs: %v", call.Expect.Increasing))
+	}
+
+	if fullResp.truncated && (len(call.Remember.BPath) > 0 || call.Remember.Snapshot != "") {
+		trace.ErrorCause = responseTooLarge()
+		return trace
+	}
+
+	err = rememberBody(&fullResp, call.Remember.BPath, vars)
+	debug.Print(vars)
+	if err != nil {
+		debug.Print("Error remember")
+		trace.ErrorCause = err
+		return trace
+	}
+
+	if call.Remember.Snapshot != "" {
+		// snapshot is the body checked by expectations, so the same call compares like with like
+		body, err := testResp.Body()
+		if err != nil {
+			trace.ErrorCause = fmt.Errorf("Can't remember snapshot '%s' of response body: %s", call.Remember.Snapshot, err)
+			return trace
+		}
+		vars.snapshots[call.Remember.Snapshot] = body
+	}
+
+	rememberHeaders(fullResp.http.Header, call.Remember.Headers, vars)
+	if err = rememberTiming(trace, call.Remember.Timing, vars); err != nil {
+		trace.ErrorCause = err
+		return trace
+	}
+	rememberValidators(fullResp.http.Header, vars)
+
+	return trace
+}
+
+// exchange sends and receives WebSocket messages, the exchange is recorded into the trace
+func exchange(ws *WebSocketOn, resp *http.Response, key string, tmplCtx *TemplateContext, trace *CallTrace) ([]byte, error) {
+	timeout, err := ws.timeout()
+	if err != nil {
+		return nil, err
+	}
+
+	message := tmplCtx.ApplyTo(ws.Send)
+	if tmplCtx.HasErrors() {
+		return nil, tmplCtx.Error()
+	}
+
+	if message != "" {
+		trace.RequestDump += "\n\nWebSocket >> " + logBody([]byte(message), "")
+	}
+
+	payload, err := exchangeWebSocket(resp, key, message, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	webSocketResponse(resp, payload)
+	return payload, nil
+}
+
+func populateRequest(on On, body string, tmplCtx *TemplateContext) (*http.Request, error) {
+
+	urlStr, err := urlPrefix(tmplCtx.vars.baseURL(), tmplCtx.ApplyTo(on.URL))
+	if err != nil {
+		return nil, errors.New("Cannot create request. Invalid url: " + on.URL)
+	}
+
+	dat := []byte(body)
+
+	req, err := http.NewRequest(on.Method, urlStr, bytes.NewBuffer(dat))
+	if err != nil {
+		return nil, err
+	}
+
+	for key, valueTmpl := range on.Headers {
+		req.Header.Add(key, tmplCtx.ApplyTo(valueTmpl))
+	}
+
+	for _, h := range on.HeaderList {
+		req.Header.Add(h.Name, tmplCtx.ApplyTo(h.Value))
+	}
+
+	q := req.URL.Query()
+	for key, valueTmpl := range on.Params {
+		q.Add(key, tmplCtx.ApplyTo(valueTmpl))
+	}
+
+	req.URL.RawQuery = q.Encode()
+
+	if tmplCtx.HasErrors() {
+		return nil, tmplCtx.Error()
+	}
+
+	return req, nil
+}
+
+func isAbsURL(p string) bool {
+	return strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") || isWebSocketURL(p)
+}
+
+// urlPrefix joins request URL with the base one, absolute request URL bypasses the base
+func urlPrefix(base string, p string) (string, error) {
+	if isAbsURL(p) {
+		return p, nil
+	}
+
+	return concatURL(base, p)
+}
+
+// joinURL joins two parts of URL with exactly one slash between them, absolute second part replaces the first one
+func joinURL(base string, p string) string {
+	if isAbsURL(p) || base == "" {
+		return p
+	}
+
+	if p == "" {
+		return base
+	}
+
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(p, "/")
+}
+
+func concatURL(base string, p string) (string, error) {
+	baseURL, err := url.ParseRequestURI(base)
+	if err != nil {
+		return "", err
+	}
+	return joinURL(baseURL.Scheme+"://"+baseURL.Host+baseURL.Path, p), nil
+}
+
+// checkAnyOf passes if at least one of expectations is met, failure lists all of them
+func checkAnyOf(exps []ResponseExpectation, resp *Response, trace *CallTrace) bool {
+	var failures []string
+	for _, exp := range exps {
+		if err := exp.check(resp); err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+
+		trace.addExp(fmt.Sprintf("(any of %d) %s", len(exps), exp.desc()))
+	}
+
+	if len(failures) == len(exps) {
+		trace.addFail(fmt.Errorf("None of %d expectations is met (matchMode: any):\n%s", len(exps), strings.Join(failures, "\n")))
+		return false
+	}
+
+	return true
+}
+
+func expectations(expect Expect, suitePath string) ([]ResponseExpectation, error) {
+	var exps []ResponseExpectation
+
+	declared := make(map[string]bool)
+
+	// add registers expectation declared under the key of 'expect' section
+	// with its severity, unless it is temporarily disabled
+	add := func(key string, exp ResponseExpectation) {
+		declared[key] = true
+
+		if bodyContentKeys[key] {
+			exp = ResponseSizeGuard{exp: exp}
+		}
+
+		if expect.Severity[key] == severityWarning {
+			exp = WarningExpectation{exp: exp}
+		}
+
+		if reason, ok := expect.Disabled[key]; ok {
+			exp = SkippedExpectation{exp: exp, reason: reason}
+		}
+
+		exps = append(exps, exp)
+	}
+
+	if expect.StatusCode != 0 {
+		add("statusCode", StatusCodeExpectation{statusCode: expect.StatusCode})
+	}
+
+	if expect.StatusText != "" {
+		add("statusText", StatusTextExpectation{statusText: expect.StatusText})
+	}
+
+	if expect.BodySchemaURI != "" {
+		var schema []byte
+		if _, disabled := expect.Disabled["bodySchemaURI"]; !disabled {
+			var err error
+			if schema, err = expect.loadSchemaFromURI(); err != nil {
+				return nil, err
+			}
+		}
+
+		add("bodySchemaURI", BodySchemaExpectation{
+			schema:      schema,
+			displayName: expect.BodySchemaURI,
+		})
+	}
+
+	if expect.BodySchemaFile != "" {
+		var schema []byte
+		if _, disabled := expect.Disabled["bodySchemaFile"]; !disabled {
+			var err error
+			if schema, err = expect.loadSchemaFromFile(suitePath); err != nil {
+				return nil, err
+			}
+		}
+
+		add("bodySchemaFile", BodySchemaExpectation{
+			schema:      schema,
+			displayName: expect.BodySchemaFile,
+		})
+	}
+
+	if expect.OpenAPI != nil {
+		if expect.OpenAPI.OperationID == "" && expect.OpenAPI.Path == "" {
+			return nil, errors.New("OpenAPI expectation requires operationId or path of the operation")
+		}
+
+		exp := OpenAPIExpectation{OpenAPIAssert: *expect.OpenAPI}
+		if _, disabled := expect.Disabled["openapi"]; !disabled {
+			var err error
+			if exp.spec, err = loadOpenAPISpec(suitePath, expect.OpenAPI.SpecFile); err != nil {
+				return nil, err
+			}
+		}
+
+		add("openapi", exp)
+	}
+
+	if expect.BodySchemaRaw != nil {
+		add("bodySchema", BodySchemaExpectation{
+			schema:      expect.BodySchemaRaw,
+			displayName: "",
+		})
+	}
+
+	if len(expect.BodyPath()) > 0 {
+		add("bodyPath", BodyPathExpectation{pathExpectations: expect.BodyPath(), coerceNumbers: expect.CoerceNumbers})
+	}
+
+	for _, quantified := range []struct {
+		key        string
+		quantifier string
+		predicates map[string]map[string]interface{}
+	}{
+		{"anyMatch", matchAny, expect.AnyMatch},
+		{"allMatch", matchAll, expect.AllMatch},
+		{"noneMatch", matchNone, expect.NoneMatch},
+	} {
+		paths := make([]string, 0, len(quantified.predicates))
+		for path := range quantified.predicates {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		for _, path := range paths {
+			add(quantified.key, ArrayMatchExpectation{Path: path, Quantifier: quantified.quantifier, Predicate: quantified.predicates[path]})
+		}
+	}
+
+	if expect.Body != nil {
+		add("body", BodyExpectation{ExpectedBody: expect.Body, Strict: false, CoerceNumbers: expect.CoerceNumbers})
+	}
+
+	if expect.ExactBody != nil {
+		add("exactBody", BodyExpectation{ExpectedBody: expect.ExactBody, Strict: true, CoerceNumbers: expect.CoerceNumbers})
+	}
+
+	if len(expect.OneOf) > 0 {
+		add("oneOf", BodyOneOfExpectation{ExpectedBodies: expect.OneOf, CoerceNumbers: expect.CoerceNumbers})
+	}
+
+	if expect.ConsistentWith != nil {
+		add("consistentWith", ConsistencyExpectation{*expect.ConsistentWith})
+	}
+
+	for _, expr := range expect.CEL {
+		program, err := compileCEL(expr)
+		if err != nil {
+			return nil, err
+		}
+		add("cel", CELExpectation{Expression: expr, program: program})
+	}
+
+	for _, comparison := range expect.Compare {
+		if comparison.Op == "" {
+			comparison.Op = "=="
+		}
+		if _, ok := comparisonOps[comparison.Op]; !ok {
+			return nil, fmt.Errorf("Invalid compare operator '%s', expected one of ==, !=, <, <=, >, >=", comparison.Op)
+		}
+		add("compare", FieldComparisonExpectation{comparison})
+	}
+
+	if expect.Certificate != nil {
+		exp := CertificateExpectation{CertificateAssert: *expect.Certificate}
+		if expect.Certificate.ExpiresInMoreThan != "" {
+			validity, err := parseValidity(expect.Certificate.ExpiresInMoreThan)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid certificate expiresInMoreThan: %s", err)
+			}
+			exp.MinValidity = validity
+		}
+		add("certificate", exp)
+	}
+
+	if expect.Location != nil {
+		exp := LocationExpectation{LocationAssert: *expect.Location}
+		if expect.Location.Matches != "" {
+			pattern, err := regexp.Compile(expect.Location.Matches)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid location pattern: %s", err)
+			}
+			exp.Pattern = pattern
+		}
+		add("location", exp)
+	}
+
+	if len(expect.Absent) > 0 {
+		add("absent", AbsentExpectation{paths: expect.Absent, nullAsAbsent: expect.NullAsAbsent})
+	}
+
+	sortedPaths := make([]string, 0, len(expect.Sorted))
+	for path := range expect.Sorted {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	for _, path := range sortedPaths {
+		order := expect.Sorted[path]
+		add("sorted", SortedExpectation{Path: path, By: order.By, Descending: order.Order == "desc"})
+	}
+
+	uniquePaths := make([]string, 0, len(expect.Unique))
+	for path := range expect.Unique {
+		uniquePaths = append(uniquePaths, path)
+	}
+	sort.Strings(uniquePaths)
+
+	for _, path := range uniquePaths {
+		add("unique", UniqueExpectation{Path: path, By: expect.Unique[path]})
+	}
+
+	typePaths := make([]string, 0, len(expect.Types))
+	for path := range expect.Types {
+		typePaths = append(typePaths, path)
+	}
+	sort.Strings(typePaths)
+
+	for _, path := range typePaths {
+		add("types", JSONTypeExpectation{Path: path, Type: expect.Types[path]})
+	}
+
+	for _, logical := range []struct {
+		op       string
+		sections []Expect
+	}{
+		{logicalAnd, expect.And},
+		{logicalOr, expect.Or},
+	} {
+		if len(logical.sections) == 0 {
+			continue
+		}
+
+		nodes, err := logicalNodes(logical.op, logical.sections, suitePath)
+		if err != nil {
+			return nil, err
+		}
+		add(logical.op, LogicalExpectation{Op: logical.op, Nodes: nodes})
+	}
+
+	if expect.Not != nil {
+		nodes, err := logicalNodes(logicalNot, []Expect{*expect.Not}, suitePath)
+		if err != nil {
+			return nil, err
+		}
+		add(logicalNot, LogicalExpectation{Op: logicalNot, Nodes: nodes})
+	}
+
+	if expect.IsJSON {
+		add("isJSON", ValidJSONExpectation{})
+	}
+
+	if expect.IsXML {
+		add("isXML", ValidXMLExpectation{})
+	}
+
+	if expect.BodyContains != "" {
+		add("bodyContains", BodyContainsExpectation{Text: expect.BodyContains})
+	}
+
+	if expect.BodyMatches != "" {
+		pattern, err := regexp.Compile(expect.BodyMatches)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid bodyMatches pattern: %s", err)
+		}
+		add("bodyMatches", BodyMatchesExpectation{Pattern: pattern})
+	}
+
+	if len(expect.Headers) > 0 {
+		for k, v := range expect.Headers {
+			add("headers", HeaderExpectation{Name: k, Value: v})
+		}
+	}
+
+	for _, comparison := range expect.HeaderCompare {
+		if err := comparison.validate(); err != nil {
+			return nil, err
+		}
+		add("headerCompare", HeaderComparisonExpectation{comparison})
+	}
+
+	for k, v := range expect.HeaderValues {
+		add("headerValues", HeaderValuesExpectation{Name: k, Values: v})
+	}
+
+	for k, v := range expect.HeaderCount {
+		add("headerCount", HeaderCountExpectation{Name: k, Count: v})
+	}
+
+	if len(expect.HeadersAbsent) > 0 {
+		add("headersAbsent", HeadersAbsentExpectation{Names: expect.HeadersAbsent})
+	}
+
+	for k, v := range expect.Trailers {
+		add("trailers", TrailerExpectation{Name: k, Value: v})
+	}
+
+	if expect.SecurityHeaders {
+		for _, exp := range securityHeadersExpectations() {
+			add("securityHeaders", exp)
+		}
+	}
+
+	if expect.ContentEncoding != "" {
+		add("contentEncoding", ContentEncodingExpectation{Value: expect.ContentEncoding})
+	}
+
+	if expect.ContentType != "" {
+		add("contentType", ContentTypeExpectation{Value: expect.ContentType})
+	}
+
+	if expect.Charset != "" {
+		add("charset", ContentTypeExpectation{Params: map[string]string{"charset": expect.Charset}})
+	}
+
+	if expect.ContentNegotiated {
+		add("contentNegotiated", NegotiationExpectation{})
+	}
+
+	for name, cookie := range expect.Cookies {
+		add("cookies", CookieExpectation{Name: name, Expected: cookie})
+	}
+
+	if expect.NotModified {
+		add("notModified", NotModifiedExpectation{})
+	}
+
+	if expect.BodySize != nil {
+		add("bodySize", BodySizeExpectation{Bytes: expect.BodySize.Bytes, MatchContentLength: expect.BodySize.MatchContentLength})
+	}
+
+	// and so on
+
+	for key := range expect.Disabled {
+		if !declared[key] {
+			return nil, fmt.Errorf("Disabled expectation '%s' is not declared in 'expect' section", key)
+		}
+	}
+
+	for key := range expect.Severity {
+		if !declared[key] {
+			return nil, fmt.Errorf("Severity of expectation '%s' is set, but it is not declared in 'expect' section", key)
+		}
+	}
+
+	return exps, nil
+}
+
+func rememberBody(resp *Response, remember map[string]string, vars *Vars) (err error) {
+
+	for varName, pathLine := range remember {
+		body, err := resp.Body()
+		if err != nil {
+			debug.Print("Can't parse response body to Map for [remember]")
+			return err
+		}
+
+		if rememberVar, err := GetByPath(body, pathLine); err == nil {
+			vars.Add(varName, rememberVar)
+		} else {
+			debug.Print(err)
+			return fmt.Errorf("Remembered value not found, path: %v", pathLine)
+		}
+	}
+
+	return err
+}
+
+func rememberHeaders(header http.Header, remember map[string]string, vars *Vars) {
+	for valueName, headerName := range remember {
+		value := header.Get(headerName)
+		if value == "" {
+			continue
+		}
+
+		vars.Add(valueName, value)
+	}
+}
+
+// timings of the call which could be remembered
+const (
+	timingDuration = "duration"
+	timingTTFB     = "ttfb"
+)
+
+// rememberTiming keeps duration of the call or time to first byte of the response in milliseconds
+func rememberTiming(trace *CallTrace, remember map[string]string, vars *Vars) error {
+	for varName, timing := range remember {
+		var value time.Duration
+		switch timing {
+		case timingDuration:
+			value = trace.ExecFrame.Duration()
+		case timingTTFB:
+			value = trace.FirstByte
+		default:
+			return fmt.Errorf("Unknown timing '%s' of remembered variable '%s', expected '%s' or '%s'", timing, varName, timingDuration, timingTTFB)
+		}
+
+		vars.Add(varName, float64(value.Milliseconds()))
+	}
+
+	return nil
+}
+
+// validateGeneratedBody checks JSON body produced by template actions (e.g. loops) is well-formed.
+// Static bodies are sent as is, so malformed JSON could be used in negative tests.
+func validateGeneratedBody(bodyTmpl string, body string, contentType string) error {
+	if !strings.Contains(bodyTmpl, "{{") {
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !isJSONMediaType(mediaType) {
+		return nil
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return fmt.Errorf("Generated request body is not a valid JSON: %s\n%s", err, body)
+	}
+
+	return nil
+}
+
+// rememberValidators keeps cache validators of the response for the next conditional request
+func rememberValidators(header http.Header, vars *Vars) {
+	if etag := header.Get("ETag"); etag != "" {
+		vars.setContext("etag", etag)
+	}
+
+	if lastModified := header.Get("Last-Modified"); lastModified != "" {
+		vars.setContext("last_modified", lastModified)
+	}
+}
+
+func addConditionalHeaders(req *http.Request, vars *Vars) error {
+	etag, hasETag := vars.items[ctxVarPrefix+varPrefixSeparator+"etag"]
+	lastModified, hasLastModified := vars.items[ctxVarPrefix+varPrefixSeparator+"last_modified"]
+
+	if !hasETag && !hasLastModified {
+		return errors.New("Cannot send conditional request. No 'ETag' or 'Last-Modified' received in previous calls")
+	}
+
+	if hasETag {
+		req.Header.Set("If-None-Match", toString(etag))
+	}
+
+	if hasLastModified {
+		req.Header.Set("If-Modified-Since", toString(lastModified))
+	}
+
+	return nil
+}
+
+func dumpRequest(req *http.Request, body string, dumpAsCurl bool) string {
+	if dumpAsCurl {
+		command, _ := http2curl.GetCurlCommand(req)
+		return command.String()
+	}
+	buf := bytes.NewBufferString("")
+
+	buf.WriteString(fmt.Sprintf("%s %s %s\n", req.Method, req.URL.String(), req.Proto))
+
+	for k, v := range req.Header {
+		buf.WriteString(fmt.Sprintf("%s: %s\n", k, strings.Join(v, " ")))
+	}
+
+	if len(body) > 0 {
+		buf.WriteString("\n")
+		buf.WriteString(logBody([]byte(body), req.Header.Get("Content-Type")))
+	}
+
+	return buf.String()
+}
+
+func debugf(format string, v ...interface{}) {
+	if debug == nil {
+		// fmt.Printf(format, v...)
+		return
+	}
+
+	debug.Printf(format, v...)
+}
@@ -0,0 +1,598 @@
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRememberBodyLazy(t *testing.T) {
+	resp := Response{
+		http: &http.Response{
+			Header: map[string][]string{"Content-Type": {"application/json"}},
+		},
+		body: []byte(`# invalid body so it fails if parsed`),
+	}
+
+	err := rememberBody(&resp, map[string]string{}, NewVars(""))
+
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRunSuite_InvalidUrlAndUnused_OnlyInvalidUrl(t *testing.T) {
+	suite := TestSuite{
+		Cases: []TestCase{
+			{
+				Calls: []Call{
+					{
+						Args: map[string]interface{}{"a": 1},
+						On: On{
+							URL: "my-invalid-host",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	results := runSuite(context.Background(), suite)
+
+	err := results[0].Traces[0].ErrorCause
+	if err == nil || !strings.Contains(err.Error(), "Invalid url") {
+		t.Error("Expected error not thrown", err)
+	}
+}
+
+func TestConcatURL(t *testing.T) {
+
+	t.Run("open base and closed path", func(t *testing.T) {
+		base := "http://example.com"
+		path := "/api/v1/example"
+		url, _ := concatURL(base, path)
+		if url != "http://example.com/api/v1/example" {
+			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
+		}
+	})
+
+	t.Run("closed base and closed path", func(t *testing.T) {
+		base := "http://example.com/api/"
+		path := "/v1/example"
+		url, _ := concatURL(base, path)
+		if url != "http://example.com/api/v1/example" {
+			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
+		}
+	})
+
+	t.Run("closed base and open path", func(t *testing.T) {
+		base := "http://example.com/api/"
+		path := "v1/example"
+		url, _ := concatURL(base, path)
+		if url != "http://example.com/api/v1/example" {
+			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
+		}
+	})
+
+	t.Run("open base and open path", func(t *testing.T) {
+		base := "http://example.com/api"
+		path := "v1/example"
+		url, _ := concatURL(base, path)
+		if url != "http://example.com/api/v1/example" {
+			t.Error("Incorrect url. Expected: http://example.com/api/v1/example. Actual: " + url)
+		}
+	})
+
+	t.Run("path with trailing slash and query", func(t *testing.T) {
+		url, _ := concatURL("http://example.com/api//", "//users/?active=true")
+		if url != "http://example.com/api/users/?active=true" {
+			t.Error("Incorrect url. Expected: http://example.com/api/users/?active=true. Actual: " + url)
+		}
+	})
+
+}
+
+func TestCallContentNegotiation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if acceptsMediaType(req.Header.Get("Accept"), "application/xml") {
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<user><name>John</name></user>`))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user": {"name": "John"}}`))
+	}))
+	defer server.Close()
+
+	newCall := func(accept string) Call {
+		return Call{
+			On: On{Method: "GET", URL: server.URL, Headers: map[string]string{"Accept": accept}},
+			Expect: Expect{
+				ContentNegotiated: true,
+				BPath:             map[string]interface{}{"user.name": "John"},
+			},
+		}
+	}
+
+	t.Run("xml", func(t *testing.T) {
+		trace := call("", newCall("application/xml"), NewVars(""))
+		if trace.HasError() {
+			t.Error(trace.ErrorCause)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		trace := call("", newCall("application/json;q=0.9, text/html;q=0"), NewVars(""))
+		if trace.HasError() {
+			t.Error(trace.ErrorCause)
+		}
+	})
+
+	t.Run("not negotiated", func(t *testing.T) {
+		trace := call("", newCall("text/csv"), NewVars(""))
+		if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "Content type is not negotiated") {
+			t.Error("Expected negotiation failure, got:", trace.ErrorCause)
+		}
+	})
+}
+
+func TestCallDisabledExpectation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	newCall := func(disabled map[string]string) Call {
+		return Call{
+			On:     On{Method: "GET", URL: server.URL},
+			Expect: Expect{StatusCode: 200, Disabled: disabled},
+		}
+	}
+
+	t.Run("skipped", func(t *testing.T) {
+		trace := call("", newCall(map[string]string{"statusCode": "BUG-1"}), NewVars(""))
+		if trace.HasError() {
+			t.Error(trace.ErrorCause)
+		}
+
+		if len(trace.ExpSkipped) != 1 {
+			t.Fatalf("Expected one skipped expectation, got: %v", trace.ExpSkipped)
+		}
+
+		for _, reason := range trace.ExpSkipped {
+			if reason != "BUG-1" {
+				t.Errorf("Unexpected skip reason: %s", reason)
+			}
+		}
+	})
+
+	t.Run("not declared", func(t *testing.T) {
+		trace := call("", newCall(map[string]string{"bodyPath": "BUG-2"}), NewVars(""))
+		if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "'bodyPath' is not declared") {
+			t.Error("Expected declaration error, got:", trace.ErrorCause)
+		}
+	})
+}
+
+func TestRunSuiteExpectRememberedValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if req.Method == "POST" {
+			w.Write([]byte(`{"id": 42, "code": "A-42"}`))
+			return
+		}
+
+		w.Write([]byte(`{"item": {"id": 42, "code": "A-42", "tags": ["A-42"]}}`))
+	}))
+	defer server.Close()
+
+	suite := TestSuite{
+		Cases: []TestCase{
+			{
+				Calls: []Call{
+					{
+						On:       On{Method: "POST", URL: server.URL},
+						Remember: Remember{BPath: map[string]string{"createdId": "id", "createdCode": "code"}},
+					},
+					{
+						On: On{Method: "GET", URL: server.URL},
+						Expect: Expect{
+							BPath: map[string]interface{}{
+								"item.id":   "{createdId}",
+								"item.code": "{createdCode}",
+								"item.tags": []interface{}{"{createdCode}"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	results := runSuite(context.Background(), suite)
+
+	if results[0].HasError() {
+		t.Error(results[0].Error())
+	}
+}
+
+func TestRunSuiteConditionalRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	newCase := func(calls ...Call) TestSuite {
+		return TestSuite{Cases: []TestCase{{Calls: calls}}}
+	}
+
+	t.Run("not modified", func(t *testing.T) {
+		results := runSuite(context.Background(), newCase(
+			Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{StatusCode: 200}},
+			Call{On: On{Method: "GET", URL: server.URL, Conditional: true}, Expect: Expect{NotModified: true}},
+		))
+
+		if results[0].HasError() {
+			t.Error(results[0].Error())
+		}
+	})
+
+	t.Run("no validators", func(t *testing.T) {
+		results := runSuite(context.Background(), newCase(
+			Call{On: On{Method: "GET", URL: server.URL, Conditional: true}, Expect: Expect{NotModified: true}},
+		))
+
+		if !results[0].HasError() || !strings.Contains(results[0].Error(), "No 'ETag' or 'Last-Modified'") {
+			t.Error("Expected missing validators error, got:", results[0].Error())
+		}
+	})
+}
+
+func TestRunSuiteWarningSeverity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, abort := WithAbort(context.Background())
+
+	suite := TestSuite{
+		Cases: []TestCase{
+			{
+				Calls: []Call{
+					{
+						On: On{Method: "GET", URL: server.URL},
+						Expect: Expect{
+							StatusCode: 200,
+							Headers:    map[string]string{"Deprecation": "true"},
+							Severity:   map[string]string{"headers": "warning"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	results := runSuite(ctx, suite)
+
+	if results[0].HasError() {
+		t.Error(results[0].Error())
+	}
+
+	if results[0].warnings() != 1 {
+		t.Errorf("Expected failed warning, got: %v", results[0].Traces[0].ExpWarnings)
+	}
+
+	if abort.Failures() != 0 {
+		t.Errorf("Failed warning is counted as failure")
+	}
+}
+
+func TestURLPrefix(t *testing.T) {
+	cases := []struct {
+		base     string
+		path     string
+		expected string
+	}{
+		{"http://example.com/api", "/users/1", "http://example.com/api/users/1"},
+		{"http://example.com/api/", "users/1", "http://example.com/api/users/1"},
+		{"http://example.com/api", "https://other.com/users/1", "https://other.com/users/1"},
+		{"", "http://other.com/users/1", "http://other.com/users/1"},
+	}
+
+	for _, c := range cases {
+		url, err := urlPrefix(c.base, c.path)
+		if err != nil || url != c.expected {
+			t.Errorf("urlPrefix(%s, %s) expected: %s, actual: %s, %v", c.base, c.path, c.expected, url, err)
+		}
+	}
+
+	if _, err := urlPrefix("", "/users/1"); err == nil {
+		t.Error("Expected error for relative url without base")
+	}
+}
+
+func TestRunSuiteBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/api/users/1" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	defer func(host string) { options.BaseURL = host }(options.BaseURL)
+
+	newSuite := func(baseURL string) TestSuite {
+		return TestSuite{
+			BaseURL: baseURL,
+			Cases: []TestCase{{Calls: []Call{{
+				On:     On{Method: "GET", URL: "/users/1"},
+				Expect: Expect{StatusCode: 200},
+			}}}},
+		}
+	}
+
+	t.Run("relative suite base joined with run base", func(t *testing.T) {
+		options.BaseURL = server.URL + "/"
+
+		results := runSuite(context.Background(), newSuite("/api/"))
+		if results[0].HasError() {
+			t.Error(results[0].Error())
+		}
+	})
+
+	t.Run("absolute suite base overrides run base", func(t *testing.T) {
+		options.BaseURL = "http://example.com"
+
+		results := runSuite(context.Background(), newSuite(server.URL+"/api"))
+		if results[0].HasError() {
+			t.Error(results[0].Error())
+		}
+	})
+}
+
+func TestRequestHeadersWithRunID(t *testing.T) {
+	var mu sync.Mutex
+	var runIDs, cases []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		runIDs = append(runIDs, req.Header.Get("X-Test-Run-Id"))
+		cases = append(cases, req.Header.Get("X-Test-Case"))
+	}))
+	defer server.Close()
+
+	defer func(opts Options, id string) {
+		options, runID = opts, id
+	}(options, runID)
+
+	options.BaseURL = server.URL
+	options.RequestHeaders = []string{"X-Test-Run-Id: {ctx:run_id}", "X-Test-Case: {ctx:suite}/{ctx:case}"}
+
+	newCase := func(name string) TestCase {
+		return TestCase{Name: name, Calls: []Call{
+			{On: On{Method: "GET", URL: "/"}, Expect: Expect{StatusCode: 200}},
+			{On: On{Method: "GET", URL: "/"}, Expect: Expect{StatusCode: 200}},
+		}}
+	}
+	suite := TestSuite{Name: "users", Cases: []TestCase{newCase("create"), newCase("delete")}}
+
+	// first run
+	for _, result := range runSuite(context.Background(), suite) {
+		if result.HasError() {
+			t.Fatal(result.Error())
+		}
+	}
+
+	if len(runIDs) != 4 || runIDs[0] == "" {
+		t.Fatalf("Expected run id on every request, got %v", runIDs)
+	}
+	for _, id := range runIDs {
+		if id != runIDs[0] {
+			t.Errorf("Expected the same run id on all requests, got %v", runIDs)
+		}
+	}
+	if cases[0] != "users/create" || cases[3] != "users/delete" {
+		t.Errorf("Unexpected case headers %v", cases)
+	}
+
+	// second run
+	firstRunID := runIDs[0]
+	runIDs = nil
+	runID = newUUID()
+
+	runSuite(context.Background(), suite)
+
+	if len(runIDs) == 0 || runIDs[0] == firstRunID {
+		t.Errorf("Expected run id to differ between runs, got %s and %v", firstRunID, runIDs)
+	}
+}
+
+func TestRunSuiteUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	var agents []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		agents = append(agents, strings.Join(req.Header["User-Agent"], ","))
+	}))
+	defer server.Close()
+
+	defer func(opts Options, id string) {
+		options, runID = opts, id
+	}(options, runID)
+
+	options.BaseURL = server.URL
+	options.UserAgent = "bozr/1.0 ({ctx:run_id})"
+	runID = "42"
+
+	calls := []Call{
+		{On: On{Method: "GET", URL: "/"}, Expect: Expect{StatusCode: 200}},
+		{On: On{Method: "GET", URL: "/", Headers: map[string]string{"user-agent": "mobile-app/2.1"}}, Expect: Expect{StatusCode: 200}},
+		{On: On{Method: "GET", URL: "/", HeaderList: []Header{{Name: "user-agent", Value: "cli/3.0"}}}, Expect: Expect{StatusCode: 200}},
+	}
+
+	// default and overridden by request
+	runSuite(context.Background(), TestSuite{Name: "users", Cases: []TestCase{{Name: "get", Calls: calls}}})
+
+	// overridden by suite
+	runSuite(context.Background(), TestSuite{Name: "users", UserAgent: "partner-sync", Cases: []TestCase{{Name: "get", Calls: calls}}})
+
+	expected := []string{"bozr/1.0 (42)", "mobile-app/2.1", "cli/3.0", "partner-sync", "mobile-app/2.1", "cli/3.0"}
+	if strings.Join(agents, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected User-Agent headers %v, got %v", expected, agents)
+	}
+}
+
+func TestCallDuplicateHeaders(t *testing.T) {
+	var links []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		links = req.Header["Link"]
+	}))
+	defer server.Close()
+
+	var on On
+	err := json.Unmarshal([]byte(`{
+		"method": "GET",
+		"url": "`+server.URL+`",
+		"headers": [
+			{"name": "Link", "value": "<{page}?page=2>; rel=\"next\""},
+			{"name": "Link", "value": "</items?page=1>; rel=\"first\""}
+		]
+	}`), &on)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := NewVars("")
+	vars.Add("page", "/items")
+
+	trace := call("", Call{On: on, Expect: Expect{StatusCode: 200}}, vars)
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	expected := []string{`</items?page=2>; rel="next"`, `</items?page=1>; rel="first"`}
+	if len(links) != 2 || links[0] != expected[0] || links[1] != expected[1] {
+		t.Errorf("Expected headers %v in order, got %v", expected, links)
+	}
+}
+
+func TestCallGeneratedArrayBody(t *testing.T) {
+	var received []map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "bozr-body")
+	defer os.RemoveAll(dir)
+
+	writeBody := func(name string, body string) string {
+		path := filepath.Join(dir, name)
+		ioutil.WriteFile(path, []byte(body), 0644)
+		return path
+	}
+
+	newCall := func(bodyFile string) Call {
+		return Call{
+			On: On{
+				Method:   "POST",
+				URL:      server.URL,
+				Headers:  map[string]string{"Content-Type": "application/json"},
+				BodyFile: bodyFile,
+			},
+			Expect: Expect{StatusCode: 200},
+		}
+	}
+
+	t.Run("generated items", func(t *testing.T) {
+		body := writeBody("items.json", `[{{range $i, $n := .Seq 5}}{{if $i}},{{end}}{"id": {{$n}}, "name": "item-{{$n}}"}{{end}}]`)
+
+		trace := call("", newCall(body), NewVars(""))
+		if trace.HasError() {
+			t.Fatal(trace.ErrorCause)
+		}
+
+		if len(received) != 5 || received[4]["name"] != "item-4" {
+			t.Errorf("Expected 5 generated items, got: %v", received)
+		}
+	})
+
+	t.Run("invalid generated json", func(t *testing.T) {
+		body := writeBody("invalid.json", `[{{range .Seq 2}}{"id": {{.}}}{{end}}]`)
+
+		trace := call("", newCall(body), NewVars(""))
+		if !trace.HasError() || !strings.Contains(trace.ErrorCause.Error(), "Generated request body is not a valid JSON") {
+			t.Error("Expected invalid JSON error, got:", trace.ErrorCause)
+		}
+	})
+}
+
+func TestCallMatchMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status": "pending"}`))
+	}))
+	defer server.Close()
+
+	on := On{Method: "GET", URL: server.URL}
+	// either completed synchronously or accepted for processing
+	expect := Expect{StatusCode: 200, Body: map[string]interface{}{"status": "pending"}}
+
+	t.Run("any", func(t *testing.T) {
+		trace := call("", Call{On: on, Expect: expect, MatchMode: "any"}, NewVars(""))
+
+		if trace.HasError() {
+			t.Fatal(trace.ErrorCause)
+		}
+
+		if len(trace.ExpDesc) != 1 {
+			t.Errorf("Expected only met expectation to be listed, got %v", trace.ExpDesc)
+		}
+		for desc := range trace.ExpDesc {
+			if !strings.HasPrefix(desc, "(any of 2) ") {
+				t.Errorf("Expected description to indicate match mode, got %s", desc)
+			}
+		}
+	})
+
+	t.Run("any none met", func(t *testing.T) {
+		trace := call("", Call{On: on, Expect: Expect{StatusCode: 200, Body: map[string]interface{}{"status": "done"}}, MatchMode: "any"}, NewVars(""))
+
+		if trace.ErrorCause == nil || !strings.HasPrefix(trace.ErrorCause.Error(), "None of 2 expectations is met (matchMode: any)") {
+			t.Error("Unexpected error:", trace.ErrorCause)
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		trace := call("", Call{On: on, Expect: expect, MatchMode: "all"}, NewVars(""))
+
+		if trace.ErrorCause == nil || !strings.HasPrefix(trace.ErrorCause.Error(), "Unexpected Status Code") {
+			t.Error("Expected status code failure, got:", trace.ErrorCause)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// recordSequence captures values on paths of response body, which must strictly increase across repeated runs
+func recordSequence(resp *Response, paths []string, trace *CallTrace) error {
+	body, err := resp.Body()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		value, err := GetByPath(body, path)
+		if err != nil {
+			return err
+		}
+
+		switch value.(type) {
+		case float64, string:
+		default:
+			return fmt.Errorf("Expected number or string on path [%s] to compare across runs, got %v", path, value)
+		}
+
+		if trace.Sequence == nil {
+			trace.Sequence = make(map[string]interface{})
+		}
+		trace.Sequence[path] = value
+	}
+
+	return nil
+}
+
+// increases checks next value is greater than previous one. Numeric strings are compared as numbers, other strings lexicographically, e.g. ULIDs
+func increases(prev, next interface{}) bool {
+	prevNum, prevOk := sequenceNumber(prev)
+	nextNum, nextOk := sequenceNumber(next)
+	if prevOk && nextOk {
+		return nextNum > prevNum
+	}
+
+	return fmt.Sprint(next) > fmt.Sprint(prev)
+}
+
+func sequenceNumber(value interface{}) (float64, bool) {
+	switch typed := value.(type) {
+	case float64:
+		return typed, true
+	case string:
+		num, err := strconv.ParseFloat(typed, 64)
+		return num, err == nil
+	}
+
+	return 0, false
+}
+
+// CheckIncreasing verifies values captured with 'increasing' expectation strictly increase from run to run.
+// The first violation of every call and path is reported, runs where the value is not captured are ignored.
+// Values captured in a single run can't be compared, so it fails as well.
+func CheckIncreasing(runs [][]TestResult) error {
+	type capture struct {
+		run   int
+		value interface{}
+	}
+
+	index := make(map[string]int)
+	var names []string
+	var captured [][]capture
+
+	for run, results := range runs {
+		for _, result := range results {
+			if result.Skipped {
+				continue
+			}
+
+			for _, trace := range result.Traces {
+				for path, value := range trace.Sequence {
+					name := fmt.Sprintf("%s / %s, call #%d [%s]", result.Suite.FullName(), result.Case.Name, trace.Num+1, path)
+					i, ok := index[name]
+					if !ok {
+						i = len(names)
+						index[name] = i
+						names = append(names, name)
+						captured = append(captured, nil)
+					}
+
+					captured[i] = append(captured[i], capture{run: run + 1, value: value})
+				}
+			}
+		}
+	}
+
+	if len(names) > 0 && len(runs) < 2 {
+		return fmt.Errorf("Expectation 'increasing' requires at least 2 runs, see --repeat-run:\n    %s", strings.Join(names, "\n    "))
+	}
+
+	var violations []string
+	for i, name := range names {
+		for j := 1; j < len(captured[i]); j++ {
+			prev, next := captured[i][j-1], captured[i][j]
+			if !increases(prev.value, next.value) {
+				violations = append(violations, fmt.Sprintf("    %s: %v in run #%d after %v in run #%d", name, next.value, next.run, prev.value, prev.run))
+				break
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return errors.New("Values did not increase across runs:\n" + strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCheckIncreasing(t *testing.T) {
+	// given
+	var mu sync.Mutex
+	counter := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if req.URL.Path == "/counter" {
+			counter++
+			fmt.Fprintf(w, `{"seq": %d, "id": "%03d"}`, counter, counter)
+			return
+		}
+		// repeats the value in the third run
+		fmt.Fprintf(w, `{"seq": %d}`, map[int]int{1: 1, 2: 2, 3: 2}[counter])
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"sequence.suite.json": `[
+			{"name": "counter", "calls": [{"on": {"method": "GET", "url": "/counter"}, "expect": {"increasing": ["seq", "id"]}}]},
+			{"name": "stuck", "calls": [{"on": {"method": "GET", "url": "/stuck"}, "expect": {"increasing": ["seq"]}}]}
+		]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	var runs [][]TestResult
+	for i := 0; i < 3; i++ {
+		results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, Workers: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, results)
+	}
+	err := CheckIncreasing(runs)
+
+	// then
+	if err == nil {
+		t.Fatal("Expected repeated value to be reported")
+	}
+
+	if !strings.Contains(err.Error(), "sequence / stuck, call #1 [seq]: 2 in run #3 after 2 in run #2") {
+		t.Errorf("Unexpected violation: %s", err)
+	}
+
+	if strings.Contains(err.Error(), "counter") {
+		t.Errorf("Expected counter to increase, got: %s", err)
+	}
+
+	if err := CheckIncreasing(runs[:2]); err != nil {
+		t.Errorf("Expected values to increase in first two runs, got: %s", err)
+	}
+
+	if err := CheckIncreasing(runs[:1]); err == nil || !strings.Contains(err.Error(), "requires at least 2 runs") {
+		t.Errorf("Expected single run to fail, got: %v", err)
+	}
+}
+
+func TestIncreasingMissingPathFails(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"seq": null}`))
+	}))
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{Increasing: []string{"seq"}}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	if !trace.HasError() {
+		t.Error("Expected failure for value which can't be compared across runs")
+	}
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SkipCondition describes when all test cases of a suite are skipped
+type SkipCondition struct {
+	// environments (see Options.Env) the suite is skipped on
+	Env []string `json:"env"`
+	// environment variables, suite is skipped if any of them is not set or empty
+	MissingEnvVars []string `json:"missingEnvVars"`
+	// skip message shared by test cases, met condition is described if empty
+	Reason string `json:"reason"`
+}
+
+// skipReason returns skip message of suite test cases if suite conditions hold for the environment, empty otherwise
+func (suite TestSuite) skipReason(env string) string {
+	if len(suite.RunOnlyOn) > 0 && !containsString(suite.RunOnlyOn, env) {
+		current := env
+		if current == "" {
+			current = "not set"
+		}
+		return fmt.Sprintf("runs only on env %s (current: %s)", strings.Join(suite.RunOnlyOn, ", "), current)
+	}
+
+	cond := suite.SkipIf
+	if cond == nil {
+		return ""
+	}
+
+	met := ""
+	if env != "" && containsString(cond.Env, env) {
+		met = fmt.Sprintf("skipped on env %s", env)
+	}
+
+	for _, name := range cond.MissingEnvVars {
+		if met == "" && os.Getenv(name) == "" {
+			met = fmt.Sprintf("env var %s is not set", name)
+		}
+	}
+
+	if met != "" && cond.Reason != "" {
+		return cond.Reason
+	}
+
+	return met
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestRunSuiteSkipped(t *testing.T) {
+	// given
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	os.Unsetenv("BOZR_TEST_PAYMENTS_TOKEN")
+
+	dir := writeTestFiles(t, map[string]string{
+		"payments.suite.json": `{
+			"skipIf": {"missingEnvVars": ["BOZR_TEST_PAYMENTS_TOKEN"], "reason": "payments sandbox is not configured"},
+			"cases": [
+				{"name": "charge", "calls": [{"on": {"method": "POST", "url": "/charges"}, "expect": {"statusCode": 200}}]},
+				{"name": "refund", "calls": [{"on": {"method": "POST", "url": "/refunds"}, "expect": {"statusCode": 200}}]}
+			]
+		}`,
+		"health.suite.json": `[{"name": "health", "calls": [{"on": {"method": "GET", "url": "/health"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	skipped := 0
+	for _, result := range results {
+		if result.Suite.Name != "payments" {
+			continue
+		}
+
+		skipped++
+		if !result.Skipped || result.SkippedMsg != "payments sandbox is not configured" {
+			t.Errorf("Expected case %s to be skipped with suite reason, got %+v", result.Case.Name, result)
+		}
+	}
+
+	if skipped != 2 || requests != 1 {
+		t.Errorf("Expected 2 skipped cases and 1 request, got %d and %d", skipped, requests)
+	}
+}
+
+func TestSuiteSkipReason(t *testing.T) {
+	os.Setenv("BOZR_TEST_SKIP_TOKEN", "secret")
+	defer os.Unsetenv("BOZR_TEST_SKIP_TOKEN")
+
+	tests := []struct {
+		suite    TestSuite
+		env      string
+		expected string
+	}{
+		{TestSuite{}, "prod", ""},
+		{TestSuite{RunOnlyOn: []string{"staging", "dev"}}, "staging", ""},
+		{TestSuite{RunOnlyOn: []string{"staging", "dev"}}, "prod", "runs only on env staging, dev (current: prod)"},
+		{TestSuite{RunOnlyOn: []string{"staging"}}, "", "runs only on env staging (current: not set)"},
+		{TestSuite{SkipIf: &SkipCondition{Env: []string{"prod"}}}, "prod", "skipped on env prod"},
+		{TestSuite{SkipIf: &SkipCondition{Env: []string{"prod"}}}, "", ""},
+		{TestSuite{SkipIf: &SkipCondition{MissingEnvVars: []string{"BOZR_TEST_SKIP_TOKEN"}}}, "", ""},
+		{TestSuite{SkipIf: &SkipCondition{MissingEnvVars: []string{"BOZR_TEST_SKIP_TOKEN", "BOZR_TEST_SKIP_MISSING"}}}, "", "env var BOZR_TEST_SKIP_MISSING is not set"},
+		{TestSuite{SkipIf: &SkipCondition{Env: []string{"prod"}, Reason: "destructive"}}, "prod", "destructive"},
+		{TestSuite{SkipIf: &SkipCondition{Env: []string{"prod"}, Reason: "destructive"}}, "dev", ""},
+	}
+
+	for _, tt := range tests {
+		if actual := tt.suite.skipReason(tt.env); actual != tt.expected {
+			t.Errorf("%+v on env %q: expected %q, got %q", tt.suite, tt.env, tt.expected, actual)
+		}
+	}
+}
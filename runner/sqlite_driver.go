@@ -0,0 +1,7 @@
+//go:build sqlite
+// +build sqlite
+
+package runner
+
+// SQLite driver requires cgo, so it is compiled in only on demand
+import _ "github.com/mattn/go-sqlite3"
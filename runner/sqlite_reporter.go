@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// sqliteDriver is the name of database/sql driver, it is compiled in with '-tags sqlite'
+const sqliteDriver = "sqlite3"
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	uuid        TEXT NOT NULL,
+	started_at  TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	total       INTEGER NOT NULL,
+	passed      INTEGER NOT NULL,
+	failed      INTEGER NOT NULL,
+	skipped     INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS results (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	run_id      INTEGER NOT NULL REFERENCES runs(id),
+	package     TEXT NOT NULL,
+	suite       TEXT NOT NULL,
+	test_case   TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	duration_ms INTEGER NOT NULL,
+	error       TEXT,
+	started_at  TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS results_run_id ON results(run_id);
+`
+
+// SQLiteReporter appends results of the run into SQLite database on Flush, so trends could be queried across runs.
+// Tables 'runs' and 'results' are created if absent.
+type SQLiteReporter struct {
+	OutPath string
+
+	mu      sync.Mutex
+	start   time.Time
+	results []TestResult
+	err     error
+}
+
+var errSQLiteNotCompiled = errors.New("SQLite support is not compiled in, build with '-tags sqlite'")
+
+// Init remembers start of the run
+func (r *SQLiteReporter) Init() {
+	r.start = time.Now()
+}
+
+// Report retains results of the suite
+func (r *SQLiteReporter) Report(results []TestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, results...)
+}
+
+// Flush inserts the run and its results in a single transaction, failure is available with FlushError
+func (r *SQLiteReporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.err = r.insert()
+	if r.err != nil {
+		r.err = fmt.Errorf("Can't append results into SQLite database %s: %s", r.OutPath, r.err)
+		fmt.Fprintln(os.Stderr, r.err)
+	}
+}
+
+// FlushError returns failure of the last Flush
+func (r *SQLiteReporter) FlushError() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+func (r *SQLiteReporter) insert() error {
+	if !sqliteDriverAvailable() {
+		return errSQLiteNotCompiled
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.OutPath), 0777); err != nil {
+		return err
+	}
+
+	db, err := sql.Open(sqliteDriver, r.OutPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if _, err = db.Exec(sqliteSchema); err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	summary := jsonSummary{Total: len(r.results), DurationMs: int64(time.Since(r.start) / time.Millisecond)}
+	for _, result := range r.results {
+		switch sqliteStatus(result) {
+		case jsonStatusPassed:
+			summary.Passed++
+		case jsonStatusFailed:
+			summary.Failed++
+		case jsonStatusSkipped:
+			summary.Skipped++
+		}
+	}
+
+	run, err := tx.Exec("INSERT INTO runs (uuid, started_at, duration_ms, total, passed, failed, skipped) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		runID, sqliteTime(r.start), summary.DurationMs, summary.Total, summary.Passed, summary.Failed, summary.Skipped)
+	if err != nil {
+		return err
+	}
+
+	id, err := run.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare("INSERT INTO results (run_id, package, suite, test_case, status, duration_ms, error, started_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, result := range r.results {
+		var resultErr sql.NullString
+		if result.HasError() {
+			resultErr = sql.NullString{String: result.Error(), Valid: true}
+		}
+
+		_, err = stmt.Exec(id, result.Suite.PackageName(), result.Suite.Name, result.Case.Name, sqliteStatus(result),
+			int64(result.ExecFrame.Duration()/time.Millisecond), resultErr, sqliteTime(result.ExecFrame.Start))
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func sqliteStatus(result TestResult) string {
+	switch {
+	case result.Skipped:
+		return jsonStatusSkipped
+	case result.HasError():
+		return jsonStatusFailed
+	}
+
+	return jsonStatusPassed
+}
+
+// sqliteTime formats time as text understood by SQLite date and time functions
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02 15:04:05.000")
+}
+
+func sqliteDriverAvailable() bool {
+	for _, name := range sql.Drivers() {
+		if name == sqliteDriver {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewSQLiteReporter returns reporter that appends results into SQLite database file
+func NewSQLiteReporter(path string) Reporter {
+	return &SQLiteReporter{OutPath: path}
+}
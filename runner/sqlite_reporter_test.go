@@ -0,0 +1,68 @@
+//go:build sqlite
+// +build sqlite
+
+package runner
+
+import (
+	"database/sql"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteReporter(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-sqlite")
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "history", "results.db")
+
+	previous := NewSQLiteReporter(path)
+	previous.Init()
+	previous.Report([]TestResult{{Suite: TestSuite{Name: "users"}, Case: TestCase{Name: "get"}, Traces: []*CallTrace{{ErrorCause: errors.New("Status code 500")}}}})
+	previous.Flush()
+
+	reporter := NewSQLiteReporter(path)
+	reporter.Init()
+
+	// when
+	reporter.Report([]TestResult{
+		{Suite: TestSuite{Name: "users", Dir: "api"}, Case: TestCase{Name: "get"}, Traces: []*CallTrace{{RequestMethod: "GET"}}},
+		{Suite: TestSuite{Name: "users", Dir: "api"}, Case: TestCase{Name: "delete"}, Traces: []*CallTrace{{ErrorCause: errors.New("Status code 500")}}},
+		{Suite: TestSuite{Name: "users", Dir: "api"}, Case: TestCase{Name: "update"}, Traces: []*CallTrace{{ErrorCause: errors.New("Status code 409")}}},
+	})
+	reporter.Report([]TestResult{{Suite: TestSuite{Name: "health"}, Case: TestCase{Name: "up"}, Skipped: true}})
+	reporter.Flush()
+
+	// then
+	db, err := sql.Open(sqliteDriver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var runs, failed, summaryFailed int
+	if err := db.QueryRow("SELECT COUNT(*) FROM runs").Scan(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT COUNT(*) FROM results WHERE status = 'failed' AND run_id = (SELECT MAX(id) FROM runs)").Scan(&failed); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow("SELECT failed FROM runs ORDER BY id DESC LIMIT 1").Scan(&summaryFailed); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs != 2 || failed != 2 || summaryFailed != 2 {
+		t.Errorf("Expected 2 runs and 2 failed results of the last one, got %d runs, %d failed results, %d failed in summary", runs, failed, summaryFailed)
+	}
+
+	var pkg, errMsg string
+	if err := db.QueryRow("SELECT package, error FROM results WHERE test_case = 'delete'").Scan(&pkg, &errMsg); err != nil {
+		t.Fatal(err)
+	}
+	if pkg != "api" || errMsg != "Status code 500" {
+		t.Errorf("Unexpected result row: %s, %s", pkg, errMsg)
+	}
+}
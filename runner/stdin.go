@@ -0,0 +1,50 @@
+package runner
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// bodyFileStdin is 'bodyFile' value to send data piped into standard input as request body
+const bodyFileStdin = "-"
+
+// stdinBody reads standard input once, the data is reused by all requests of the run
+type stdinBody struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+var stdinData = &stdinBody{}
+
+func (s *stdinBody) read() ([]byte, error) {
+	s.once.Do(func() {
+		s.data, s.err = readStdin(options.Stdin)
+	})
+
+	return s.data, s.err
+}
+
+func readStdin(r io.Reader) ([]byte, error) {
+	if r == nil {
+		// reading from terminal would block the run until user input
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice != 0 {
+			return nil, errors.New("Request body is expected in stdin ('bodyFile': '-'), but stdin is not piped")
+		}
+		r = os.Stdin
+	}
+
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.New("Can't read request body from stdin: " + err.Error())
+	}
+
+	if len(data) == 0 {
+		return nil, errors.New("Request body is expected in stdin ('bodyFile': '-'), but stdin is empty")
+	}
+
+	return data, nil
+}
@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRunBodyFromStdin(t *testing.T) {
+	// given
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"orders.suite.json": `[
+			{"name": "create", "calls": [{"on": {"method": "POST", "url": "/orders", "bodyFile": "-"}, "expect": {"statusCode": 200}}]},
+			{"name": "validate", "calls": [{"on": {"method": "POST", "url": "/orders/validate", "bodyFile": "-"}, "expect": {"statusCode": 200}}]}
+		]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{
+		Path:    dir,
+		BaseURL: server.URL,
+		Stdin:   strings.NewReader(`{"item": "book"}`),
+	})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, result := range results {
+		if result.HasError() {
+			t.Error(result.Error())
+		}
+	}
+
+	if len(bodies) != 2 || bodies[0] != `{"item": "book"}` || bodies[1] != bodies[0] {
+		t.Errorf("Expected stdin body to be sent by both requests, got %q", bodies)
+	}
+}
+
+func TestRunBodyFromEmptyStdin(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	defer server.Close()
+	defer func(opts Options) { options = opts }(options)
+
+	dir := writeTestFiles(t, map[string]string{
+		"orders.suite.json": `[{"name": "create", "calls": [{"on": {"method": "POST", "url": "/orders", "bodyFile": "-"}, "expect": {"statusCode": 200}}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	// when
+	results, err := Run(context.Background(), Options{Path: dir, BaseURL: server.URL, Stdin: strings.NewReader("")})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || !strings.Contains(results[0].Error(), "stdin is empty") {
+		t.Errorf("Expected empty stdin error, got %v", results)
+	}
+}
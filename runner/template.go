@@ -1,11 +1,14 @@
-package main
+package runner
 
 import (
 	"bytes"
 	"crypto/sha1"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"strings"
 	"text/template"
 	"time"
 
@@ -38,6 +41,21 @@ func (ctx *Funcs) Base64(value string) string {
 	return base64.StdEncoding.EncodeToString([]byte(value))
 }
 
+// URLQuery escapes value to be placed inside URL query
+func (ctx *Funcs) URLQuery(value string) string {
+	return url.QueryEscape(value)
+}
+
+// ToJSON encodes value as JSON, e.g. to embed string with quotes or new lines into JSON body
+func (ctx *Funcs) ToJSON(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
 // SHA1 returns string representation of SHA1 hash bytes
 func (ctx *Funcs) SHA1(value string) string {
 	// fmt.Println("Calculating SHA1: " + value)
@@ -80,6 +98,25 @@ func (ctx *Funcs) Now(tz ...string) time.Time {
 	return time.Now().In(loc)
 }
 
+// Seq returns sequence of numbers from 0 to n-1, e.g. to generate n array items with 'range'
+func (ctx *Funcs) Seq(n int) []int {
+	if n < 0 {
+		n = 0
+	}
+
+	seq := make([]int, n)
+	for i := range seq {
+		seq[i] = i
+	}
+
+	return seq
+}
+
+// Split slices value into all substrings separated by sep, e.g. to iterate over items of a variable with 'range'
+func (ctx *Funcs) Split(value, sep string) []string {
+	return strings.Split(value, sep)
+}
+
 // TemplateContext backs and executes template
 type TemplateContext struct {
 	funcs  *Funcs
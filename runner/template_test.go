@@ -1,6 +1,7 @@
-package main
+package runner
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -96,6 +97,46 @@ func TestFuncBase64(t *testing.T) {
 	}
 }
 
+func TestFuncURLQuery(t *testing.T) {
+	tmplCtx := NewTemplateContext(NewVars(""))
+
+	output := tmplCtx.ApplyTo("q={{ .URLQuery `a b&c=d/ü` }}")
+
+	if tmplCtx.HasErrors() {
+		t.Error("Unexpected error", tmplCtx.Error())
+	}
+
+	expected := "q=a+b%26c%3Dd%2F%C3%BC"
+	if output != expected {
+		t.Errorf("Unexpected output. Expected: %s, Actual: %s", expected, output)
+	}
+}
+
+func TestFuncToJSON(t *testing.T) {
+	vars := NewVars("")
+	vars.Add("comment", "line \"one\"\nline <two>")
+
+	tmplCtx := NewTemplateContext(vars)
+
+	output := tmplCtx.ApplyTo("{\"comment\": {{ .ToJSON `{comment}` }}, \"ids\": {{ .Split `1,2` `,` | .ToJSON }}}")
+
+	if tmplCtx.HasErrors() {
+		t.Error("Unexpected error", tmplCtx.Error())
+	}
+
+	var body struct {
+		Comment string
+		IDs     []string
+	}
+	if err := json.Unmarshal([]byte(output), &body); err != nil {
+		t.Fatalf("Invalid JSON %s: %s", output, err)
+	}
+
+	if body.Comment != "line \"one\"\nline <two>" || len(body.IDs) != 2 {
+		t.Errorf("Unexpected output: %s", output)
+	}
+}
+
 func TestFuncSHA1(t *testing.T) {
 	// given
 	tmpl := "{{ .SHA1 `{username}` }}"
@@ -195,3 +236,17 @@ func TestFuncNowInTZAndFormat(t *testing.T) {
 		t.Error(output, "is not equal to", expected)
 	}
 }
+
+func TestFuncSeqAndSplit(t *testing.T) {
+	ctx := NewTemplateContext(NewVars(""))
+
+	got := ctx.ApplyTo(`[{{range $i, $n := .Seq 3}}{{if $i}},{{end}}{{$n}}{{end}}]`)
+	if got != "[0,1,2]" {
+		t.Error("Expected [0,1,2], got", got)
+	}
+
+	got = ctx.ApplyTo(`{{range .Split "a;b" ";"}}<{{.}}>{{end}}`)
+	if got != "<a><b>" {
+		t.Error("Expected <a><b>, got", got)
+	}
+}
@@ -0,0 +1,287 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// percentiles reported for request and test case durations
+var reportedPercentiles = []float64{50, 90, 99}
+
+// Timings retains durations of all requests and test cases of the run
+type Timings struct {
+	Requests []time.Duration
+	Cases    []time.Duration
+}
+
+func (t *Timings) add(results []TestResult) {
+	for _, result := range results {
+		if result.Skipped {
+			continue
+		}
+
+		t.Cases = append(t.Cases, result.ExecFrame.Duration())
+
+		for _, trace := range result.Traces {
+			if trace.Terminated() {
+				continue
+			}
+
+			t.Requests = append(t.Requests, trace.ExecFrame.Duration())
+		}
+	}
+}
+
+// Percentile returns the smallest duration that is greater or equal to p percents of durations (nearest-rank method)
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
+
+func formatPercentiles(durations []time.Duration) string {
+	values := make([]string, 0, len(reportedPercentiles))
+	for _, p := range reportedPercentiles {
+		values = append(values, Percentile(durations, p).Round(time.Millisecond).String())
+	}
+
+	return strings.Join(values, " / ")
+}
+
+// PerfThreshold limits percentile of request durations, e.g. 'p99=800ms'
+type PerfThreshold struct {
+	Percentile float64
+	Limit      time.Duration
+}
+
+// ParsePerfThreshold parses threshold in format 'p<percentile>=<duration>'
+func ParsePerfThreshold(value string) (PerfThreshold, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "p") {
+		return PerfThreshold{}, fmt.Errorf("Invalid perf threshold '%s'. Expected format: p99=800ms", value)
+	}
+
+	p, err := strconv.ParseFloat(strings.TrimPrefix(parts[0], "p"), 64)
+	if err != nil || p <= 0 || p > 100 {
+		return PerfThreshold{}, fmt.Errorf("Invalid percentile in perf threshold '%s'", value)
+	}
+
+	limit, err := time.ParseDuration(parts[1])
+	if err != nil {
+		return PerfThreshold{}, fmt.Errorf("Invalid duration in perf threshold '%s': %s", value, err)
+	}
+
+	return PerfThreshold{Percentile: p, Limit: limit}, nil
+}
+
+func (t PerfThreshold) String() string {
+	return "p" + strconv.FormatFloat(t.Percentile, 'f', -1, 64) + "=" + t.Limit.String()
+}
+
+func (t PerfThreshold) check(timings *Timings) error {
+	actual := Percentile(timings.Requests, t.Percentile)
+	if actual > t.Limit {
+		return fmt.Errorf("Perf threshold exceeded. Request duration p%s expected: %s, actual: %s",
+			strconv.FormatFloat(t.Percentile, 'f', -1, 64), t.Limit, actual.Round(time.Millisecond))
+	}
+
+	return nil
+}
+
+// CaseTiming limits duration of the test case and time between its calls
+type CaseTiming struct {
+	// max duration of the test case, e.g. '1s'
+	Budget string `json:"budget,omitempty"`
+	// max time between completion of two calls
+	Between []CallsTiming `json:"between,omitempty"`
+	// share of requests of the test case completed within the duration
+	Latency []LatencyBucket `json:"latency,omitempty"`
+}
+
+// LatencyBucket requires at least 'AtLeast' percents of requests to complete within 'Max', e.g. 95% within 200ms
+type LatencyBucket struct {
+	Max     string  `json:"max"`
+	AtLeast float64 `json:"atLeast"`
+}
+
+// CallsTiming limits time from completion of call 'From' to completion of call 'To' (zero based indexes)
+type CallsTiming struct {
+	From int    `json:"from"`
+	To   int    `json:"to"`
+	Max  string `json:"max"`
+}
+
+// check verifies time frames of executed test case against the limits
+func (t CaseTiming) check(result TestResult) error {
+	if t.Budget != "" {
+		budget, err := time.ParseDuration(t.Budget)
+		if err != nil {
+			return fmt.Errorf("Invalid timing budget '%s': %s", t.Budget, err)
+		}
+
+		if took := result.ExecFrame.Duration(); took > budget {
+			return fmt.Errorf("Case took %s, budget %s", took.Round(time.Millisecond), budget)
+		}
+	}
+
+	for _, between := range t.Between {
+		limit, err := time.ParseDuration(between.Max)
+		if err != nil {
+			return fmt.Errorf("Invalid timing limit '%s': %s", between.Max, err)
+		}
+
+		if between.From >= len(result.Traces) || between.To >= len(result.Traces) {
+			return fmt.Errorf("Timing refers call #%d or #%d, but case has %d calls", between.From, between.To, len(result.Traces))
+		}
+
+		took := result.Traces[between.To].ExecFrame.End.Sub(result.Traces[between.From].ExecFrame.End)
+		if took > limit {
+			return fmt.Errorf("Call #%d completed %s after call #%d, limit %s", between.To, took.Round(time.Millisecond), between.From, limit)
+		}
+	}
+
+	for _, bucket := range t.Latency {
+		if err := bucket.check(result.Traces); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// check verifies share of requests completed within the bucket
+func (b LatencyBucket) check(traces []*CallTrace) error {
+	limit, err := time.ParseDuration(b.Max)
+	if err != nil {
+		return fmt.Errorf("Invalid latency bucket '%s': %s", b.Max, err)
+	}
+
+	if len(traces) == 0 {
+		return nil
+	}
+
+	within := 0
+	for _, trace := range traces {
+		if trace.ExecFrame.Duration() <= limit {
+			within++
+		}
+	}
+
+	share := float64(within) * 100 / float64(len(traces))
+	if share < b.AtLeast {
+		return fmt.Errorf("%s%% of %d requests completed within %s, expected at least %s%%",
+			strconv.FormatFloat(share, 'f', 1, 64), len(traces), limit, strconv.FormatFloat(b.AtLeast, 'f', -1, 64))
+	}
+
+	return nil
+}
+
+// TimingsReporter collects durations of the run. On Flush percentiles are written into JSON file (if output is set).
+type TimingsReporter struct {
+	OutPath string
+	// destination of percentiles instead of the file, e.g. os.Stdout
+	Writer io.Writer
+
+	mu      sync.Mutex
+	timings Timings
+}
+
+type timingsSummary struct {
+	Count       int              `json:"count"`
+	Percentiles map[string]int64 `json:"percentilesMs"`
+}
+
+// Init does nothing since timings are collected in memory
+func (r *TimingsReporter) Init() {
+
+}
+
+// Report retains durations of requests and test cases
+func (r *TimingsReporter) Report(results []TestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.timings.add(results)
+}
+
+// Flush writes percentiles into JSON file or writer
+func (r *TimingsReporter) Flush() {
+	if r.OutPath == "" && r.Writer == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(map[string]timingsSummary{
+		"requests": summarize(r.timings.Requests),
+		"cases":    summarize(r.timings.Cases),
+	}, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	if r.Writer != nil {
+		if _, err := r.Writer.Write(append(data, '\n')); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(r.OutPath), 0777)
+	if err == nil {
+		err = writeFileAtomic(r.OutPath, data)
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Check verifies request durations do not exceed thresholds
+func (r *TimingsReporter) Check(thresholds []PerfThreshold) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, threshold := range thresholds {
+		if err := threshold.check(&r.timings); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func summarize(durations []time.Duration) timingsSummary {
+	summary := timingsSummary{Count: len(durations), Percentiles: make(map[string]int64)}
+	for _, p := range reportedPercentiles {
+		key := "p" + strconv.FormatFloat(p, 'f', -1, 64)
+		summary.Percentiles[key] = int64(Percentile(durations, p) / time.Millisecond)
+	}
+
+	return summary
+}
+
+// NewTimingsReporter returns reporter that writes timing percentiles into provided file
+func NewTimingsReporter(outPath string) Reporter {
+	return &TimingsReporter{OutPath: outPath}
+}
@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func millis(values ...int) []time.Duration {
+	durations := make([]time.Duration, 0, len(values))
+	for _, v := range values {
+		durations = append(durations, time.Duration(v)*time.Millisecond)
+	}
+	return durations
+}
+
+func TestPercentile(t *testing.T) {
+	// unsorted on purpose
+	durations := millis(100, 10, 90, 20, 80, 30, 70, 40, 60, 50)
+
+	cases := []struct {
+		p        float64
+		expected time.Duration
+	}{
+		{50, 50 * time.Millisecond},
+		{90, 90 * time.Millisecond},
+		{99, 100 * time.Millisecond},
+		{100, 100 * time.Millisecond},
+		{1, 10 * time.Millisecond},
+	}
+
+	for _, c := range cases {
+		if got := Percentile(durations, c.p); got != c.expected {
+			t.Errorf("p%v expected %s, got %s", c.p, c.expected, got)
+		}
+	}
+
+	if durations[0] != 100*time.Millisecond {
+		t.Error("Percentile calculation modified passed durations")
+	}
+
+	if got := Percentile(nil, 99); got != 0 {
+		t.Errorf("Expected 0 for empty durations, got %s", got)
+	}
+}
+
+func TestParsePerfThreshold(t *testing.T) {
+	threshold, err := ParsePerfThreshold("p99=800ms")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if threshold.Percentile != 99 || threshold.Limit != 800*time.Millisecond {
+		t.Errorf("Unexpected threshold: %+v", threshold)
+	}
+
+	for _, invalid := range []string{"99=800ms", "p99", "p0=1s", "p101=1s", "pxx=1s", "p90=fast"} {
+		if _, err := ParsePerfThreshold(invalid); err == nil {
+			t.Errorf("Expected error for '%s'", invalid)
+		}
+	}
+}
+
+func TestTimingsReporterThreshold(t *testing.T) {
+	// given
+	start := time.Now()
+	trace := func(d int) *CallTrace {
+		return &CallTrace{ExecFrame: TimeFrame{Start: start, End: start.Add(time.Duration(d) * time.Millisecond)}}
+	}
+
+	reporter := &TimingsReporter{}
+	reporter.Report([]TestResult{
+		{Traces: []*CallTrace{trace(100), trace(200)}},
+		{Traces: []*CallTrace{trace(300), trace(900)}},
+		{Skipped: true},
+	})
+
+	// then
+	if err := reporter.Check([]PerfThreshold{{Percentile: 50, Limit: 200 * time.Millisecond}}); err != nil {
+		t.Error(err)
+	}
+
+	err := reporter.Check([]PerfThreshold{{Percentile: 99, Limit: 800 * time.Millisecond}})
+	if err == nil || err.Error() != "Perf threshold exceeded. Request duration p99 expected: 800ms, actual: 900ms" {
+		t.Error("Expected threshold error, got:", err)
+	}
+}
+
+func TestTimingsReporterWritesJSON(t *testing.T) {
+	// given
+	dir, _ := ioutil.TempDir("", "bozr-timings")
+	defer os.RemoveAll(dir)
+
+	start := time.Now()
+	result := TestResult{
+		ExecFrame: TimeFrame{Start: start, End: start.Add(50 * time.Millisecond)},
+		Traces:    []*CallTrace{{ExecFrame: TimeFrame{Start: start, End: start.Add(40 * time.Millisecond)}}},
+	}
+
+	reporter := NewTimingsReporter(filepath.Join(dir, "timings.json"))
+
+	// when
+	reporter.Report([]TestResult{result})
+	reporter.Flush()
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "timings.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var summary map[string]timingsSummary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		t.Fatal(err)
+	}
+
+	if summary["requests"].Count != 1 || summary["requests"].Percentiles["p99"] != 40 {
+		t.Errorf("Unexpected requests summary: %+v", summary["requests"])
+	}
+
+	if summary["cases"].Percentiles["p50"] != 50 {
+		t.Errorf("Unexpected cases summary: %+v", summary["cases"])
+	}
+}
+
+func TestCaseTiming(t *testing.T) {
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	result := TestResult{
+		ExecFrame: TimeFrame{Start: start, End: at(1200)},
+		Traces: []*CallTrace{
+			{ExecFrame: TimeFrame{Start: at(0), End: at(300)}},
+			{ExecFrame: TimeFrame{Start: at(300), End: at(700)}},
+			{ExecFrame: TimeFrame{Start: at(700), End: at(1200)}},
+		},
+	}
+
+	t.Run("within limits", func(t *testing.T) {
+		timing := CaseTiming{Budget: "1500ms", Between: []CallsTiming{{From: 0, To: 2, Max: "1s"}}}
+
+		if err := timing.check(result); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("budget exceeded", func(t *testing.T) {
+		err := CaseTiming{Budget: "1s"}.check(result)
+
+		if err == nil || err.Error() != "Case took 1.2s, budget 1s" {
+			t.Error("Unexpected error:", err)
+		}
+	})
+
+	t.Run("between calls exceeded", func(t *testing.T) {
+		err := CaseTiming{Between: []CallsTiming{{From: 1, To: 2, Max: "400ms"}}}.check(result)
+
+		if err == nil || err.Error() != "Call #2 completed 500ms after call #1, limit 400ms" {
+			t.Error("Unexpected error:", err)
+		}
+	})
+
+	t.Run("unknown call", func(t *testing.T) {
+		err := CaseTiming{Between: []CallsTiming{{From: 0, To: 3, Max: "1s"}}}.check(result)
+
+		if err == nil || !strings.HasPrefix(err.Error(), "Timing refers call #0 or #3") {
+			t.Error("Unexpected error:", err)
+		}
+	})
+}
+
+func TestCaseTimingLatency(t *testing.T) {
+	start := time.Date(2020, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	// durations of 20 requests: 18 fast ones and 2 slow ones
+	var traces []*CallTrace
+	for i := 0; i < 20; i++ {
+		took := 120 * time.Millisecond
+		if i%10 == 9 {
+			took = 450 * time.Millisecond
+		}
+		traces = append(traces, &CallTrace{ExecFrame: TimeFrame{Start: start, End: start.Add(took)}})
+	}
+	result := TestResult{Traces: traces}
+
+	tests := []struct {
+		name    string
+		buckets []LatencyBucket
+		message string
+	}{
+		{name: "threshold met", buckets: []LatencyBucket{{Max: "200ms", AtLeast: 90}, {Max: "500ms", AtLeast: 100}}},
+		{name: "bucket boundary included", buckets: []LatencyBucket{{Max: "120ms", AtLeast: 90}}},
+		{name: "threshold not met", buckets: []LatencyBucket{{Max: "200ms", AtLeast: 95}}, message: "90.0% of 20 requests completed within 200ms, expected at least 95%"},
+		{name: "second bucket not met", buckets: []LatencyBucket{{Max: "500ms", AtLeast: 100}, {Max: "100ms", AtLeast: 50}}, message: "0.0% of 20 requests completed within 100ms, expected at least 50%"},
+		{name: "invalid bucket", buckets: []LatencyBucket{{Max: "fast", AtLeast: 50}}, message: "Invalid latency bucket 'fast'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CaseTiming{Latency: tt.buckets}.check(result)
+
+			if tt.message == "" && err != nil {
+				t.Error(err)
+			}
+			if tt.message != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.message)) {
+				t.Errorf("Expected failure '%s', got %v", tt.message, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+)
+
+// categories of transport errors, used as error type in reports
+const (
+	TransportErrorDNS               = "DNSError"
+	TransportErrorConnectionRefused = "ConnectionRefused"
+	TransportErrorTLS               = "TLSError"
+	TransportErrorTimeout           = "Timeout"
+)
+
+var transportErrorLabels = map[string]string{
+	TransportErrorDNS:               "unresolved host",
+	TransportErrorConnectionRefused: "connection refused",
+	TransportErrorTLS:               "TLS error",
+	TransportErrorTimeout:           "timeout",
+}
+
+// TransportError is a failure to send request or receive response, e.g. unresolved host or refused connection
+type TransportError struct {
+	Category string
+	Err      error
+}
+
+func (e *TransportError) Error() string {
+	return transportErrorLabels[e.Category] + ": " + e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// classifyTransportError wraps error of request sending into TransportError of matching category.
+// Errors of unknown category are returned as is.
+func classifyTransportError(err error) error {
+	category := transportErrorCategory(err)
+	if category == "" {
+		return err
+	}
+
+	return &TransportError{Category: category, Err: err}
+}
+
+func transportErrorCategory(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return TransportErrorTimeout
+		}
+		return TransportErrorDNS
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return TransportErrorConnectionRefused
+	}
+
+	var (
+		unknownAuthority x509.UnknownAuthorityError
+		hostname         x509.HostnameError
+		invalid          x509.CertificateInvalidError
+		recordHeader     tls.RecordHeaderError
+	)
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid) || errors.As(err, &recordHeader) {
+		return TransportErrorTLS
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return TransportErrorTimeout
+	}
+
+	// handshake alerts are not exported by crypto/tls
+	if strings.Contains(err.Error(), "tls: ") {
+		return TransportErrorTLS
+	}
+
+	return ""
+}
+
+// insecureTransport does not verify server certificates
+var insecureTransport = func() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	return transport
+}()
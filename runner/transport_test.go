@@ -0,0 +1,129 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyTransportError(t *testing.T) {
+	dial := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://api.test/users", Err: &net.OpError{Op: "dial", Net: "tcp", Err: err}}
+	}
+
+	tests := []struct {
+		err      error
+		category string
+	}{
+		{dial(&net.DNSError{Err: "no such host", Name: "api.test", IsNotFound: true}), TransportErrorDNS},
+		{dial(&net.DNSError{Err: "i/o timeout", Name: "api.test", IsTimeout: true}), TransportErrorTimeout},
+		{dial(os.NewSyscallError("connect", syscall.ECONNREFUSED)), TransportErrorConnectionRefused},
+		{&url.Error{Op: "Get", URL: "https://api.test", Err: errors.New("remote error: tls: handshake failure")}, TransportErrorTLS},
+		{&url.Error{Op: "Get", URL: "http://api.test", Err: context.DeadlineExceeded}, TransportErrorTimeout},
+		{errors.New("unexpected EOF"), ""},
+	}
+
+	for _, tt := range tests {
+		err := classifyTransportError(tt.err)
+
+		var transportErr *TransportError
+		if errors.As(err, &transportErr) != (tt.category != "") {
+			t.Errorf("%v: expected category %q, got %v", tt.err, tt.category, err)
+			continue
+		}
+
+		if transportErr != nil && transportErr.Category != tt.category {
+			t.Errorf("%v: expected category %q, got %q", tt.err, tt.category, transportErr.Category)
+		}
+	}
+}
+
+func TestCallConnectionRefused(t *testing.T) {
+	// given
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	c := Call{On: On{Method: "GET", URL: "http://" + addr + "/users"}, Expect: Expect{StatusCode: 200}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	var transportErr *TransportError
+	if !errors.As(trace.ErrorCause, &transportErr) || transportErr.Category != TransportErrorConnectionRefused {
+		t.Fatalf("Expected connection refused, got %v", trace.ErrorCause)
+	}
+
+	if !strings.HasPrefix(trace.ErrorCause.Error(), "connection refused: ") {
+		t.Errorf("Unexpected message: %s", trace.ErrorCause)
+	}
+}
+
+func TestCallUntrustedCertificate(t *testing.T) {
+	// given
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	c := Call{On: On{Method: "GET", URL: server.URL}, Expect: Expect{StatusCode: 200}}
+
+	// when
+	trace := call("", c, NewVars(""))
+
+	// then
+	var transportErr *TransportError
+	if !errors.As(trace.ErrorCause, &transportErr) || transportErr.Category != TransportErrorTLS {
+		t.Errorf("Expected TLS error, got %v", trace.ErrorCause)
+	}
+}
+
+func TestJUnitReporterTransportError(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "bozr-junit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cause := classifyTransportError(&url.Error{Op: "Get", URL: "http://api.test", Err: &net.DNSError{Err: "no such host", Name: "api.test"}})
+	results := []TestResult{
+		{
+			Suite:  TestSuite{Name: "users"},
+			Case:   TestCase{Name: "get user"},
+			Traces: []*CallTrace{{ErrorCause: cause}},
+		},
+	}
+
+	reporter := &JUnitXMLReporter{OutPath: dir}
+
+	// when
+	reporter.Report(results)
+
+	// then
+	data, err := ioutil.ReadFile(filepath.Join(dir, "users.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := string(data)
+	for _, expected := range []string{`errors="1"`, `failures="0"`, `<error type="DNSError" message="unresolved host: Get`} {
+		if !strings.Contains(report, expected) {
+			t.Errorf("Expected %s in %s", expected, report)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// resultCounts are numbers of test cases by outcome
+type resultCounts struct {
+	Passed  int
+	Failed  int
+	Skipped int
+}
+
+func (c *resultCounts) add(other resultCounts) {
+	c.Passed += other.Passed
+	c.Failed += other.Failed
+	c.Skipped += other.Skipped
+}
+
+func (c resultCounts) String() string {
+	return fmt.Sprintf("%d passed, %d failed, %d skipped", c.Passed, c.Failed, c.Skipped)
+}
+
+// suiteCounts are outcomes of test cases of a single suite
+type suiteCounts struct {
+	Name string
+	resultCounts
+}
+
+func countResults(results []TestResult) resultCounts {
+	var counts resultCounts
+	for _, result := range results {
+		switch {
+		case result.Skipped:
+			counts.Skipped++
+		case result.HasError():
+			counts.Failed++
+		default:
+			counts.Passed++
+		}
+	}
+
+	return counts
+}
+
+// packageNode is a package in the tree of suites, its counts roll up suites of the package and all nested packages
+type packageNode struct {
+	Name     string
+	Counts   resultCounts
+	Packages map[string]*packageNode
+	Suites   []suiteCounts
+}
+
+func newPackageNode(name string) *packageNode {
+	return &packageNode{Name: name, Packages: make(map[string]*packageNode)}
+}
+
+// buildPackageTree nests packages by their dot separated names, e.g. suites of 'api.users' are placed under 'api' > 'users'
+func buildPackageTree(suites map[string][]suiteCounts) *packageNode {
+	root := newPackageNode("")
+
+	for pkg, pkgSuites := range suites {
+		node := root
+		path := []*packageNode{root}
+
+		if pkg != "" {
+			for _, name := range strings.Split(pkg, ".") {
+				child, ok := node.Packages[name]
+				if !ok {
+					child = newPackageNode(name)
+					node.Packages[name] = child
+				}
+				node = child
+				path = append(path, node)
+			}
+		}
+
+		for _, suite := range pkgSuites {
+			node.Suites = append(node.Suites, suite)
+			for _, parent := range path {
+				parent.Counts.add(suite.resultCounts)
+			}
+		}
+	}
+
+	return root
+}
+
+// writePackageTree writes indented tree of packages and suites with rolled up counts, packages go before suites
+func writePackageTree(w io.Writer, node *packageNode, indent int) {
+	names := make([]string, 0, len(node.Packages))
+	for name := range node.Packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	prefix := strings.Repeat(" ", indent)
+
+	for _, name := range names {
+		child := node.Packages[name]
+		fmt.Fprintf(w, "%s%s (%s)\n", prefix, child.Name, child.Counts)
+		writePackageTree(w, child, indent+defaultIndentSize)
+	}
+
+	suites := append([]suiteCounts(nil), node.Suites...)
+	sort.Slice(suites, func(i, j int) bool { return suites[i].Name < suites[j].Name })
+
+	for _, suite := range suites {
+		fmt.Fprintf(w, "%s%s %s (%s)\n", prefix, caretIcon, suite.Name, suite.resultCounts)
+	}
+}
@@ -0,0 +1,1273 @@
+package runner
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/clbanning/mxj"
+	"github.com/pkg/errors"
+)
+
+// TestSuite represents file with test cases.
+type TestSuite struct {
+	// file name
+	Name string
+	// Path to a directory where suite is located
+	// Relative to the suite root
+	Dir string
+	// base URL of suite requests, relative one is joined with the base URL of the run
+	BaseURL string
+	// authentication of suite requests unless request has its own
+	Auth *Auth
+	// 'User-Agent' header of suite requests unless request has its own
+	UserAgent string
+	// HTTP client settings of suite requests, e.g. timeout or proxy
+	Client *ClientOptions
+	// all test cases are skipped if the condition holds
+	SkipIf *SkipCondition
+	// environments (see Options.Env) the suite is executed on, all if empty
+	RunOnlyOn []string
+	// test cases listed in a file
+	Cases []TestCase
+}
+
+// PackageName builds name of a package based on folder where test is located
+func (suite TestSuite) PackageName() string {
+	if strings.HasPrefix(suite.Dir, ".") {
+		return ""
+	}
+
+	return strings.Replace(filepath.ToSlash(suite.Dir), "/", ".", -1)
+}
+
+// FullName builds name of the test including package and test name
+func (suite TestSuite) FullName() string {
+	pkg := suite.PackageName()
+	if pkg == "" {
+		return suite.Name
+	}
+
+	return fmt.Sprintf("%s.%s", suite.PackageName(), suite.Name)
+}
+
+// TestCase represents single test scenario
+type TestCase struct {
+	Name   string                 `json:"name,omitempty"`
+	Ignore *string                `json:"ignore,omitempty"`
+	Args   map[string]interface{} `json:"args,omitempty"`
+	Calls  []Call                 `json:"calls,omitempty"`
+	// time limits of the test case and between its calls
+	Timing *CaseTiming `json:"timing,omitempty"`
+}
+
+// Call defines metadata for one request-response verification within TestCase
+type Call struct {
+	Args     map[string]interface{} `json:"args,omitempty"`
+	On       On                     `json:"on,omitempty"`
+	Expect   Expect                 `json:"expect,omitempty"`
+	Remember Remember               `json:"remember,omitempty"`
+	// 'all' (default) expectations must be met or 'any' of them
+	MatchMode string `json:"matchMode,omitempty"`
+	// expectations overridden or added when run for the environment, key is environment name
+	ExpectEnv map[string]json.RawMessage `json:"expectEnv,omitempty"`
+	// follow links to next pages, expectations are checked against accumulated items
+	Paginate *Pagination `json:"paginate,omitempty"`
+	// repeat the request to verify it is rate limited, expectations are checked against statuses of all requests
+	RateLimit *RateLimitProbe `json:"rateLimit,omitempty"`
+	// path of the value in response body that expectations are checked against, e.g. 'data.order'
+	Extract string `json:"extract,omitempty"`
+	// repeat the call while it fails
+	Retry *CallRetry `json:"retry,omitempty"`
+}
+
+// expectations of the call are met if at least one of them passes
+const matchModeAny = "any"
+
+// Remember defines items from HTTP response to persist for usage in future calls
+type Remember struct {
+	BPath   map[string]string `json:"bodyPath,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// name of the snapshot of response body compared by 'expect.consistentWith' of the next calls of the suite
+	Snapshot string `json:"snapshot,omitempty"`
+	// timing of the call in milliseconds, key is variable name, value is 'duration' or 'ttfb' (time to first byte)
+	Timing map[string]string `json:"timing,omitempty"`
+}
+
+// On is a metadata for building a HTTP request
+type On struct {
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Headers  map[string]string `json:"headers"`
+	Params   map[string]string `json:"params"`
+	Body     json.RawMessage   `json:"body"`
+	BodyFile string            `json:"bodyFile"`
+	// serializes JSON body into the format, e.g. yaml, msgpack or cbor, see RegisterBodyFormat
+	Format string `json:"format"`
+	// send 'If-None-Match' and 'If-Modified-Since' headers with validators of the previous response
+	Conditional bool `json:"conditional"`
+	// open WebSocket connection and exchange messages instead of plain request
+	WebSocket *WebSocketOn `json:"websocket"`
+	// read server-sent events of text/event-stream response for a bounded time
+	EventStream *EventStreamOn `json:"eventStream"`
+	// serialize request and parse response as protobuf messages, optionally framed as gRPC-Web
+	Protobuf *ProtobufOn `json:"protobuf"`
+	// headers provided as a list, order and duplicates are preserved
+	HeaderList []Header `json:"-"`
+	// challenge-response authentication, suite one is used if not set
+	Auth *Auth `json:"auth"`
+	// redirect responses are followed (default), otherwise redirect response itself is verified
+	FollowRedirects *bool `json:"followRedirects"`
+	// HTTP client settings of the suite
+	Client *ClientOptions `json:"-"`
+}
+
+// Header is a single request header
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// UnmarshalJSON accepts headers either as an object or as a list of name/value pairs
+func (on *On) UnmarshalJSON(data []byte) error {
+	type plainOn On
+	aux := struct {
+		*plainOn
+		Headers json.RawMessage `json:"headers"`
+	}{plainOn: (*plainOn)(on)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	raw := bytes.TrimSpace(aux.Headers)
+	if len(raw) > 0 && raw[0] == '[' {
+		return json.Unmarshal(raw, &on.HeaderList)
+	}
+
+	if len(raw) > 0 {
+		return json.Unmarshal(raw, &on.Headers)
+	}
+
+	return nil
+}
+
+// BodyContent returns request body content regardless of its source
+// e.g. provided inline or fetched from file
+func (on On) BodyContent(suitePath string) (string, error) {
+	const quote byte = '"'
+
+	dat := []byte(on.Body)
+	if len(dat) > 0 && dat[0] == quote && dat[len(dat)-1] == quote {
+		dat = dat[1 : len(dat)-1]
+	} // remove leading and trailing double quotes (suppress JSON string)
+
+	if on.BodyFile == bodyFileStdin {
+		d, err := stdinData.read()
+		if err != nil {
+			return "", err
+		}
+
+		return string(d), nil
+	}
+
+	if on.BodyFile != "" {
+		uri, err := toAbsPath(suitePath, on.BodyFile)
+		if err != nil {
+			return "", err
+		}
+
+		d, err := ioutil.ReadFile(uri)
+		if err != nil {
+			return "", fmt.Errorf("Can't read body file: %s", err.Error())
+		}
+
+		dat = d
+	}
+
+	return string(dat), nil
+}
+
+// Expect is a metadata for HTTP response verification
+type Expect struct {
+	StatusCode int `json:"statusCode"`
+	// reason phrase of the status line, e.g. 'Not Found'
+	StatusText string `json:"statusText"`
+	// shortcut for content-type header
+	ContentType string `json:"contentType"`
+	// expected 'charset' parameter of Content-Type, regardless of media type
+	Charset        string                  `json:"charset"`
+	Headers        map[string]string       `json:"headers"`
+	BPath          map[string]interface{}  `json:"bodyPath"`
+	Body           interface{}             `json:"body"`
+	ExactBody      interface{}             `json:"exactBody"`
+	Absent         []string                `json:"absent"`
+	BodySchemaRaw  json.RawMessage         `json:"bodySchema"`
+	BodySchemaFile string                  `json:"bodySchemaFile"`
+	BodySchemaURI  string                  `json:"bodySchemaURI"`
+	Cookies        map[string]CookieAssert `json:"cookies"`
+	// 'Content-Encoding' sent by the server before decompression, e.g. 'gzip' or 'identity'
+	ContentEncoding string `json:"contentEncoding"`
+	// typed header values compared with expected ones, e.g. 'X-RateLimit-Remaining' > 0
+	HeaderCompare []HeaderComparison `json:"headerCompare"`
+	// all occurrences of headers in order, e.g. several 'Set-Cookie', every value is a prefix of the occurrence
+	HeaderValues map[string][]string `json:"headerValues"`
+	// number of occurrences of headers
+	HeaderCount map[string]int `json:"headerCount"`
+	// status and body match documented responses of the operation of OpenAPI document
+	OpenAPI *OpenAPIAssert `json:"openapi"`
+	// values on body paths strictly increase across repeated runs, e.g. sequence numbers
+	Increasing []string `json:"increasing"`
+	// body equals the snapshot remembered by a previous call of the suite, e.g. the same GET repeated
+	ConsistentWith *ConsistencyAssert `json:"consistentWith"`
+	// CEL expressions over 'status', 'headers', 'body' and 'durationMs' which must be true
+	CEL CELExpressions `json:"cel"`
+	// fields of 'absent' paths with null value are treated as absent, by default they are present
+	NullAsAbsent bool `json:"nullAsAbsent"`
+	// headers must not be present in the response, e.g. 'Server' revealing technology stack
+	HeadersAbsent []string `json:"headersAbsent"`
+	// response has common security headers and does not reveal technology stack
+	SecurityHeaders bool `json:"securityHeaders"`
+	// trailers sent after the body, e.g. 'grpc-status', any value if empty
+	Trailers map[string]string `json:"trailers"`
+	// response body exactly matches one of the expected bodies
+	OneOf []interface{} `json:"oneOf"`
+	// numbers and strings holding JSON numbers are equal in body, exactBody, oneOf and bodyPath, e.g. "5" and 5
+	CoerceNumbers bool `json:"coerceNumbers"`
+	// pairs of fields of the response body are consistent, e.g. 'total' == 'items.size()'
+	Compare []FieldComparison `json:"compare"`
+	// details of the server certificate
+	Certificate *CertificateAssert `json:"certificate"`
+	// value of 'Location' header of redirect response
+	Location *LocationAssert `json:"location"`
+	// arrays on path are sorted by element field
+	Sorted map[string]SortAssert `json:"sorted"`
+	// arrays on path have unique elements, or unique values of element field
+	Unique map[string]string `json:"unique"`
+	// values on path are of JSON type: number, string, boolean, null, array or object
+	Types map[string]string `json:"types"`
+	// all, at least one or none of the nested expectations are met, e.g. [{"statusCode": 200}, {"bodyPath": {"role": "admin"}}]
+	And []Expect `json:"and"`
+	Or  []Expect `json:"or"`
+	Not *Expect  `json:"not"`
+	// some, all or none of elements of arrays on path match the predicate (element field path -> expected value)
+	AnyMatch  map[string]map[string]interface{} `json:"anyMatch"`
+	AllMatch  map[string]map[string]interface{} `json:"allMatch"`
+	NoneMatch map[string]map[string]interface{} `json:"noneMatch"`
+	// response body is well-formed JSON
+	IsJSON bool `json:"isJSON"`
+	// response body is well-formed XML
+	IsXML bool `json:"isXML"`
+	// raw response body contains the text
+	BodyContains string `json:"bodyContains"`
+	// raw response body matches the regular expression
+	BodyMatches string `json:"bodyMatches"`
+	// response content type satisfies 'Accept' header of the request
+	ContentNegotiated bool `json:"contentNegotiated"`
+	// response is '304 Not Modified' for conditional request
+	NotModified bool `json:"notModified"`
+	// size of the response body in bytes and its consistency with 'Content-Length' header
+	BodySize *BodySizeAssert `json:"bodySize"`
+	// severity of expectations, key is expectation name, value is 'error' (default) or 'warning'
+	Severity map[string]string `json:"severity"`
+	// expectations temporarily disabled, key is expectation name, value is a reason
+	Disabled map[string]string `json:"disabled"`
+}
+
+// BodySizeAssert describes expected size of the response body
+type BodySizeAssert struct {
+	Bytes              *int `json:"bytes"`
+	MatchContentLength bool `json:"matchContentLength"`
+}
+
+// FieldComparison describes expected relation of two fields of the response body
+type FieldComparison struct {
+	Left string `json:"left"`
+	// one of ==, !=, <, <=, >, >=. Default is ==
+	Op    string `json:"op"`
+	Right string `json:"right"`
+}
+
+// SortAssert describes expected ordering of array elements
+type SortAssert struct {
+	// path of element field to sort by, element itself is compared if empty
+	By string `json:"by"`
+	// 'asc' (default) or 'desc'
+	Order string `json:"order"`
+}
+
+// CookieAssert describes expected value and attributes of a cookie set by the response
+type CookieAssert struct {
+	Value      string `json:"value"`
+	ValueRegex string `json:"valueRegex"`
+	HTTPOnly   *bool  `json:"httpOnly"`
+	Secure     *bool  `json:"secure"`
+	SameSite   string `json:"sameSite"`
+	MaxAge     *int   `json:"maxAge"`
+}
+
+func (e Expect) BodyPath() map[string]interface{} {
+	return e.BPath
+}
+
+var jsonSchemaCache sync.Map
+
+func (e Expect) loadSchemaFromFile(suitePath string) ([]byte, error) {
+
+	if e.BodySchemaFile == "" {
+		return nil, nil
+	}
+
+	uri, err := toAbsPath(suitePath, e.BodySchemaFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var cached, ok = jsonSchemaCache.Load(uri)
+	if ok {
+		debugf("loading json schema from the cache: %s", uri)
+		v, _ := cached.([]byte)
+		return v, nil
+	}
+
+	debugf("loading json schema: %s", uri)
+
+	schema, err := ioutil.ReadFile(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonSchemaCache.Store(uri, schema)
+
+	return schema, nil
+}
+
+func (e Expect) loadSchemaFromURI() ([]byte, error) {
+	uri := toAbsURL(options.BaseURL, e.BodySchemaURI)
+
+	if uri == "" {
+		return nil, nil
+	}
+
+	var cached, ok = jsonSchemaCache.Load(uri)
+	if ok {
+		debugf("loading json schema from the cache: %s", uri)
+		v, _ := cached.([]byte)
+		return v, nil
+	}
+
+	debugf("loading json schema: %s", uri)
+
+	resp, err := http.Get(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonSchemaCache.Store(uri, schema)
+
+	return schema, nil
+}
+
+// populateWith resolves variables in expected values, e.g. '{env:EXPECTED_VERSION}'. Unknown placeholders are kept as is, like in requests
+func (e *Expect) populateWith(vars *Vars) error {
+	tmplCtx := NewTemplateContext(vars)
+
+	if e.ConsistentWith != nil {
+		consistency := *e.ConsistentWith
+		consistency.body, consistency.found = vars.snapshots[consistency.Snapshot]
+		e.ConsistentWith = &consistency
+	}
+
+	e.StatusText = tmplCtx.ApplyTo(e.StatusText)
+	e.ContentType = tmplCtx.ApplyTo(e.ContentType)
+	e.Charset = tmplCtx.ApplyTo(e.Charset)
+	e.ContentEncoding = tmplCtx.ApplyTo(e.ContentEncoding)
+	e.BodyContains = tmplCtx.ApplyTo(e.BodyContains)
+	e.BodyMatches = tmplCtx.ApplyTo(e.BodyMatches)
+
+	cel := make(CELExpressions, len(e.CEL))
+	for i, expr := range e.CEL {
+		cel[i] = tmplCtx.ApplyTo(expr)
+	}
+	e.CEL = cel
+
+	// maps are shared with the test case definition, populate copies
+	headers := make(map[string]string, len(e.Headers))
+	for name, valueTmpl := range e.Headers {
+		headers[name] = tmplCtx.ApplyTo(valueTmpl)
+	}
+	e.Headers = headers
+
+	trailers := make(map[string]string, len(e.Trailers))
+	for name, valueTmpl := range e.Trailers {
+		trailers[name] = tmplCtx.ApplyTo(valueTmpl)
+	}
+	e.Trailers = trailers
+
+	headerCompare := make([]HeaderComparison, len(e.HeaderCompare))
+	for i, comparison := range e.HeaderCompare {
+		comparison.Value = tmplCtx.ApplyTo(comparison.Value)
+		headerCompare[i] = comparison
+	}
+	e.HeaderCompare = headerCompare
+
+	headerValues := make(map[string][]string, len(e.HeaderValues))
+	for name, valueTmpls := range e.HeaderValues {
+		values := make([]string, len(valueTmpls))
+		for i, valueTmpl := range valueTmpls {
+			values[i] = tmplCtx.ApplyTo(valueTmpl)
+		}
+		headerValues[name] = values
+	}
+	e.HeaderValues = headerValues
+
+	cookies := make(map[string]CookieAssert, len(e.Cookies))
+	for name, cookie := range e.Cookies {
+		cookie.Value = tmplCtx.ApplyTo(cookie.Value)
+		cookies[name] = cookie
+	}
+	e.Cookies = cookies
+
+	if e.Location != nil {
+		e.Location = &LocationAssert{
+			Equals:  tmplCtx.ApplyTo(e.Location.Equals),
+			Prefix:  tmplCtx.ApplyTo(e.Location.Prefix),
+			Matches: tmplCtx.ApplyTo(e.Location.Matches),
+		}
+	}
+
+	e.Body = populateProperty(tmplCtx, e.Body)
+	e.ExactBody = populateProperty(tmplCtx, e.ExactBody)
+	oneOf := make([]interface{}, len(e.OneOf))
+	for i, body := range e.OneOf {
+		oneOf[i] = populateProperty(tmplCtx, body)
+	}
+	e.OneOf = oneOf
+	e.BPath = populateProperty(tmplCtx, e.BodyPath()).(map[string]interface{})
+	e.AnyMatch = populatePredicates(tmplCtx, e.AnyMatch)
+	e.AllMatch = populatePredicates(tmplCtx, e.AllMatch)
+	e.NoneMatch = populatePredicates(tmplCtx, e.NoneMatch)
+
+	// nested sections are shared with the test case definition, populate copies
+	for _, nested := range []*[]Expect{&e.And, &e.Or} {
+		sections := make([]Expect, len(*nested))
+		for i, section := range *nested {
+			if err := section.populateWith(vars); err != nil {
+				return err
+			}
+			sections[i] = section
+		}
+		*nested = sections
+	}
+
+	if e.Not != nil {
+		not := *e.Not
+		if err := not.populateWith(vars); err != nil {
+			return err
+		}
+		e.Not = &not
+	}
+
+	if tmplCtx.HasErrors() {
+		return tmplCtx.Error()
+	}
+
+	return nil
+}
+
+func populatePredicates(tmpl *TemplateContext, predicates map[string]map[string]interface{}) map[string]map[string]interface{} {
+	if predicates == nil {
+		return nil
+	}
+
+	result := make(map[string]map[string]interface{}, len(predicates))
+	for path, predicate := range predicates {
+		result[path] = populateProperty(tmpl, predicate).(map[string]interface{})
+	}
+
+	return result
+}
+
+func populateProperty(tmpl *TemplateContext, prop interface{}) interface{} {
+
+	switch typedProp := prop.(type) {
+	case string:
+		// keep type of the variable referenced as a whole, e.g. "{createdId}" -> 42
+		if val, ok := tmpl.vars.Value(typedProp); ok {
+			if _, isStr := val.(string); !isStr {
+				return val
+			}
+		}
+
+		r := tmpl.ApplyTo(typedProp)
+		debugf("Populated template: %v -> %v", typedProp, r)
+		return r
+
+	case []string:
+		var result = make([]string, 0)
+		for _, item := range typedProp {
+			result = append(result, tmpl.ApplyTo(item))
+		}
+		return result
+
+	case []interface{}:
+		var result = make([]interface{}, 0, len(typedProp))
+		for _, item := range typedProp {
+			result = append(result, populateProperty(tmpl, item))
+		}
+		return result
+
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for pk, pv := range typedProp {
+			result[pk] = populateProperty(tmpl, pv)
+		}
+		return result
+
+	default:
+		// no transformation are required
+		return prop
+	}
+
+}
+
+func toAbsPath(suitePath string, assetPath string) (string, error) {
+	debug.Printf("Building absolute path using: suiteDir: %s, srcDir: %s, assetPath: %s", options.Path, suitePath, assetPath)
+	if filepath.IsAbs(assetPath) {
+		// ignore srcDir
+		return assetPath, nil
+	}
+
+	uri, err := filepath.Abs(filepath.Join(options.Path, suitePath, assetPath))
+	if err != nil {
+		return "", errors.New("Invalid file path: " + assetPath)
+	}
+
+	return filepath.ToSlash(uri), nil
+}
+
+// toAbsURL returns absolute URL to a schema
+func toAbsURL(baseHost, uri string) string {
+	if uri == "" {
+		return ""
+	}
+
+	isHTTP := strings.HasPrefix(uri, "http://")
+	isHTTPS := strings.HasPrefix(uri, "https://")
+
+	if isHTTP || isHTTPS {
+		return uri
+	}
+
+	return strings.TrimSuffix(baseHost, "/") + "/" + strings.TrimPrefix(uri, "/")
+}
+
+// TestResult represents single test case for reporting
+type TestResult struct {
+	Suite      TestSuite
+	Case       TestCase
+	Skipped    bool
+	SkippedMsg string
+	Traces     []*CallTrace
+	// arguments and remembered values at the end of the test case
+	Vars map[string]interface{}
+
+	ExecFrame TimeFrame
+}
+
+func (result *TestResult) HasError() bool {
+	for _, trace := range result.Traces {
+		if trace.HasError() {
+			return true
+		}
+	}
+	return false
+}
+
+// retries counts repeated attempts of all calls
+func (result *TestResult) retries() int {
+	count := 0
+	for _, trace := range result.Traces {
+		count = count + trace.Retries
+	}
+	return count
+}
+
+func (result *TestResult) warnings() int {
+	count := 0
+	for _, trace := range result.Traces {
+		count = count + len(trace.ExpWarnings)
+	}
+	return count
+}
+
+func (result *TestResult) skippedExps() int {
+	count := 0
+	for _, trace := range result.Traces {
+		count = count + len(trace.ExpSkipped)
+	}
+	return count
+}
+
+func (result *TestResult) Error() string {
+	if trace := result.failedTrace(); trace != nil {
+		return trace.ErrorCause.Error()
+	}
+	return ""
+}
+
+// failedTrace returns the first trace with error, nil if test case passed
+func (result *TestResult) failedTrace() *CallTrace {
+	for _, trace := range result.Traces {
+		if trace.HasError() {
+			return trace
+		}
+	}
+	return nil
+}
+
+// TimeFrame describes period of time
+type TimeFrame struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration of TimeFrame from Start to End
+func (tf TimeFrame) Duration() time.Duration {
+	return tf.End.Sub(tf.Start)
+}
+
+// Extend does extension of time perod by other provided TimeFrame
+// from earlier start to elder end
+func (tf *TimeFrame) Extend(tf2 TimeFrame) {
+	if tf.Start.After(tf2.Start) {
+		tf.Start = tf2.Start
+	}
+
+	if tf.End.Before(tf2.End) {
+		tf.End = tf2.End
+	}
+}
+
+// CallTrace stands for test error in report
+type CallTrace struct {
+	Num           int
+	RequestMethod string
+	RequestURL    string
+	RequestDump   string
+	ResponseDump  string
+	ErrorCause    error
+	ExpDesc       map[string]bool
+	// disabled expectations with the reason
+	ExpSkipped map[string]string
+	// failed expectations of 'warning' severity with the failure message
+	ExpWarnings map[string]string
+	ExecFrame   TimeFrame
+	// time from the start of the call to the first byte of the response
+	FirstByte time.Duration
+	// number of failed attempts repeated before the reported one
+	Retries int
+	// certificate chain of the server, if response is received over TLS
+	PeerCertificates []*x509.Certificate
+	// trailers received after the response body
+	Trailers http.Header
+	// server-sent events received, see On.EventStream
+	Events []ServerEvent
+	// values captured for 'increasing' expectation, key is body path
+	Sequence map[string]interface{}
+}
+
+func (trace *CallTrace) addExp(desc string) {
+	if trace.ExpDesc == nil {
+		trace.ExpDesc = make(map[string]bool)
+	}
+	trace.ExpDesc[desc] = false
+}
+
+func (trace *CallTrace) addSkippedExp(desc string, reason string) {
+	if trace.ExpSkipped == nil {
+		trace.ExpSkipped = make(map[string]string)
+	}
+	trace.ExpSkipped[desc] = reason
+}
+
+// skippedExps returns descriptions of skipped expectations in order
+func (trace *CallTrace) skippedExps() []string {
+	exps := make([]string, 0, len(trace.ExpSkipped))
+	for exp := range trace.ExpSkipped {
+		exps = append(exps, exp)
+	}
+	sort.Strings(exps)
+
+	return exps
+}
+
+func (trace *CallTrace) addWarning(desc string, err error) {
+	if trace.ExpWarnings == nil {
+		trace.ExpWarnings = make(map[string]string)
+	}
+	trace.ExpWarnings[desc] = err.Error()
+}
+
+func (trace *CallTrace) addFail(err error) {
+	if trace.ExpDesc == nil {
+		trace.ExpDesc = make(map[string]bool)
+	}
+
+	trace.ErrorCause = err
+	trace.ExpDesc[err.Error()] = true
+}
+
+func (trace *CallTrace) HasError() bool {
+	return trace.ErrorCause != nil
+}
+
+// Terminated returns true if request failed due to the issues with making request
+// or parsing response, not due to failed expectations
+func (trace *CallTrace) Terminated() bool {
+	return trace.HasError() && !trace.hasFailedExp()
+}
+
+func (trace *CallTrace) hasFailedExp() bool {
+	for _, failed := range trace.ExpDesc {
+		if failed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Response wraps test call HTTP response
+type Response struct {
+	http *http.Response
+	// body transcoded into UTF-8
+	body []byte
+	// original body if it is transcoded from another charset
+	encoded    []byte
+	parsedBody interface{}
+	// 'Content-Encoding' sent by the server, body is decompressed
+	contentEncoding string
+	// body exceeded max response size and is read partially
+	truncated bool
+	// time of the call, available to 'expect.cel' as 'durationMs'
+	duration time.Duration
+	// parses body regardless of its content type, e.g. protobuf message of the call
+	format *BodyFormat
+}
+
+// newResponse creates response with body transcoded into UTF-8 according to 'Content-Type' charset
+func newResponse(resp *http.Response, body []byte) (Response, error) {
+	r := Response{http: resp, body: body}
+
+	decoded, ok, err := decodeCharset(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return r, err
+	}
+	if ok {
+		r.body, r.encoded = decoded, body
+	}
+
+	return r, nil
+}
+
+// size returns number of bytes received
+func (resp *Response) size() int {
+	if resp.encoded != nil {
+		return len(resp.encoded)
+	}
+
+	return len(resp.body)
+}
+
+// Body returns parsed response (array or map) depending on provided 'Content-Type'
+// supported content types are 'application/json', 'application/xml', 'text/xml'
+func (resp *Response) Body() (interface{}, error) {
+	if resp.parsedBody != nil {
+		return resp.parsedBody, nil
+	}
+
+	var err error
+	resp.parsedBody, err = resp.parseBody()
+
+	return resp.parsedBody, err
+}
+
+func (resp Response) parseBody() (interface{}, error) {
+
+	if len(resp.body) == 0 {
+		return nil, nil
+	}
+
+	contentType, _, _ := mime.ParseMediaType(resp.http.Header.Get("content-type"))
+	if isXMLMediaType(contentType) {
+		m, err := mxj.NewMapXml(resp.body)
+		if err == nil {
+			return m.Old(), nil
+		}
+		return nil, err
+	}
+
+	if isJSONMediaType(contentType) {
+		var (
+			body interface{}
+			err  error
+		)
+		if string(resp.body[0]) == "[" {
+			body = make([]interface{}, 0)
+			err = json.Unmarshal(resp.body, &body)
+		} else {
+			body = make(map[string]interface{})
+			err = json.Unmarshal(resp.body, &body)
+		}
+
+		if err == nil {
+			return body, nil
+		}
+		return nil, err
+	}
+
+	if resp.format != nil {
+		return resp.format.Unmarshal(resp.body)
+	}
+
+	if format, ok := responseFormat(contentType); ok {
+		return format.Unmarshal(resp.body)
+	}
+
+	if contentType == "text/html" {
+		m, err := mxj.NewMapXmlSeq(resp.body)
+		if err == nil {
+			return m.Old(), nil
+		}
+		return nil, err
+	}
+
+	return nil, errors.New("Cannot parse body. Unsupported content type")
+}
+
+// isJSONMediaType checks media type is JSON including structured syntax suffix, e.g. application/problem+json
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// isXMLMediaType checks media type is XML including structured syntax suffix, e.g. application/atom+xml
+func isXMLMediaType(mediaType string) bool {
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// ToString return string representation of response data
+// including status code, headers and body.
+func (resp *Response) ToString() string {
+	http := resp.http
+
+	headers := "\n"
+	for k, v := range http.Header {
+		headers = fmt.Sprintf("%s%s: %s\n", headers, k, strings.Join(v, " "))
+	}
+
+	if resp.truncated {
+		return fmt.Sprintf("%s \n %s \n%s\n<truncated, exceeded max size of %d bytes>", http.Status, headers, logBody(resp.body, resp.http.Header.Get("content-type")), maxResponseSize())
+	}
+
+	body := resp.body
+	contentType, _, _ := mime.ParseMediaType(resp.http.Header.Get("content-type"))
+	if isJSONMediaType(contentType) {
+		data, _ := resp.Body()
+		body, _ = json.MarshalIndent(data, "", "  ")
+	}
+
+	// structured body of other formats is shown as JSON
+	logType := resp.http.Header.Get("content-type")
+	if _, ok := responseFormat(contentType); ok || resp.format != nil && !isJSONMediaType(contentType) && !isXMLMediaType(contentType) {
+		if data, err := resp.Body(); err == nil {
+			body, _ = json.MarshalIndent(data, "", "  ")
+			logType = "application/json"
+		}
+	}
+
+	if isXMLMediaType(contentType) {
+		resp.Body()
+		mp, _ := mxj.NewMapXml(resp.body, false)
+		body, _ = mp.XmlIndent("", "  ")
+	}
+
+	details := fmt.Sprintf("%s \n %s \n%s", http.Status, headers, logBody(body, logType))
+	return details
+}
+
+const (
+	envVarPrefix       = "env"
+	ctxVarPrefix       = "ctx"
+	varPrefixSeparator = ":"
+)
+
+// Vars defines map of test case level variables (e.g. args, remember, env)
+type Vars struct {
+	// variables ready to be used
+	items map[string]interface{}
+	used  map[string]bool
+	// snapshots of response bodies by name, shared by test cases of the suite
+	snapshots map[string]interface{}
+}
+
+// NewVars create new Vars object with default set of env variables
+func NewVars(baseURL string) *Vars {
+	v := &Vars{
+		items:     make(map[string]interface{}),
+		used:      make(map[string]bool),
+		snapshots: make(map[string]interface{}),
+	}
+
+	v.addContext(baseURL)
+	v.addEnv()
+
+	return v
+}
+
+func (v *Vars) addContext(baseURL string) {
+	v.items[ctxVarPrefix+varPrefixSeparator+"base_url"] = baseURL
+}
+
+// setContext sets or overrides context variable, e.g. 'ctx:etag'
+func (v *Vars) setContext(name string, val interface{}) {
+	v.items[ctxVarPrefix+varPrefixSeparator+name] = val
+}
+
+func (v *Vars) baseURL() string {
+	return toString(v.items[ctxVarPrefix+varPrefixSeparator+"base_url"])
+}
+
+func (v *Vars) addEnv() {
+
+	for _, e := range os.Environ() {
+		v.parseEnv(e)
+	}
+}
+
+func (v *Vars) parseEnv(env string) {
+	pair := strings.SplitN(env, "=", 2)
+	v.items[envVarPrefix+varPrefixSeparator+pair[0]] = pair[1]
+}
+
+// Add is adding variable with name and value to map.
+// References to other variables will be resolved upon add.
+// If variable is a template, it will executed.
+func (v *Vars) Add(name string, val interface{}) error {
+	return v.addInScope(name, val, make(map[string]interface{}))
+}
+
+func (v *Vars) addInScope(name string, val interface{}, scope map[string]interface{}) error {
+	debugf("Adding new argument: %s - %+v\n", name, val)
+
+	if !v.isUserDefined(name) {
+		if _, ok := v.items[name]; ok {
+			return fmt.Errorf("%s is already defined. Overriding is not allowed", name)
+		}
+	}
+
+	if str, ok := val.(string); ok {
+		tmplCtx := NewTemplateContext(v)
+
+		for in, iv := range scope {
+			arg := fmt.Sprintf("{%s}", in)
+
+			if !strings.Contains(str, arg) {
+				continue
+			}
+
+			delete(scope, in)
+
+			v.addInScope(in, iv, scope)
+		}
+
+		str = v.ApplyTo(str)
+
+		v.items[name] = tmplCtx.ApplyTo(str)
+
+		if tmplCtx.HasErrors() {
+			debugf("Cannot add new argument: %s\n", tmplCtx.Error())
+			return errors.Wrapf(tmplCtx.Error(), "Cannot evaluate `"+name+"`")
+		}
+
+		debugf("Added argument: %s - %s\n", name, v.items[name])
+
+		return nil
+	}
+
+	v.items[name] = val
+	return nil
+}
+
+// AddAll adds all passed arguments in a single scope. Means items can refer to each other.
+func (v *Vars) AddAll(src map[string]interface{}) error {
+	if src == nil {
+		return nil
+	}
+
+	scope := make(map[string]interface{})
+	for ik, iv := range src {
+		scope[ik] = iv
+	}
+
+	for ik, iv := range src {
+		// Scope shall contain only non-processed items.
+		// By doing it before v.Add we avoid self-referencing.
+		delete(scope, ik)
+
+		err := v.addInScope(ik, iv, scope)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddDefaults adds variables shared by all test cases, e.g. from config file.
+// Unlike args they could be overridden and are not reported as unused.
+func (v *Vars) AddDefaults(src map[string]interface{}) error {
+	if err := v.AddAll(src); err != nil {
+		return err
+	}
+
+	for name := range src {
+		v.used[name] = true
+	}
+
+	return nil
+}
+
+// ApplyTo updates input template with values correspondent to placeholders
+// according to current vars map
+func (v *Vars) ApplyTo(str string) string {
+	for varName, val := range v.items {
+		placeholder := "{" + varName + "}"
+		assembled := strings.Replace(str, placeholder, toString(val), -1)
+
+		used := assembled != str
+
+		if v.isUserDefined(varName) && used {
+			v.used[varName] = true
+		} // check used excluding ctx and env
+
+		str = assembled
+	}
+
+	return str
+}
+
+// embedJSON replaces quoted placeholders of object and array variables in JSON body with their JSON,
+// e.g. {"order": "{order}"} -> {"order": {"id": 1}}. Other placeholders are left to ApplyTo.
+func (v *Vars) embedJSON(body string) string {
+	for varName, val := range v.items {
+		switch val.(type) {
+		case map[string]interface{}, []interface{}:
+		default:
+			continue
+		}
+
+		placeholder := `"{` + varName + `}"`
+		if !strings.Contains(body, placeholder) {
+			continue
+		}
+
+		body = strings.Replace(body, placeholder, toJSON(val), -1)
+		if v.isUserDefined(varName) {
+			v.used[varName] = true
+		}
+	}
+
+	return body
+}
+
+// Value returns typed value of the variable if template is a single placeholder, e.g. "{createdId}"
+func (v *Vars) Value(tmpl string) (interface{}, bool) {
+	if !strings.HasPrefix(tmpl, "{") || !strings.HasSuffix(tmpl, "}") {
+		return nil, false
+	}
+
+	varName := tmpl[1 : len(tmpl)-1]
+	val, ok := v.items[varName]
+	if !ok {
+		return nil, false
+	}
+
+	if v.isUserDefined(varName) {
+		v.used[varName] = true
+	}
+
+	return val, true
+}
+
+// markUsed excludes variables from unused ones, e.g. exported after the run
+func (v *Vars) markUsed(names []string) {
+	for _, name := range names {
+		if _, ok := v.items[name]; ok {
+			v.used[name] = true
+		}
+	}
+}
+
+// UserDefined returns arguments and remembered values, context and environment variables are excluded
+func (v *Vars) UserDefined() map[string]interface{} {
+	values := make(map[string]interface{})
+	for varName, val := range v.items {
+		if v.isUserDefined(varName) {
+			values[varName] = val
+		}
+	}
+
+	return values
+}
+
+// Unused returns the slice of var names not replaced so far in any templates
+func (v *Vars) Unused() []string {
+
+	unused := make([]string, 0, len(v.items)-len(v.used))
+	for varName := range v.items {
+		if v.used[varName] {
+			continue
+		}
+
+		if !v.isUserDefined(varName) {
+			continue
+		}
+
+		unused = append(unused, varName)
+	}
+
+	return unused
+}
+
+func (v *Vars) String() string {
+	str := "Vars {"
+	for varName, val := range v.items {
+		if !v.isUserDefined(varName) {
+			continue
+		}
+		str += fmt.Sprintf("%s=%s; ", varName, val)
+	}
+
+	str += "}"
+
+	return str
+}
+
+func (v *Vars) isUserDefined(varName string) bool {
+	if strings.HasPrefix(varName, ctxVarPrefix+varPrefixSeparator) {
+		return false
+	}
+
+	if strings.HasPrefix(varName, envVarPrefix+varPrefixSeparator) {
+		return false
+	}
+
+	return true
+}
+
+// toString returns value suitable to insert as an argument
+// if value if a float where decimal part is zero - convert to int, objects and arrays are converted to JSON
+func toString(rw interface{}) string {
+	switch rw.(type) {
+	case map[string]interface{}, []interface{}:
+		return toJSON(rw)
+	}
+
+	var sv = rw
+	if fv, ok := rw.(float64); ok {
+		_, frac := math.Modf(fv)
+		if frac == 0 {
+			sv = int(fv)
+		}
+	}
+
+	return fmt.Sprintf("%v", sv)
+}
+
+func toJSON(v interface{}) string {
+	bytes, _ := json.Marshal(v)
+	return string(bytes)
+}
+
+// newUUID generates random (version 4) UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // variant 10
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Throttle implements rate limiting based on sliding time window
+type Throttle struct {
+	limit     int
+	timeFrame time.Duration
+	queue     []time.Time
+}
+
+// InfiniteLimit is a constant that represents an absence of any limits.
+const InfiniteLimit = 0
+
+// NewThrottle creates Throttle with following notation: not more than X executions per time period
+// e.g. not more than 300 calls per 1 minute
+func NewThrottle(limit int, perTimeFrame time.Duration) *Throttle {
+	return &Throttle{limit: limit, timeFrame: perTimeFrame, queue: make([]time.Time, 0)}
+}
+
+func (t *Throttle) cleanOld() {
+	for _, callTime := range t.queue {
+
+		timeSince := time.Since(callTime)
+		if timeSince <= t.timeFrame {
+			break
+		} // queue is ordered, so no point to proceed
+
+		t.queue = t.queue[1:]
+	} // clean up top callTimes older than frame
+}
+
+// RunOrPause should be added to any throttled operation
+// so it either runs without interruption or waits for next time frame if current time frame call limit is exceeded
+func (t *Throttle) RunOrPause() {
+	if t.limit == InfiniteLimit {
+		return
+	} // no limit, so exit
+
+	t.cleanOld()
+
+	totalCallsInFrame := len(t.queue)
+	limitExceeded := totalCallsInFrame == t.limit
+
+	if limitExceeded {
+		eldestCallInFrame := t.queue[0]
+		durationSinceEldest := time.Since(eldestCallInFrame)
+
+		remaining := t.timeFrame - durationSinceEldest
+
+		time.Sleep(remaining)
+
+		t.queue = t.queue[1:] // free up space for new item
+	}
+
+	t.queue = append(t.queue, time.Now())
+}
@@ -1,4 +1,4 @@
-package main
+package runner
 
 import (
 	"net/http"
@@ -342,6 +342,24 @@ func TestExpectPopulateWithBodyInt(t *testing.T) {
 	}
 }
 
+func TestExpectPopulateWithOneOfKeepsDefinition(t *testing.T) {
+	definition := Expect{OneOf: []interface{}{"{savedId}", "none"}}
+	vars := NewVars("")
+	vars.Add("savedId", "myId")
+
+	// expectations of the call are populated on a copy of the definition
+	expect := definition
+	expect.populateWith(vars)
+
+	if expect.OneOf[0] != "myId" {
+		t.Errorf("oneOf does not contain var, oneOf %v", expect.OneOf)
+	}
+
+	if definition.OneOf[0] != "{savedId}" {
+		t.Errorf("definition was modified, oneOf %v", definition.OneOf)
+	}
+}
+
 func TestOnBodyContentRemovesStartEndDoubleQuotes(t *testing.T) {
 	on := &On{Body: []byte("\"abc\"")}
 
@@ -583,3 +601,28 @@ func TestToAbsURLConcat(t *testing.T) {
 		}
 	}
 }
+
+func TestExpectPopulateWithBodyKeepsVarType(t *testing.T) {
+	expect := &Expect{BPath: map[string]interface{}{
+		"items.id":   "{savedId}",
+		"items.name": "id-{savedId}",
+		"items.code": "{savedCode}",
+	}}
+
+	vars := NewVars("")
+	vars.AddAll(map[string]interface{}{"savedId": 12.0, "savedCode": "12"})
+
+	expect.populateWith(vars)
+
+	if expect.BodyPath()["items.id"] != 12.0 {
+		t.Errorf("number type is not preserved, body %v", expect.BodyPath())
+	}
+
+	if expect.BodyPath()["items.name"] != "id-12" {
+		t.Errorf("var is not interpolated into string, body %v", expect.BodyPath())
+	}
+
+	if expect.BodyPath()["items.code"] != "12" {
+		t.Errorf("string type is not preserved, body %v", expect.BodyPath())
+	}
+}
@@ -0,0 +1,130 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExportGitHub is output of VarsExportReporter writing GitHub Actions step outputs
+const ExportGitHub = "github"
+
+// VarsExportReporter writes selected variables of test cases once the run is finished,
+// e.g. ID of resource created by tests for a later CI step. Value of the last reported test case wins.
+type VarsExportReporter struct {
+	Names []string
+	// JSON file or ExportGitHub, which appends 'name=value' lines to the file of 'GITHUB_OUTPUT'
+	// or writes '::set-output' commands to Writer if it is not set
+	Output string
+	// destination of '::set-output' commands, os.Stdout if not set
+	Writer io.Writer
+
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// Init does nothing since values are collected across runs
+func (r *VarsExportReporter) Init() {
+
+}
+
+// Report retains selected variables of test cases
+func (r *VarsExportReporter) Report(results []TestResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.values == nil {
+		r.values = make(map[string]interface{})
+	}
+
+	for _, result := range results {
+		for _, name := range r.Names {
+			if value, ok := result.Vars[name]; ok {
+				r.values[name] = value
+			}
+		}
+	}
+}
+
+// Flush writes variables into JSON file or step outputs, variables not captured by any test case are omitted
+func (r *VarsExportReporter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var err error
+	if r.Output == ExportGitHub {
+		err = r.writeGitHubOutputs()
+	} else {
+		err = r.writeJSON()
+	}
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (r *VarsExportReporter) writeJSON() error {
+	values := r.values
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.Output), 0777); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(r.Output, append(data, '\n'))
+}
+
+func (r *VarsExportReporter) writeGitHubOutputs() error {
+	names := make([]string, 0, len(r.values))
+	for name := range r.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		w := r.Writer
+		if w == nil {
+			w = os.Stdout
+		}
+
+		for _, name := range names {
+			value := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(toString(r.values[name]))
+			fmt.Fprintf(w, "::set-output name=%s::%s\n", name, value)
+		}
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	for _, name := range names {
+		value := toString(r.values[name])
+		if strings.Contains(value, "\n") {
+			// multiline value is written with delimiter
+			_, err = fmt.Fprintf(f, "%s<<BOZR_EOF\n%s\nBOZR_EOF\n", name, value)
+		} else {
+			_, err = fmt.Fprintf(f, "%s=%s\n", name, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
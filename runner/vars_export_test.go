@@ -0,0 +1,97 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVarsExportReporter(t *testing.T) {
+	// given
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/orders/42")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 42}`))
+	}))
+	defer server.Close()
+
+	dir := writeTestFiles(t, map[string]string{
+		"orders.suite.json": `[{"name": "create", "calls": [{
+			"on": {"method": "POST", "url": "` + server.URL + `/orders"},
+			"expect": {"statusCode": 201},
+			"remember": {"bodyPath": {"createdId": "id"}, "headers": {"location": "Location"}}
+		}]}]`,
+	})
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "out", "vars.json")
+	exported := &VarsExportReporter{Names: []string{"createdId", "location", "missing"}, Output: output}
+
+	// when
+	results, err := Run(context.Background(), Options{Path: filepath.Join(dir, "orders.suite.json"), Reporter: exported, ExportVars: exported.Names})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results[0].HasError() {
+		t.Fatalf("Expected exported variables not to be reported as unused, got %s", results[0].Error())
+	}
+
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "{\n  \"createdId\": 42,\n  \"location\": \"/orders/42\"\n}\n"
+	if string(data) != expected {
+		t.Errorf("Expected exported variables %s, got %s", expected, data)
+	}
+}
+
+func TestVarsExportReporterGitHub(t *testing.T) {
+	defer os.Setenv("GITHUB_OUTPUT", os.Getenv("GITHUB_OUTPUT"))
+
+	results := []TestResult{
+		{Vars: map[string]interface{}{"id": 1.0, "note": "a\nb"}},
+		{Vars: map[string]interface{}{"id": 2.0, "other": "x"}},
+	}
+
+	t.Run("output file", func(t *testing.T) {
+		f, _ := ioutil.TempFile("", "github-output")
+		defer os.Remove(f.Name())
+		f.WriteString("previous=1\n")
+		f.Close()
+		os.Setenv("GITHUB_OUTPUT", f.Name())
+
+		r := &VarsExportReporter{Names: []string{"id", "note"}, Output: ExportGitHub}
+		r.Report(results)
+		r.Flush()
+
+		data, _ := ioutil.ReadFile(f.Name())
+		if expected := "previous=1\nid=2\nnote<<BOZR_EOF\na\nb\nBOZR_EOF\n"; string(data) != expected {
+			t.Errorf("Expected %q, got %q", expected, data)
+		}
+	})
+
+	t.Run("commands", func(t *testing.T) {
+		os.Setenv("GITHUB_OUTPUT", "")
+		var out bytes.Buffer
+
+		r := &VarsExportReporter{Names: []string{"id", "note"}, Output: ExportGitHub, Writer: &out}
+		r.Report(results)
+		r.Flush()
+
+		if expected := "::set-output name=id::2\n::set-output name=note::a%0Ab\n"; !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected %q, got %q", expected, out.String())
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package runner
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WebSocketOn describes exchange over WebSocket connection opened by the call.
+// Request of the call is sent as opening handshake, first received message becomes response body.
+type WebSocketOn struct {
+	// message sent after connection is opened, nothing is sent if empty
+	Send string `json:"send"`
+	// how long to wait for a message, e.g. '2s'
+	Timeout string `json:"timeout"`
+}
+
+const (
+	webSocketGUID           = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+	webSocketDefaultTimeout = 5 * time.Second
+
+	wsOpContinuation = 0x0
+	wsOpText         = 0x1
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xA
+)
+
+func (ws WebSocketOn) timeout() (time.Duration, error) {
+	if ws.Timeout == "" {
+		return webSocketDefaultTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(ws.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid WebSocket timeout '%s': %s", ws.Timeout, err)
+	}
+
+	return timeout, nil
+}
+
+// prepareWebSocket turns request into opening handshake and returns the key sent
+func prepareWebSocket(req *http.Request) (string, error) {
+	switch req.URL.Scheme {
+	case "ws":
+		req.URL.Scheme = "http"
+	case "wss":
+		req.URL.Scheme = "https"
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	return key, nil
+}
+
+func webSocketAccept(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// exchangeWebSocket sends message (if any) over upgraded connection and waits for the first message from the server
+func exchangeWebSocket(resp *http.Response, key string, message string, timeout time.Duration) ([]byte, error) {
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return nil, fmt.Errorf("WebSocket handshake failed. Expected status: %d, Actual: %d", http.StatusSwitchingProtocols, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		return nil, errors.New("WebSocket handshake failed. Invalid 'Sec-WebSocket-Accept' header")
+	}
+
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		return nil, errors.New("WebSocket handshake failed. Connection is not upgraded")
+	}
+
+	type received struct {
+		payload []byte
+		err     error
+	}
+
+	done := make(chan received, 1)
+	go func() {
+		if message != "" {
+			if err := writeWSFrame(conn, wsOpText, []byte(message), true); err != nil {
+				done <- received{err: err}
+				return
+			}
+		}
+
+		payload, err := readWSMessage(bufio.NewReader(conn), conn)
+		done <- received{payload: payload, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err == nil {
+			writeWSFrame(conn, wsOpClose, nil, true)
+		}
+		return r.payload, r.err
+	case <-time.After(timeout):
+		conn.Close()
+		return nil, fmt.Errorf("No WebSocket message received within %s", timeout)
+	}
+}
+
+// webSocketResponse presents received message as response body. Content type is derived from message if handshake has none.
+func webSocketResponse(resp *http.Response, payload []byte) {
+	if resp.Header.Get("Content-Type") != "" {
+		return
+	}
+
+	if json.Valid(payload) {
+		resp.Header.Set("Content-Type", "application/json")
+	} else {
+		resp.Header.Set("Content-Type", "text/plain")
+	}
+}
+
+// readWSMessage reads data frames until the message is complete. Pings are answered, pongs are ignored.
+func readWSMessage(r *bufio.Reader, w io.Writer) ([]byte, error) {
+	var message []byte
+	for {
+		opcode, fin, payload, err := readWSFrame(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := writeWSFrame(w, wsOpPong, payload, true); err != nil {
+				return nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			return nil, errors.New("WebSocket connection is closed by server before message is received")
+		case wsOpText, wsOpBinary, wsOpContinuation:
+			if limit := maxResponseSize(); limit > 0 && int64(len(message)+len(payload)) > limit {
+				return nil, wsMessageTooLarge()
+			}
+			message = append(message, payload...)
+		default:
+			return nil, fmt.Errorf("Unexpected WebSocket frame opcode: %d", opcode)
+		}
+
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+func wsMessageTooLarge() error {
+	return fmt.Errorf("WebSocket message exceeded max size of %d bytes (--max-response-size)", maxResponseSize())
+}
+
+func readWSFrame(r *bufio.Reader) (byte, bool, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, false, nil, err
+	}
+
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0F
+	masked := header[1]&0x80 != 0
+
+	length := uint64(header[1] & 0x7F)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return 0, false, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err := io.ReadFull(r, ext); err != nil {
+			return 0, false, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext)
+	}
+
+	// the most significant bit of 64-bit length must be 0
+	if length>>63 != 0 {
+		return 0, false, nil, errors.New("Invalid WebSocket frame length")
+	}
+	if limit := maxResponseSize(); limit > 0 && length > uint64(limit) {
+		return 0, false, nil, wsMessageTooLarge()
+	}
+
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(r, mask); err != nil {
+			return 0, false, nil, err
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, false, nil, err
+	}
+
+	for i := range payload {
+		if masked {
+			payload[i] ^= mask[i%4]
+		}
+	}
+
+	return opcode, fin, payload, nil
+}
+
+// writeWSFrame writes single final frame. Frames sent by client must be masked.
+func writeWSFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	frame := []byte{0x80 | opcode}
+
+	maskBit := byte(0)
+	if masked {
+		maskBit = 0x80
+	}
+
+	length := len(payload)
+	switch {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	data := payload
+	if masked {
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		frame = append(frame, mask...)
+
+		data = make([]byte, length)
+		for i := range payload {
+			data[i] = payload[i] ^ mask[i%4]
+		}
+	}
+
+	_, err := w.Write(append(frame, data...))
+	return err
+}
+
+// isWebSocketURL checks url has WebSocket scheme
+func isWebSocketURL(p string) bool {
+	return strings.HasPrefix(p, "ws://") || strings.HasPrefix(p, "wss://")
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// echoWebSocketServer replies with received message wrapped into JSON, e.g. 'hi' -> {"echo": "hi"}
+func echoWebSocketServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+			webSocketAccept(req.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+
+		if req.URL.Path == "/silent" {
+			readWSMessage(rw.Reader, conn)
+			return
+		}
+
+		message, err := readWSMessage(rw.Reader, conn)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		writeWSFrame(conn, wsOpPing, nil, false)
+		writeWSFrame(conn, wsOpText, []byte(`{"echo": "`+string(message)+`"}`), false)
+	}))
+}
+
+func TestWebSocketExchange(t *testing.T) {
+	// given
+	server := echoWebSocketServer(t)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	c := Call{
+		On: On{Method: "GET", URL: wsURL + "/notifications", WebSocket: &WebSocketOn{Send: "hello {name}"}},
+		Expect: Expect{
+			StatusCode:   http.StatusSwitchingProtocols,
+			BPath:        map[string]interface{}{"echo": "hello world"},
+			BodyContains: "hello",
+			BodyMatches:  `"echo":\s*"hello \w+"`,
+		},
+	}
+
+	vars := NewVars("")
+	vars.Add("name", "world")
+
+	// when
+	trace := call("", c, vars)
+
+	// then
+	if trace.HasError() {
+		t.Fatal(trace.ErrorCause)
+	}
+
+	if !strings.Contains(trace.RequestDump, "WebSocket >> hello world") {
+		t.Error("Sent message is not recorded in trace:", trace.RequestDump)
+	}
+
+	if !strings.Contains(trace.ResponseDump, `"echo": "hello world"`) {
+		t.Error("Received message is not recorded in trace:", trace.ResponseDump)
+	}
+}
+
+func TestWebSocketTimeout(t *testing.T) {
+	server := echoWebSocketServer(t)
+	defer server.Close()
+
+	c := Call{
+		On:     On{Method: "GET", URL: "ws" + strings.TrimPrefix(server.URL, "http") + "/silent", WebSocket: &WebSocketOn{Timeout: "50ms"}},
+		Expect: Expect{BodyContains: "anything"},
+	}
+
+	trace := call("", c, NewVars(""))
+
+	if trace.ErrorCause == nil || trace.ErrorCause.Error() != "No WebSocket message received within 50ms" {
+		t.Error("Expected timeout error, got:", trace.ErrorCause)
+	}
+}
+
+func TestWebSocketMessageTooLarge(t *testing.T) {
+	defer func(opts Options) { options = opts }(options)
+	options.MaxResponseSize = 1024
+
+	continued := append([]byte{wsOpText, 126, 0x02, 0x58}, make([]byte, 600)...)
+	final := append([]byte{0x80 | wsOpContinuation, 126, 0x02, 0x58}, make([]byte, 600)...)
+
+	tests := []struct {
+		name   string
+		frames []byte
+	}{
+		{"frame length", []byte{0x80 | wsOpBinary, 127, 0, 0, 1, 0, 0, 0, 0, 0}},
+		{"fragmented message", append(continued, final...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readWSMessage(bufio.NewReader(bytes.NewReader(tt.frames)), ioutil.Discard)
+
+			if err == nil || err.Error() != "WebSocket message exceeded max size of 1024 bytes (--max-response-size)" {
+				t.Error("Expected max size error, got:", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// marshalYAML serializes JSON values into block style YAML, keys are sorted
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(wireValue(v)); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalYAML parses YAML into JSON values, aliases are resolved.
+// Stream of multiple documents becomes array of documents.
+func unmarshalYAML(data []byte) (interface{}, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+	var docs []interface{}
+	for {
+		var doc interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %s", err)
+		}
+
+		value, err := jsonValue(doc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid YAML: %s", err)
+		}
+		docs = append(docs, value)
+	}
+
+	switch len(docs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return docs[0], nil
+	}
+
+	return docs, nil
+}